@@ -3,9 +3,13 @@ module github.com/terraform-providers/terraform-provider-postgresql
 go 1.16
 
 require (
+	github.com/aws/aws-sdk-go v1.36.1
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/hashicorp/terraform-plugin-sdk v1.0.0
-	github.com/lib/pq v1.9.0
+	github.com/jackc/pgconn v1.13.0
+	github.com/jackc/pgx/v4 v4.17.2
+	github.com/lib/pq v1.10.2
 	github.com/sean-/postgresql-acl v0.0.0-20161225120419-d10489e5d217
 	gocloud.dev v0.21.0
+	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa
 )
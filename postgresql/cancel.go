@@ -0,0 +1,154 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// cancelConnector wraps a connector so the statements executed on its connections
+// are also cancelled when the provider context is done (e.g.: when an apply is interrupted).
+//
+// The provider does not pass any context to database/sql, so without this wrapper
+// lib/pq would never send a cancel request and a long running statement would keep
+// running on the server after Terraform stopped waiting for it.
+type cancelConnector struct {
+	connector driver.Connector
+	ctx       context.Context
+}
+
+func (c *cancelConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &cancelConn{conn: conn, ctx: c.ctx}, nil
+}
+
+func (c *cancelConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// withCancel returns a context cancelled when either ctx or done is done.
+// The returned function must be called to release the context once the statement is over.
+func withCancel(ctx, done context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-done.Done():
+			cancel()
+		case <-merged.Done():
+		}
+	}()
+	return merged, cancel
+}
+
+// cancelConn forwards the statements to the wrapped connection with a context
+// which is cancelled with the provider context.
+type cancelConn struct {
+	conn driver.Conn
+	ctx  context.Context
+}
+
+func (c *cancelConn) Prepare(query string) (driver.Stmt, error) {
+	return c.conn.Prepare(query)
+}
+
+func (c *cancelConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *cancelConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *cancelConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	beginner, ok := c.conn.(driver.ConnBeginTx)
+	if !ok {
+		return c.conn.Begin()
+	}
+
+	// lib/pq watches the context of the transaction until it is committed or rolled back
+	txCtx, cancel := withCancel(ctx, c.ctx)
+	tx, err := beginner.BeginTx(txCtx, opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelTx{tx: tx, cancel: cancel}, nil
+}
+
+func (c *cancelConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql will prepare the statement instead
+		return nil, driver.ErrSkip
+	}
+
+	execCtx, cancel := withCancel(ctx, c.ctx)
+	defer cancel()
+	return execer.ExecContext(execCtx, query, args)
+}
+
+func (c *cancelConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		// database/sql will prepare the statement instead
+		return nil, driver.ErrSkip
+	}
+
+	// The context must stay alive until the rows are read,
+	// otherwise the query would be cancelled while scanning the results.
+	queryCtx, cancel := withCancel(ctx, c.ctx)
+	rows, err := queryer.QueryContext(queryCtx, query, args)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &cancelRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (c *cancelConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *cancelConn) ResetSession(ctx context.Context) error {
+	return resetSession(ctx, c.conn)
+}
+
+func (c *cancelConn) IsValid() bool {
+	return isValidConn(c.conn)
+}
+
+func (c *cancelConn) CheckNamedValue(value *driver.NamedValue) error {
+	return checkNamedValue(c.conn, value)
+}
+
+// cancelTx releases the context of the transaction once it is over.
+type cancelTx struct {
+	tx     driver.Tx
+	cancel context.CancelFunc
+}
+
+func (t *cancelTx) Commit() error {
+	defer t.cancel()
+	return t.tx.Commit()
+}
+
+func (t *cancelTx) Rollback() error {
+	defer t.cancel()
+	return t.tx.Rollback()
+}
+
+// cancelRows releases the context of the query once the rows are closed.
+type cancelRows struct {
+	driver.Rows
+	cancel context.CancelFunc
+}
+
+func (r *cancelRows) Close() error {
+	defer r.cancel()
+	return r.Rows.Close()
+}
@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+)
+
+// blockingConn is a driver connection whose statements run until their context is done.
+type blockingConn struct {
+	fakeConn
+	queryCtx context.Context
+}
+
+func (blockingConn) ExecContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (c *blockingConn) QueryContext(ctx context.Context, _ string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.queryCtx = ctx
+	return fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return nil }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestCancelConnExec(t *testing.T) {
+	providerCtx, cancelProvider := context.WithCancel(context.Background())
+	conn := &cancelConn{conn: &blockingConn{}, ctx: providerCtx}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_sleep(3600)", nil)
+		errCh <- err
+	}()
+
+	cancelProvider()
+
+	select {
+	case err := <-errCh:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("statement was not cancelled with the provider context")
+	}
+}
+
+func TestCancelConnQuery(t *testing.T) {
+	providerCtx, cancelProvider := context.WithCancel(context.Background())
+	defer cancelProvider()
+
+	blocking := &blockingConn{}
+	conn := &cancelConn{conn: blocking, ctx: providerCtx}
+
+	rows, err := conn.QueryContext(context.Background(), "SELECT 1", nil)
+	if err != nil {
+		t.Fatalf("could not execute query: %v", err)
+	}
+
+	// The query context must stay alive while the rows are read
+	if err := blocking.queryCtx.Err(); err != nil {
+		t.Fatalf("query context should not be done before the rows are closed, got %v", err)
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("could not close rows: %v", err)
+	}
+	if err := blocking.queryCtx.Err(); err == nil {
+		t.Error("query context should be released once the rows are closed")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"strings"
 )
 
 func getCommandOutput(ctx context.Context, command string, args ...string) (string, error) {
@@ -18,3 +19,24 @@ func getCommandOutput(ctx context.Context, command string, args ...string) (stri
 	}
 	return stdout.String(), nil
 }
+
+// getUsernameFromCommand runs the username command once per provider run
+// (the password command runs afterwards, for this user name).
+// The whitespaces around its output (e.g.: the final newline) are removed.
+func getUsernameFromCommand(ctx context.Context, command string) (string, error) {
+	output, err := getCommandOutput(ctx, "bash", "-ec", command)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute the username command %w", err)
+	}
+	username := strings.TrimSpace(output)
+	if username == "" {
+		return "", fmt.Errorf("the username command did not print any user name")
+	}
+	return username, nil
+}
+
+// trimPasswordOutput removes the final newline of the password command output,
+// the other whitespaces may be part of the password.
+func trimPasswordOutput(output string) string {
+	return strings.TrimRight(output, "\r\n")
+}
@@ -0,0 +1,168 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	connInfoDatabaseAttr       = "database"
+	connInfoCurrentUserAttr    = "current_user"
+	connInfoSessionUserAttr    = "session_user"
+	connInfoServerVersionAttr  = "server_version"
+	connInfoSSLModeAttr        = "sslmode"
+	connInfoSSLAttr            = "ssl"
+	connInfoSSLVersionAttr     = "ssl_version"
+	connInfoSSLCipherAttr      = "ssl_cipher"
+	connInfoCurrentSchemasAttr = "current_schemas"
+	connInfoSettingsAttr       = "settings"
+)
+
+// connectionInfoSettings are the session settings read by the postgresql_connection_info data source,
+// as they are the most likely to explain an unexpected behavior of the resources.
+// The settings unknown by the server (e.g.: added by a later version) are not returned.
+var connectionInfoSettings = []string{
+	"application_name",
+	"client_encoding",
+	"default_transaction_read_only",
+	"idle_in_transaction_session_timeout",
+	"lock_timeout",
+	"search_path",
+	"statement_timeout",
+	"TimeZone",
+}
+
+func dataSourcePostgreSQLConnectionInfo() *schema.Resource {
+	return &schema.Resource{
+		Read: PGResourceFunc(dataSourcePostgreSQLConnectionInfoRead),
+
+		Schema: map[string]*schema.Schema{
+			connInfoDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database to connect to. Defaults to the database configured in the provider",
+			},
+			connInfoCurrentUserAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The role the queries are executed as (CURRENT_USER)",
+			},
+			connInfoSessionUserAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The role the provider authenticated as (SESSION_USER)",
+			},
+			connInfoServerVersionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version reported by the server",
+			},
+			connInfoSSLModeAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The sslmode configured in the provider",
+			},
+			connInfoSSLAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the connection is encrypted with SSL, as reported by pg_stat_ssl",
+			},
+			connInfoSSLVersionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The SSL version of the connection (empty if not encrypted)",
+			},
+			connInfoSSLCipherAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The SSL cipher of the connection (empty if not encrypted)",
+			},
+			connInfoCurrentSchemasAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The schemas of the search_path which exist, in order, as used to resolve unqualified names",
+			},
+			connInfoSettingsAttr: {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The values of the main session settings (search_path, timeouts, ...) in effect",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLConnectionInfoRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	// All the information is read from the same connection, as the sessions of the pool may differ
+	// (e.g.: the SSL negotiation).
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var currentUser, sessionUser, serverVersion string
+	var currentSchemas pq.StringArray
+	if err := txn.QueryRow(
+		"SELECT CURRENT_USER, SESSION_USER, current_setting('server_version'), current_schemas(false)",
+	).Scan(&currentUser, &sessionUser, &serverVersion, &currentSchemas); err != nil {
+		return fmt.Errorf("could not read the connection information: %w", err)
+	}
+
+	var ssl bool
+	var sslVersion, sslCipher string
+	if db.featureSupported(featureStatSSL) {
+		if err := txn.QueryRow(
+			"SELECT ssl, COALESCE(version, ''), COALESCE(cipher, '') FROM pg_catalog.pg_stat_ssl WHERE pid = pg_backend_pid()",
+		).Scan(&ssl, &sslVersion, &sslCipher); err != nil {
+			return fmt.Errorf("could not read the SSL information of the connection: %w", err)
+		}
+	}
+
+	settings, err := readSessionSettings(txn, connectionInfoSettings)
+	if err != nil {
+		return err
+	}
+
+	_ = d.Set(connInfoDatabaseAttr, database)
+	_ = d.Set(connInfoCurrentUserAttr, currentUser)
+	_ = d.Set(connInfoSessionUserAttr, sessionUser)
+	_ = d.Set(connInfoServerVersionAttr, serverVersion)
+	_ = d.Set(connInfoSSLModeAttr, db.client.config.SSLMode)
+	_ = d.Set(connInfoSSLAttr, ssl)
+	_ = d.Set(connInfoSSLVersionAttr, sslVersion)
+	_ = d.Set(connInfoSSLCipherAttr, sslCipher)
+	_ = d.Set(connInfoCurrentSchemasAttr, []string(currentSchemas))
+	_ = d.Set(connInfoSettingsAttr, settings)
+	d.SetId(database)
+
+	return nil
+}
+
+// readSessionSettings returns the values in effect in the session of the settings known by the server,
+// as displayed by SHOW (e.g.: with their unit).
+func readSessionSettings(db QueryAble, names []string) (map[string]string, error) {
+	rows, err := db.Query(
+		"SELECT name, current_setting(name) FROM pg_catalog.pg_settings WHERE name = ANY($1)", pq.Array(names),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the session settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("could not scan the session settings: %w", err)
+		}
+		settings[name] = value
+	}
+	return settings, rows.Err()
+}
@@ -0,0 +1,45 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceConnectionInfo(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	testConfig := getTestConfig(t)
+
+	config := fmt.Sprintf(`
+data "postgresql_connection_info" "test" {
+  database = "%s"
+}
+`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_connection_info.test", "database", dbName),
+					resource.TestCheckResourceAttr("data.postgresql_connection_info.test", "session_user", testConfig.Username),
+					resource.TestCheckResourceAttr("data.postgresql_connection_info.test", "sslmode", testConfig.SSLMode),
+					resource.TestCheckResourceAttr("data.postgresql_connection_info.test", "current_schemas.0", "public"),
+					resource.TestCheckResourceAttr("data.postgresql_connection_info.test", "settings.search_path", `"$user", public`),
+					resource.TestCheckResourceAttrSet("data.postgresql_connection_info.test", "settings.statement_timeout"),
+					resource.TestCheckResourceAttrSet("data.postgresql_connection_info.test", "server_version"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,105 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	dbSettingsDatabaseAttr = "database"
+	dbSettingsRoleAttr     = "role"
+	dbSettingsSettingsAttr = "settings"
+
+	// setrole of the settings of the database itself (for all the roles), not to be confused with publicRoleOID
+	dbSettingsDatabaseRoleOID = 0
+)
+
+func dataSourcePostgreSQLDatabaseSettings() *schema.Resource {
+	return &schema.Resource{
+		Read: PGResourceFunc(dataSourcePostgreSQLDatabaseSettingsRead),
+
+		Schema: map[string]*schema.Schema{
+			dbSettingsDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database to read the settings of",
+			},
+			dbSettingsRoleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "If set, read the settings of this role in the database instead of the database settings",
+			},
+			dbSettingsSettingsAttr: {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The settings overridden with ALTER DATABASE ... SET (or ALTER ROLE ... IN DATABASE ... SET)",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLDatabaseSettingsRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+	role := d.Get(dbSettingsRoleAttr).(string)
+	if role == publicRole {
+		// setrole 0 is the database itself, not PUBLIC for which no setting can be set
+		return fmt.Errorf("settings cannot be set for %s, leave role empty to read the settings of database %s", publicRole, database)
+	}
+
+	exists, err := dbExists(db, database)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("database %s does not exist", database)
+	}
+
+	roleOID := dbSettingsDatabaseRoleOID
+	if role != "" {
+		if roleOID, err = getRoleOID(db, role); err != nil {
+			return err
+		}
+	}
+
+	var settings pq.StringArray
+	query := "SELECT COALESCE(setconfig, '{}') FROM pg_catalog.pg_db_role_setting s " +
+		"JOIN pg_catalog.pg_database d ON d.oid = s.setdatabase " +
+		"WHERE d.datname = $1 AND s.setrole = $2"
+	err = db.QueryRow(query, database, roleOID).Scan(&settings)
+	switch {
+	case err == sql.ErrNoRows:
+		// No setting overridden
+	case err != nil:
+		return fmt.Errorf("could not read settings of database %s: %w", database, err)
+	}
+
+	_ = d.Set(dbSettingsDatabaseAttr, database)
+	_ = d.Set(dbSettingsSettingsAttr, parseSettings(settings))
+
+	id := database
+	if role != "" {
+		id = strings.Join([]string{database, role}, ".")
+	}
+	d.SetId(id)
+
+	return nil
+}
+
+// parseSettings converts the name=value entries of a setconfig array to a map.
+func parseSettings(settings []string) map[string]string {
+	result := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		parts := strings.SplitN(setting, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result
+}
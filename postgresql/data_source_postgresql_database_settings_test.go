@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestAccPostgresqlDataSourceDatabaseSettings(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr("postgres")
+	dbExecute(t, dsn, fmt.Sprintf("ALTER DATABASE %s SET work_mem = '16MB'", dbName))
+	dbExecute(t, dsn, fmt.Sprintf("ALTER DATABASE %s SET statement_timeout = 30000", dbName))
+	dbExecute(t, dsn, fmt.Sprintf("ALTER ROLE %s IN DATABASE %s SET work_mem = '32MB'", roleName, dbName))
+
+	config := fmt.Sprintf(`
+data "postgresql_database_settings" "db" {
+  database = "%[1]s"
+}
+
+data "postgresql_database_settings" "role" {
+  database = "%[1]s"
+  role     = "%[2]s"
+}
+
+data "postgresql_database_settings" "none" {
+  database = "postgres"
+  role     = "%[2]s"
+}
+`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_database_settings.db", "settings.%", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_database_settings.db", "settings.work_mem", "16MB"),
+					resource.TestCheckResourceAttr("data.postgresql_database_settings.db", "settings.statement_timeout", "30000"),
+					resource.TestCheckResourceAttr("data.postgresql_database_settings.role", "settings.%", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_database_settings.role", "settings.work_mem", "32MB"),
+					resource.TestCheckResourceAttr("data.postgresql_database_settings.none", "settings.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestParseSettings(t *testing.T) {
+	var tests = []struct {
+		input []string
+		want  map[string]string
+	}{
+		{nil, map[string]string{}},
+		{[]string{"work_mem=16MB"}, map[string]string{"work_mem": "16MB"}},
+		{
+			[]string{"search_path=\"$user\", public", "application_name=a=b"},
+			map[string]string{"search_path": "\"$user\", public", "application_name": "a=b"},
+		},
+	}
+
+	for _, test := range tests {
+		if got := parseSettings(test.input); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseSettings(%v) = %v, want %v", test.input, got, test.want)
+		}
+	}
+}
+
+func TestDataSourceDatabaseSettingsPublic(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourcePostgreSQLDatabaseSettings().Schema, map[string]interface{}{
+		dbSettingsDatabaseAttr: "mydb",
+		dbSettingsRoleAttr:     publicRole,
+	})
+
+	// Rejected before reading pg_db_role_setting, where setrole 0 is the database itself
+	err := dataSourcePostgreSQLDatabaseSettingsRead(&DBConnection{client: &Client{databaseName: "postgres"}}, d)
+	if err == nil || !strings.Contains(err.Error(), "settings cannot be set for public") {
+		t.Errorf("expected public to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	objectPrivilegesRoleAttr            = "role"
+	objectPrivilegesDatabaseAttr        = "database"
+	objectPrivilegesSchemaAttr          = "schema"
+	objectPrivilegesObjectTypeAttr      = "object_type"
+	objectPrivilegesObjectAttr          = "object"
+	objectPrivilegesPrivilegesAttr      = "privileges"
+	objectPrivilegesWithGrantOptionAttr = "privileges_with_grant_option"
+)
+
+// objectPrivilegesCheckFunctions are the functions checking the privileges of a role per object type.
+var objectPrivilegesCheckFunctions = map[string]string{
+	"table":    "has_table_privilege",
+	"sequence": "has_sequence_privilege",
+	"schema":   "has_schema_privilege",
+	"database": "has_database_privilege",
+	"function": "has_function_privilege",
+}
+
+func dataSourcePostgreSQLObjectPrivileges() *schema.Resource {
+	return &schema.Resource{
+		Read: PGResourceFunc(dataSourcePostgreSQLObjectPrivilegesRead),
+
+		Schema: map[string]*schema.Schema{
+			objectPrivilegesRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role to read the effective privileges of (public for all roles)",
+			},
+			objectPrivilegesDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database of the object",
+			},
+			objectPrivilegesSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema of the object (for tables, sequences and functions)",
+			},
+			objectPrivilegesObjectTypeAttr: {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"table",
+					"sequence",
+					"schema",
+					"database",
+					"function",
+				}, false),
+				Description: "The type of the object (table, sequence, schema, database or function)",
+			},
+			objectPrivilegesObjectAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the object (for a function, its name followed by its argument types)",
+			},
+			objectPrivilegesPrivilegesAttr: {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The effective privileges of the role on the object (including the inherited ones)",
+			},
+			objectPrivilegesWithGrantOptionAttr: {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The effective privileges the role can grant to others",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLObjectPrivilegesRead(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Get(objectPrivilegesRoleAttr).(string)
+	database := getDatabase(d, db.client.databaseName)
+	objectType := d.Get(objectPrivilegesObjectTypeAttr).(string)
+	object := objectPrivilegesIdentifier(objectType, d.Get(objectPrivilegesSchemaAttr).(string), d.Get(objectPrivilegesObjectAttr).(string))
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	query := objectPrivilegesQuery(objectType)
+	rows, err := txn.Query(query, role, object, pq.Array(allPrivileges(db, objectType)))
+	if err != nil {
+		return fmt.Errorf("could not read privileges of role %s on %s %s: %w", role, objectType, object, err)
+	}
+	defer rows.Close()
+
+	privileges := schema.NewSet(schema.HashString, nil)
+	withGrantOption := schema.NewSet(schema.HashString, nil)
+	for rows.Next() {
+		var privilege string
+		var granted, grantable bool
+		if err := rows.Scan(&privilege, &granted, &grantable); err != nil {
+			return fmt.Errorf("could not scan privileges of role %s on %s %s: %w", role, objectType, object, err)
+		}
+		if granted {
+			privileges.Add(privilege)
+		}
+		if grantable {
+			withGrantOption.Add(privilege)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read privileges of role %s on %s %s: %w", role, objectType, object, err)
+	}
+
+	_ = d.Set(objectPrivilegesDatabaseAttr, database)
+	_ = d.Set(objectPrivilegesPrivilegesAttr, privileges)
+	_ = d.Set(objectPrivilegesWithGrantOptionAttr, withGrantOption)
+	d.SetId(strings.Join([]string{role, database, objectType, object}, "_"))
+
+	return nil
+}
+
+// objectPrivilegesIdentifier returns the text identifying the object for the has_*_privilege functions.
+func objectPrivilegesIdentifier(objectType, schemaName, object string) string {
+	switch objectType {
+	case "schema", "database":
+		// Names, not parsed as identifiers
+		return object
+	case "function":
+		// The function name is followed by its arguments, it is parsed by the server as is
+		return fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schemaName), object)
+	default:
+		return fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(object))
+	}
+}
+
+// objectPrivilegesQuery returns the query checking each privilege of the array $3 for the role $1
+// on the object $2, with and without the grant option.
+func objectPrivilegesQuery(objectType string) string {
+	checkFunction := objectPrivilegesCheckFunctions[objectType]
+	return fmt.Sprintf(
+		"SELECT p, pg_catalog.%[1]s($1, $2, p), pg_catalog.%[1]s($1, $2, p || ' WITH GRANT OPTION') "+
+			"FROM unnest($3::text[]) p",
+		checkFunction,
+	)
+}
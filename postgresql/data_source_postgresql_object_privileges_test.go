@@ -0,0 +1,103 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestObjectPrivilegesIdentifier(t *testing.T) {
+	cases := []struct {
+		objectType string
+		schema     string
+		object     string
+		expected   string
+	}{
+		{"table", "public", "my_table", `"public"."my_table"`},
+		{"sequence", "My Schema", "my_seq", `"My Schema"."my_seq"`},
+		{"function", "public", "my_func(integer, text)", `"public".my_func(integer, text)`},
+		{"schema", "public", "my_schema", "my_schema"},
+		{"database", "public", "my_db", "my_db"},
+	}
+
+	for _, c := range cases {
+		identifier := objectPrivilegesIdentifier(c.objectType, c.schema, c.object)
+		if identifier != c.expected {
+			t.Errorf("%s %s: expected %s, got %s", c.objectType, c.object, c.expected, identifier)
+		}
+	}
+}
+
+func TestAccPostgresqlDataSourceObjectPrivileges(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testConfig := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dsn, _ := testConfig.connStr(dbName)
+
+	// The privileges on the table are inherited from a group role
+	dbExecute(t, dsn, "CREATE ROLE test_object_privileges_group")
+	defer dbExecute(t, dsn, "DROP ROLE test_object_privileges_group")
+	dbExecute(t, dsn, fmt.Sprintf("GRANT test_object_privileges_group TO %s", roleName))
+	dbExecute(t, dsn, "CREATE TABLE test_object_privileges (id serial)")
+	dbExecute(t, dsn, "GRANT SELECT, INSERT ON test_object_privileges TO test_object_privileges_group")
+	dbExecute(t, dsn, fmt.Sprintf("GRANT UPDATE ON test_object_privileges TO %s WITH GRANT OPTION", roleName))
+	dbExecute(t, dsn, "CREATE FUNCTION test_object_privileges_func(integer) RETURNS integer AS 'SELECT $1' LANGUAGE SQL")
+	dbExecute(t, dsn, "REVOKE EXECUTE ON FUNCTION test_object_privileges_func(integer) FROM PUBLIC")
+
+	config := fmt.Sprintf(`
+data "postgresql_object_privileges" "table" {
+  role        = "%[1]s"
+  database    = "%[2]s"
+  object_type = "table"
+  object      = "test_object_privileges"
+}
+
+data "postgresql_object_privileges" "function" {
+  role        = "%[1]s"
+  database    = "%[2]s"
+  object_type = "function"
+  object      = "test_object_privileges_func(integer)"
+}
+
+data "postgresql_object_privileges" "database" {
+  role        = "%[1]s"
+  object_type = "database"
+  object      = "%[2]s"
+}
+`, roleName, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_object_privileges.table", "privileges.#", "3"),
+					testCheckObjectPrivilege("data.postgresql_object_privileges.table", "privileges", "SELECT"),
+					testCheckObjectPrivilege("data.postgresql_object_privileges.table", "privileges", "INSERT"),
+					testCheckObjectPrivilege("data.postgresql_object_privileges.table", "privileges", "UPDATE"),
+					resource.TestCheckResourceAttr("data.postgresql_object_privileges.table", "privileges_with_grant_option.#", "1"),
+					testCheckObjectPrivilege("data.postgresql_object_privileges.table", "privileges_with_grant_option", "UPDATE"),
+
+					resource.TestCheckResourceAttr("data.postgresql_object_privileges.function", "privileges.#", "0"),
+
+					testCheckObjectPrivilege("data.postgresql_object_privileges.database", "privileges", "CONNECT"),
+				),
+			},
+		},
+	})
+}
+
+// testCheckObjectPrivilege checks that the privilege is in the set attribute of the data source.
+func testCheckObjectPrivilege(name, attr, privilege string) resource.TestCheckFunc {
+	return resource.TestCheckResourceAttr(name, fmt.Sprintf("%s.%d", attr, schema.HashString(privilege)), privilege)
+}
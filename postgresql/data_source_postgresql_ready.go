@@ -0,0 +1,124 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+const (
+	readyDatabaseAttr = "database"
+	readyTimeoutAttr  = "timeout"
+	readyIntervalAttr = "interval"
+)
+
+func dataSourcePostgreSQLReady() *schema.Resource {
+	return &schema.Resource{
+		// Not wrapped with PGResourceFunc as failing to connect is expected while the server is not ready
+		Read: dataSourcePostgreSQLReadyRead,
+
+		Schema: map[string]*schema.Schema{
+			readyDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database to connect to",
+			},
+			readyTimeoutAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      300,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum time (in seconds) to wait for the server to be ready",
+			},
+			readyIntervalAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Time (in seconds) to wait between two checks",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLReadyRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	database := getDatabase(d, client.databaseName)
+
+	ctx := client.config.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	timeout := time.Duration(d.Get(readyTimeoutAttr).(int)) * time.Second
+	interval := time.Duration(d.Get(readyIntervalAttr).(int)) * time.Second
+
+	err := waitForReady(ctx, timeout, interval, func() error {
+		release := client.acquireSlot()
+		defer release()
+
+		return checkReady(ctx, client, database)
+	})
+	if err != nil {
+		return fmt.Errorf("PostgreSQL server %s is not ready: %w", client.config.Host, err)
+	}
+
+	_ = d.Set(readyDatabaseAttr, database)
+	d.SetId(database)
+
+	return nil
+}
+
+// checkReady returns an error if the server does not accept connections or is in recovery
+// (e.g.: a replica or a server still replaying its WAL after being restored).
+// Each check opens its own connection, as the ones of the pool may have been opened before
+// the server went down, and is cancelled with ctx.
+func checkReady(ctx context.Context, client *Client, database string) error {
+	db, err := client.openDedicatedDB(database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	var inRecovery bool
+	if err := db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return fmt.Errorf("could not check if the server is in recovery: %w", err)
+	}
+	if inRecovery {
+		return errors.New("server is in recovery")
+	}
+
+	return nil
+}
+
+// waitForReady calls check every interval until it succeeds, the timeout is reached
+// or ctx is done (e.g.: when the apply is interrupted). The last error of check is returned.
+func waitForReady(ctx context.Context, timeout, interval time.Duration, check func() error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+		if !time.Now().Add(interval).Before(deadline) {
+			return fmt.Errorf("timeout after %s: %w", timeout, err)
+		}
+		log.Printf("[DEBUG] PostgreSQL server not ready, retrying in %s: %v", interval, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestWaitForReady(t *testing.T) {
+	notReady := errors.New("server is in recovery")
+
+	t.Run("ready after retries", func(t *testing.T) {
+		attempts := 0
+		err := waitForReady(context.Background(), time.Second, time.Millisecond, func() error {
+			attempts++
+			if attempts < 3 {
+				return notReady
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if attempts != 3 {
+			t.Errorf("expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		err := waitForReady(context.Background(), 20*time.Millisecond, time.Millisecond, func() error {
+			return notReady
+		})
+		if !errors.Is(err, notReady) || !strings.Contains(err.Error(), "timeout") {
+			t.Errorf("expected a timeout wrapping the last error, got %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := waitForReady(ctx, 2*time.Hour, time.Hour, func() error {
+			return notReady
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestAccPostgresqlDataSourceReady(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_ready" "ready" {
+  database = "postgres"
+  timeout  = 30
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_ready.ready", "id", "postgres"),
+					resource.TestCheckResourceAttr("data.postgresql_ready.ready", "database", "postgres"),
+				),
+			},
+		},
+	})
+}
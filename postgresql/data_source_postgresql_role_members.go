@@ -0,0 +1,117 @@
+package postgresql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	roleMembersRoleAttr      = "role"
+	roleMembersRecursiveAttr = "recursive"
+	roleMembersMembersAttr   = "members"
+
+	roleMemberNameAttr        = "name"
+	roleMemberAdminOptionAttr = "admin_option"
+	roleMemberDirectAttr      = "direct"
+)
+
+func dataSourcePostgreSQLRoleMembers() *schema.Resource {
+	return &schema.Resource{
+		Read: PGResourceFunc(dataSourcePostgreSQLRoleMembersRead),
+
+		Schema: map[string]*schema.Schema{
+			roleMembersRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role to list the members of",
+			},
+			roleMembersRecursiveAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, also list the members of the member roles, recursively",
+			},
+			roleMembersMembersAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The members of the role",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						roleMemberNameAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the member role",
+						},
+						roleMemberAdminOptionAttr: {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "If the member can grant the membership of the role it belongs to others",
+						},
+						roleMemberDirectAttr: {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "If the member is granted the role directly, false if it is only a member of a member role",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// roleMembersQuery lists the members of a role (from its OID), recursively if $2 is true.
+// A role can be a member through several memberships (e.g.: granted by different grantors
+// since PostgreSQL 16, or through several member roles), so they are aggregated per member.
+const roleMembersQuery = `WITH RECURSIVE members(member, admin_option, depth) AS (
+	SELECT m.member, m.admin_option, 1 FROM pg_catalog.pg_auth_members m WHERE m.roleid = $1
+	UNION
+	SELECT m.member, m.admin_option, members.depth + 1
+	FROM pg_catalog.pg_auth_members m JOIN members ON m.roleid = members.member
+	WHERE $2
+)
+SELECT pg_catalog.pg_get_userbyid(member), bool_or(admin_option), min(depth) = 1
+FROM members GROUP BY member ORDER BY 1`
+
+func dataSourcePostgreSQLRoleMembersRead(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Get(roleMembersRoleAttr).(string)
+	recursive := d.Get(roleMembersRecursiveAttr).(bool)
+
+	roleOID, err := getRoleOID(db, role)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("role %s does not exist", role)
+	case err != nil:
+		return err
+	}
+
+	rows, err := db.Query(roleMembersQuery, roleOID, recursive)
+	if err != nil {
+		return fmt.Errorf("could not read members of role %s: %w", role, err)
+	}
+	defer rows.Close()
+
+	members := []interface{}{}
+	for rows.Next() {
+		var name string
+		var adminOption, direct bool
+		if err := rows.Scan(&name, &adminOption, &direct); err != nil {
+			return fmt.Errorf("could not scan member of role %s: %w", role, err)
+		}
+		members = append(members, map[string]interface{}{
+			roleMemberNameAttr:        name,
+			roleMemberAdminOptionAttr: adminOption,
+			roleMemberDirectAttr:      direct,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read members of role %s: %w", role, err)
+	}
+
+	_ = d.Set(roleMembersMembersAttr, members)
+	d.SetId(role)
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceRoleMembers(t *testing.T) {
+	skipIfNotAcc(t)
+
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr("postgres")
+	dbExecute(t, dsn, "CREATE ROLE test_members_admin")
+	defer dbExecute(t, dsn, "DROP ROLE test_members_admin")
+	dbExecute(t, dsn, "CREATE ROLE test_members_direct IN ROLE test_members_admin")
+	defer dbExecute(t, dsn, "DROP ROLE test_members_direct")
+	dbExecute(t, dsn, "CREATE ROLE test_members_nested IN ROLE test_members_direct")
+	defer dbExecute(t, dsn, "DROP ROLE test_members_nested")
+	dbExecute(t, dsn, "GRANT test_members_admin TO test_members_direct WITH ADMIN OPTION")
+
+	config := `
+data "postgresql_role_members" "direct" {
+  role = "test_members_admin"
+}
+
+data "postgresql_role_members" "recursive" {
+  role      = "test_members_admin"
+  recursive = true
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_role_members.direct", "members.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.direct", "members.0.name", "test_members_direct"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.direct", "members.0.admin_option", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.direct", "members.0.direct", "true"),
+
+					resource.TestCheckResourceAttr("data.postgresql_role_members.recursive", "members.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.recursive", "members.1.name", "test_members_nested"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.recursive", "members.1.admin_option", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.recursive", "members.1.direct", "false"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	rolePasswordInfoRoleAttr       = "role"
+	rolePasswordInfoMethodAttr     = "method"
+	rolePasswordInfoIterationsAttr = "iterations"
+
+	passwordMethodSCRAM = "scram-sha-256"
+	passwordMethodMD5   = "md5"
+	passwordMethodPlain = "plain"
+	passwordMethodNone  = "none"
+)
+
+func dataSourcePostgreSQLRolePasswordInfo() *schema.Resource {
+	return &schema.Resource{
+		Read: PGResourceFunc(dataSourcePostgreSQLRolePasswordInfoRead),
+
+		Schema: map[string]*schema.Schema{
+			rolePasswordInfoRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role to read the password verifier of",
+			},
+			rolePasswordInfoMethodAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The method of the password verifier (scram-sha-256, md5, plain or none)",
+			},
+			rolePasswordInfoIterationsAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The iteration count of the SCRAM verifier (0 for the other methods)",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLRolePasswordInfoRead(db *DBConnection, d *schema.ResourceData) error {
+	role := d.Get(rolePasswordInfoRoleAttr).(string)
+
+	// Only the verifier is read, the password (or its hash) is never stored in the state.
+	var password sql.NullString
+	err := db.QueryRow("SELECT rolpassword FROM pg_catalog.pg_authid WHERE rolname = $1", role).Scan(&password)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("role %s does not exist", role)
+	case isInsufficientPrivilege(err):
+		return fmt.Errorf(
+			"could not read the password of role %s, the connected user must be a superuser to read pg_authid: %w",
+			role, err,
+		)
+	case err != nil:
+		return fmt.Errorf("could not read the password of role %s: %w", role, err)
+	}
+
+	method, iterations, err := parsePasswordVerifier(password)
+	if err != nil {
+		return fmt.Errorf("could not parse the password of role %s: %w", role, err)
+	}
+
+	_ = d.Set(rolePasswordInfoMethodAttr, method)
+	_ = d.Set(rolePasswordInfoIterationsAttr, iterations)
+	d.SetId(role)
+
+	return nil
+}
+
+// parsePasswordVerifier returns the method and the iteration count (for SCRAM) of a rolpassword value.
+// SCRAM verifiers have the format SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+// and MD5 ones md5<32 hexadecimal characters>.
+func parsePasswordVerifier(password sql.NullString) (string, int, error) {
+	switch {
+	case !password.Valid || password.String == "":
+		return passwordMethodNone, 0, nil
+
+	case strings.HasPrefix(password.String, "SCRAM-SHA-256$"):
+		parameters := strings.SplitN(strings.TrimPrefix(password.String, "SCRAM-SHA-256$"), ":", 2)
+		iterations, err := strconv.Atoi(parameters[0])
+		if err != nil || len(parameters) != 2 {
+			return "", 0, fmt.Errorf("invalid SCRAM verifier")
+		}
+		return passwordMethodSCRAM, iterations, nil
+
+	case strings.HasPrefix(password.String, "md5") && len(password.String) == 35:
+		return passwordMethodMD5, 0, nil
+	}
+
+	// Unencrypted passwords could be stored before PostgreSQL 10
+	return passwordMethodPlain, 0, nil
+}
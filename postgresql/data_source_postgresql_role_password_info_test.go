@@ -0,0 +1,88 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceRolePasswordInfo(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, false, true)
+	defer teardown()
+
+	_, roleName := getTestDBNames(dbSuffix)
+
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr("postgres")
+	dbExecute(t, dsn, "CREATE ROLE test_no_password_info")
+	defer dbExecute(t, dsn, "DROP ROLE test_no_password_info")
+
+	config := fmt.Sprintf(`
+data "postgresql_role_password_info" "test" {
+  role = "%s"
+}
+
+data "postgresql_role_password_info" "no_password" {
+  role = "test_no_password_info"
+}
+`, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.postgresql_role_password_info.test", "method", regexp.MustCompile("^(scram-sha-256|md5)$"),
+					),
+					resource.TestCheckNoResourceAttr("data.postgresql_role_password_info.test", "password"),
+					resource.TestCheckResourceAttr("data.postgresql_role_password_info.no_password", "method", "none"),
+					resource.TestCheckResourceAttr("data.postgresql_role_password_info.no_password", "iterations", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestParsePasswordVerifier(t *testing.T) {
+	var tests = []struct {
+		input          sql.NullString
+		wantMethod     string
+		wantIterations int
+		wantErr        bool
+	}{
+		{sql.NullString{}, "none", 0, false},
+		{sql.NullString{String: "", Valid: true}, "none", 0, false},
+		{
+			sql.NullString{String: "SCRAM-SHA-256$4096:c2FsdA==$c3RvcmVka2V5:c2VydmVya2V5", Valid: true},
+			"scram-sha-256", 4096, false,
+		},
+		{sql.NullString{String: "md5a3556571e93b0d20722ba62be61e8c2d", Valid: true}, "md5", 0, false},
+		{sql.NullString{String: "secret", Valid: true}, "plain", 0, false},
+		{sql.NullString{String: "SCRAM-SHA-256$invalid", Valid: true}, "", 0, true},
+	}
+
+	for _, test := range tests {
+		method, iterations, err := parsePasswordVerifier(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parsePasswordVerifier(%v) error = %v, wantErr %v", test.input, err, test.wantErr)
+			continue
+		}
+		if method != test.wantMethod || iterations != test.wantIterations {
+			t.Errorf(
+				"parsePasswordVerifier(%v) = %s, %d, want %s, %d",
+				test.input, method, iterations, test.wantMethod, test.wantIterations,
+			)
+		}
+	}
+}
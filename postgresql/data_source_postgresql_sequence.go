@@ -0,0 +1,153 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	seqDataTypeAttr  = "data_type"
+	seqLastValueAttr = "last_value"
+	seqIsCalledAttr  = "is_called"
+)
+
+func dataSourcePostgreSQLSequence() *schema.Resource {
+	return &schema.Resource{
+		Read: PGResourceFunc(dataSourcePostgreSQLSequenceRead),
+
+		Schema: map[string]*schema.Schema{
+			seqNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the sequence",
+			},
+			seqSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "public",
+				Description: "The schema of the sequence",
+			},
+			seqDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database of the sequence",
+			},
+			seqDataTypeAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The data type of the sequence",
+			},
+			seqIncrementAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The value added to the current sequence value to create a new value",
+			},
+			seqMinValueAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The minimum value the sequence can generate",
+			},
+			seqMaxValueAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The maximum value the sequence can generate",
+			},
+			seqStartAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The starting value of the sequence",
+			},
+			seqCacheAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "How many sequence numbers are preallocated and stored in memory",
+			},
+			seqCycleAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the sequence wraps around when the max or min value is reached",
+			},
+			seqOwnedByAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The table column (as table.column) owning the sequence",
+			},
+			seqLastValueAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The last value returned by nextval in any session (0 if the sequence was never used)",
+			},
+			seqIsCalledAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether nextval was called since the sequence was created or reset, false if last_value is not set",
+			},
+		},
+	}
+}
+
+// sequenceDataQuery reads the parameters and the last value of a sequence without advancing it
+// (currval cannot be used as it only returns the value of a nextval of the same session).
+// pg_sequences.last_value is NULL if the sequence was never used or if the user cannot read it.
+const sequenceDataQuery = `SELECT data_type::text, increment_by, min_value, max_value, start_value, cache_size, cycle, last_value,
+	pg_catalog.has_sequence_privilege(pg_catalog.quote_ident(schemaname) || '.' || pg_catalog.quote_ident(sequencename), 'SELECT,USAGE')
+FROM pg_catalog.pg_sequences WHERE schemaname = $1 AND sequencename = $2`
+
+func dataSourcePostgreSQLSequenceRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureSequence) {
+		return unsupportedVersionError(db, "postgresql_sequence data source")
+	}
+
+	database := getDatabase(d, db.client.databaseName)
+	seqSchema := d.Get(seqSchemaAttr).(string)
+	seqName := d.Get(seqNameAttr).(string)
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var dataType string
+	var increment, minValue, maxValue, start, cache int
+	var cycle, readable bool
+	var lastValue sql.NullInt64
+	err = txn.QueryRow(sequenceDataQuery, seqSchema, seqName).Scan(
+		&dataType, &increment, &minValue, &maxValue, &start, &cache, &cycle, &lastValue, &readable,
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("sequence %s.%s does not exist in database %s", seqSchema, seqName, database)
+	case err != nil:
+		return fmt.Errorf("could not read sequence %s.%s: %w", seqSchema, seqName, err)
+	}
+	if !readable {
+		return fmt.Errorf(
+			"the connected user needs the SELECT or USAGE privilege on sequence %s.%s to read its last value",
+			seqSchema, seqName,
+		)
+	}
+
+	ownedBy, err := getSequenceOwnedBy(txn, seqSchema, seqName)
+	if err != nil {
+		return err
+	}
+
+	_ = d.Set(seqDatabaseAttr, database)
+	_ = d.Set(seqDataTypeAttr, dataType)
+	_ = d.Set(seqIncrementAttr, increment)
+	_ = d.Set(seqMinValueAttr, minValue)
+	_ = d.Set(seqMaxValueAttr, maxValue)
+	_ = d.Set(seqStartAttr, start)
+	_ = d.Set(seqCacheAttr, cache)
+	_ = d.Set(seqCycleAttr, cycle)
+	_ = d.Set(seqOwnedByAttr, ownedBy)
+	_ = d.Set(seqLastValueAttr, lastValue.Int64)
+	_ = d.Set(seqIsCalledAttr, lastValue.Valid)
+	d.SetId(generateSequenceID(database, seqSchema, seqName))
+
+	return nil
+}
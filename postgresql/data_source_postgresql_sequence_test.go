@@ -0,0 +1,61 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceSequence(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr(dbName)
+	dbExecute(t, dsn, "CREATE SEQUENCE test_schema.used_seq AS integer INCREMENT BY 5 START WITH 10")
+	dbExecute(t, dsn, "SELECT nextval('test_schema.used_seq'), nextval('test_schema.used_seq')")
+	dbExecute(t, dsn, "CREATE SEQUENCE test_schema.unused_seq")
+
+	config := fmt.Sprintf(`
+data "postgresql_sequence" "used" {
+  database = "%[1]s"
+  schema   = "test_schema"
+  name     = "used_seq"
+}
+
+data "postgresql_sequence" "unused" {
+  database = "%[1]s"
+  schema   = "test_schema"
+  name     = "unused_seq"
+}
+`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureSequence)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_sequence.used", "data_type", "integer"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.used", "increment", "5"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.used", "start", "10"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.used", "max_value", "2147483647"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.used", "last_value", "15"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.used", "is_called", "true"),
+
+					resource.TestCheckResourceAttr("data.postgresql_sequence.unused", "data_type", "bigint"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.unused", "last_value", "0"),
+					resource.TestCheckResourceAttr("data.postgresql_sequence.unused", "is_called", "false"),
+				),
+			},
+		},
+	})
+}
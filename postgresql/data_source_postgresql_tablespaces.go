@@ -0,0 +1,125 @@
+package postgresql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	tablespacesNamesAttr       = "names"
+	tablespacesTablespacesAttr = "tablespaces"
+
+	tablespaceNameAttr     = "name"
+	tablespaceOwnerAttr    = "owner"
+	tablespaceLocationAttr = "location"
+	tablespaceOptionsAttr  = "options"
+)
+
+func dataSourcePostgreSQLTablespaces() *schema.Resource {
+	return &schema.Resource{
+		Read: PGResourceFunc(dataSourcePostgreSQLTablespacesRead),
+
+		Schema: map[string]*schema.Schema{
+			tablespacesNamesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The tablespaces to read, which must all exist. All the tablespaces are read if empty",
+			},
+			tablespacesTablespacesAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The tablespaces, ordered by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						tablespaceNameAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the tablespace",
+						},
+						tablespaceOwnerAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The owner of the tablespace",
+						},
+						tablespaceLocationAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The directory of the tablespace on the server (empty for the built-in tablespaces)",
+						},
+						tablespaceOptionsAttr: {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The options of the tablespace (e.g.: random_page_cost)",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLTablespacesRead(db *DBConnection, d *schema.ResourceData) error {
+	// Not nil, as a nil array would be sent as NULL
+	names := []string{}
+	for _, name := range d.Get(tablespacesNamesAttr).(*schema.Set).List() {
+		names = append(names, name.(string))
+	}
+	sort.Strings(names)
+
+	query := "SELECT spcname, pg_catalog.pg_get_userbyid(spcowner), pg_catalog.pg_tablespace_location(oid), " +
+		"COALESCE(spcoptions, '{}') FROM pg_catalog.pg_tablespace " +
+		"WHERE $1::text[] = '{}' OR spcname = ANY($1) ORDER BY spcname"
+	rows, err := db.Query(query, pq.Array(names))
+	if err != nil {
+		return fmt.Errorf("could not read tablespaces: %w", err)
+	}
+	defer rows.Close()
+
+	tablespaces := []interface{}{}
+	found := make(map[string]bool)
+	for rows.Next() {
+		var name, owner, location string
+		var options pq.StringArray
+		if err := rows.Scan(&name, &owner, &location, &options); err != nil {
+			return fmt.Errorf("could not scan tablespace: %w", err)
+		}
+		found[name] = true
+		tablespaces = append(tablespaces, map[string]interface{}{
+			tablespaceNameAttr:     name,
+			tablespaceOwnerAttr:    owner,
+			tablespaceLocationAttr: location,
+			tablespaceOptionsAttr:  parseSettings(options),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read tablespaces: %w", err)
+	}
+
+	// Fail early, before the objects are created in a missing tablespace
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("tablespace(s) %s do not exist", strings.Join(missing, ", "))
+	}
+
+	_ = d.Set(tablespacesTablespacesAttr, tablespaces)
+
+	id := "all"
+	if len(names) > 0 {
+		id = strings.Join(names, ",")
+	}
+	d.SetId(id)
+
+	return nil
+}
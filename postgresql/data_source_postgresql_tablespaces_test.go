@@ -0,0 +1,46 @@
+package postgresql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceTablespaces(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_tablespaces" "all" {}
+
+data "postgresql_tablespaces" "default" {
+  names = ["pg_default"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_tablespaces.all", "tablespaces.0.name", "pg_default"),
+					resource.TestCheckResourceAttr("data.postgresql_tablespaces.all", "tablespaces.1.name", "pg_global"),
+					resource.TestCheckResourceAttr("data.postgresql_tablespaces.default", "tablespaces.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_tablespaces.default", "tablespaces.0.name", "pg_default"),
+					resource.TestCheckResourceAttr("data.postgresql_tablespaces.default", "tablespaces.0.location", ""),
+					resource.TestCheckResourceAttrSet("data.postgresql_tablespaces.default", "tablespaces.0.owner"),
+				),
+			},
+			{
+				Config: `
+data "postgresql_tablespaces" "missing" {
+  names = ["pg_default", "test_missing_tablespace"]
+}
+`,
+				ExpectError: regexp.MustCompile("tablespace\\(s\\) test_missing_tablespace do not exist"),
+			},
+		},
+	})
+}
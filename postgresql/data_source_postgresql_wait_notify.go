@@ -0,0 +1,152 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	waitNotifyDatabaseAttr = "database"
+	waitNotifyChannelAttr  = "channel"
+	waitNotifyTimeoutAttr  = "timeout"
+	waitNotifyPayloadAttr  = "payload"
+)
+
+// waitNotifyPollInterval is the interval between the statements executed while waiting,
+// as lib/pq only reads the notifications received by a connection when executing a statement.
+const waitNotifyPollInterval = time.Second
+
+func dataSourcePostgreSQLWaitNotify() *schema.Resource {
+	return &schema.Resource{
+		// Not wrapped with PGResourceFunc, so waiting does not hold a slot of the other resources
+		Read: dataSourcePostgreSQLWaitNotifyRead,
+
+		Schema: map[string]*schema.Schema{
+			waitNotifyDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The database to listen in, the notifications being sent to the listeners of the same database",
+			},
+			waitNotifyChannelAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.NoZeroValues,
+				Description:  "The channel to listen to (LISTEN)",
+			},
+			waitNotifyTimeoutAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      300,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Maximum time (in seconds) to wait for a notification",
+			},
+			waitNotifyPayloadAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The payload of the notification received",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLWaitNotifyRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	database := getDatabase(d, client.databaseName)
+	channel := d.Get(waitNotifyChannelAttr).(string)
+
+	if client.config.Scheme != "postgres" {
+		return fmt.Errorf("postgresql_wait_notify is only supported with the postgres scheme, not %s", client.config.Scheme)
+	}
+	// Opens the jumphost tunnel if needed and checks the connection settings before waiting
+	databaseClient := client.forDatabase(database)
+	if _, err := databaseClient.Connect(); err != nil {
+		return err
+	}
+
+	ctx := databaseClient.config.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// The connection is not taken from the pool of the provider as it must receive the notifications
+	notifications := make(chan *pq.Notification, 32)
+	// The configuration of the database client includes what was detected when connecting (e.g.: direct SSL support)
+	config := databaseClient.config
+	// The notifications are received through lib/pq, whatever the driver of the provider
+	config.Driver = driverPQ
+	config.notificationHandler = func(notification *pq.Notification) {
+		// The handler blocks the connection, the notifications of a full channel are not needed
+		select {
+		case notifications <- notification:
+		default:
+		}
+	}
+	dsn, err := config.connStr(database)
+	if err != nil {
+		return fmt.Errorf("failed to get connection string %w", err)
+	}
+	db, err := config.openDB(dsn)
+	if err != nil {
+		return fmt.Errorf("could not connect to database %s: %w", database, err)
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not connect to database %s: %w", database, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("LISTEN %s", pq.QuoteIdentifier(channel))); err != nil {
+		return fmt.Errorf("could not listen to channel %s: %w", channel, err)
+	}
+
+	timeout := time.Duration(d.Get(waitNotifyTimeoutAttr).(int)) * time.Second
+	notification, err := waitForNotification(ctx, timeout, waitNotifyPollInterval, channel, notifications, func() error {
+		_, err := conn.ExecContext(ctx, "SELECT 1")
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("no notification received on channel %s: %w", channel, err)
+	}
+
+	_ = d.Set(waitNotifyDatabaseAttr, database)
+	_ = d.Set(waitNotifyPayloadAttr, notification.Extra)
+	d.SetId(fmt.Sprintf("%s.%s", database, channel))
+
+	return nil
+}
+
+// waitForNotification returns the first notification of the channel, calling poll every interval
+// so the connection reads the notifications, until the timeout is reached
+// or ctx is done (e.g.: when the apply is interrupted).
+func waitForNotification(
+	ctx context.Context, timeout, interval time.Duration, channel string,
+	notifications <-chan *pq.Notification, poll func() error,
+) (*pq.Notification, error) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case notification := <-notifications:
+			if notification.Channel == channel {
+				return notification, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, fmt.Errorf("timeout after %s", timeout)
+		case <-time.After(interval):
+			log.Printf("[DEBUG] Waiting for a notification on channel %s", channel)
+			if err := poll(); err != nil {
+				return nil, fmt.Errorf("could not read the notifications: %w", err)
+			}
+		}
+	}
+}
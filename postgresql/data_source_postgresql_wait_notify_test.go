@@ -0,0 +1,124 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/lib/pq"
+)
+
+func TestWaitForNotification(t *testing.T) {
+	poll := func() error { return nil }
+
+	t.Run("notified", func(t *testing.T) {
+		notifications := make(chan *pq.Notification, 2)
+		notifications <- &pq.Notification{Channel: "other", Extra: "ignored"}
+		notifications <- &pq.Notification{Channel: "migrations", Extra: "done"}
+
+		notification, err := waitForNotification(context.Background(), time.Second, time.Hour, "migrations", notifications, poll)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if notification.Extra != "done" {
+			t.Errorf("expected the payload of the channel, got %q", notification.Extra)
+		}
+	})
+
+	t.Run("polled until notified", func(t *testing.T) {
+		notifications := make(chan *pq.Notification, 1)
+		polls := 0
+		_, err := waitForNotification(context.Background(), time.Second, time.Millisecond, "migrations", notifications, func() error {
+			polls++
+			if polls == 3 {
+				notifications <- &pq.Notification{Channel: "migrations"}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if polls != 3 {
+			t.Errorf("expected 3 polls, got %d", polls)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := waitForNotification(context.Background(), 20*time.Millisecond, time.Millisecond, "migrations", nil, poll)
+		if err == nil || !strings.Contains(err.Error(), "timeout") {
+			t.Errorf("expected a timeout, got %v", err)
+		}
+	})
+
+	t.Run("poll error", func(t *testing.T) {
+		connectionLost := errors.New("connection lost")
+		_, err := waitForNotification(context.Background(), time.Second, time.Millisecond, "migrations", nil, func() error {
+			return connectionLost
+		})
+		if !errors.Is(err, connectionLost) {
+			t.Errorf("expected the poll error, got %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := waitForNotification(ctx, 2*time.Hour, time.Hour, "migrations", nil, poll)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestAccPostgresqlDataSourceWaitNotify(t *testing.T) {
+	skipIfNotAcc(t)
+
+	// Notifies the channel until the end of the test, as the notifications sent
+	// before the data source listens to the channel are not received
+	stop := make(chan struct{})
+	defer close(stop)
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+	go func() {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return
+		}
+		defer db.Close()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(500 * time.Millisecond):
+				_, _ = db.Exec("NOTIFY test_migrations, 'done'")
+			}
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "postgresql_wait_notify" "migrations" {
+  database = "postgres"
+  channel  = "test_migrations"
+  timeout  = 30
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_wait_notify.migrations", "id", "postgres.test_migrations"),
+					resource.TestCheckResourceAttr("data.postgresql_wait_notify.migrations", "payload", "done"),
+				),
+			},
+		},
+	})
+}
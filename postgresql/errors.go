@@ -0,0 +1,94 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/lib/pq"
+)
+
+// Kinds of the errors returned for the common failures. The errors returned by the provider
+// can be matched against them with errors.Is, whatever their message (e.g.: to decide in the
+// tools wrapping the provider whether an operation can be skipped or must fail).
+var (
+	// The connected user is not allowed to execute a statement
+	ErrInsufficientPrivilege = errors.New("insufficient privilege")
+	// An object (database, schema, table, role, etc.) used by a statement does not exist
+	ErrObjectNotFound = errors.New("object not found")
+	// A resource or an attribute is not supported by the version of the server
+	ErrVersionUnsupported = errors.New("not supported by the PostgreSQL version")
+)
+
+// sqlStateErrorKinds maps the SQLSTATE of the PostgreSQL errors to their kind.
+var sqlStateErrorKinds = map[pq.ErrorCode]error{
+	pgErrInsufficientPrivilege: ErrInsufficientPrivilege,
+	pgErrUndefinedTable:        ErrObjectNotFound,
+	pgErrUndefinedObject:       ErrObjectNotFound,
+	pgErrUndefinedColumn:       ErrObjectNotFound,
+	pgErrUndefinedFunction:     ErrObjectNotFound,
+	pgErrInvalidCatalogName:    ErrObjectNotFound,
+	pgErrInvalidSchemaName:     ErrObjectNotFound,
+}
+
+// kindError associates one of the error kinds to an error, without changing its message.
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+func withErrorKind(err, kind error) error {
+	return &kindError{err: err, kind: kind}
+}
+
+// classifyError associates its kind to err from the SQLSTATE of the PostgreSQL error it wraps.
+// err is returned as is if it already has a kind or if its SQLSTATE is not mapped to one.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var kErr *kindError
+	if errors.As(err, &kErr) {
+		return err
+	}
+
+	if kind, ok := sqlStateErrorKinds[sqlState(err)]; ok {
+		return withErrorKind(err, kind)
+	}
+	return err
+}
+
+// sqlState returns the SQLSTATE of the PostgreSQL error wrapped by err,
+// as returned by lib/pq or by pgx (see the driver setting), or an empty code if there is none.
+func sqlState(err error) pq.ErrorCode {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pq.ErrorCode(pgErr.Code)
+	}
+	return ""
+}
+
+// unsupportedVersionError returns the error for a feature not supported by the version of the server.
+func unsupportedVersionError(db *DBConnection, feature string) error {
+	return withErrorKind(
+		fmt.Errorf("%s is not supported for this Postgres version (%s)", feature, db.version),
+		ErrVersionUnsupported,
+	)
+}
@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/jackc/pgconn"
+	"github.com/lib/pq"
+)
+
+func TestClassifyError(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"insufficient privilege", &pq.Error{Code: "42501"}, ErrInsufficientPrivilege},
+		{"undefined table", &pq.Error{Code: "42P01"}, ErrObjectNotFound},
+		{"undefined role", &pq.Error{Code: "42704"}, ErrObjectNotFound},
+		{"undefined database", &pq.Error{Code: "3D000"}, ErrObjectNotFound},
+		{"undefined schema", &pq.Error{Code: "3F000"}, ErrObjectNotFound},
+		{"wrapped", fmt.Errorf("could not read role: %w", &pq.Error{Code: "42501"}), ErrInsufficientPrivilege},
+		{"unmapped SQLSTATE", &pq.Error{Code: "40001"}, nil},
+		{"pgx insufficient privilege", &pgconn.PgError{Code: "42501"}, ErrInsufficientPrivilege},
+		{"pgx wrapped", fmt.Errorf("could not read table: %w", &pgconn.PgError{Code: "42P01"}), ErrObjectNotFound},
+		{"not a PostgreSQL error", errors.New("boom"), nil},
+		{
+			"unsupported version",
+			unsupportedVersionError(&DBConnection{version: semver.MustParse("9.0.0")}, "postgresql_sequence resource"),
+			ErrVersionUnsupported,
+		},
+	}
+
+	kinds := []error{ErrInsufficientPrivilege, ErrObjectNotFound, ErrVersionUnsupported}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := classifyError(test.err)
+			if err.Error() != test.err.Error() {
+				t.Errorf("classifyError() changed the message to %q, want %q", err.Error(), test.err.Error())
+			}
+			for _, kind := range kinds {
+				if got, want := errors.Is(err, kind), kind == test.kind; got != want {
+					t.Errorf("errors.Is(%v, %v) = %t, want %t", err, kind, got, want)
+				}
+			}
+
+			var pqErr *pq.Error
+			if errors.As(test.err, &pqErr) && !errors.As(err, &pqErr) {
+				t.Errorf("classifyError() hides the PostgreSQL error of %v", test.err)
+			}
+		})
+	}
+
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) should be nil")
+	}
+}
@@ -1,31 +1,69 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/lib/pq"
 )
 
 const (
 	createIfNotExistsAttr = "create_if_not_exists"
+	lockTimeoutAttr       = "lock_timeout"
 	// https://github.com/lib/pq/blob/9e747ca50601fcb6c958dd89f4cb8aea3e067767/error.go#L199
 	pgInvalidAuth = pq.ErrorClass("28")
+	// https://www.postgresql.org/docs/current/errcodes-appendix.html
+	pgErrInsufficientPrivilege = pq.ErrorCode("42501")
+	pgErrDependentObjects      = pq.ErrorCode("2BP01")
+	pgErrUndefinedTable        = pq.ErrorCode("42P01")
+	pgErrSerializationFailure  = pq.ErrorCode("40001")
+	pgErrDeadlockDetected      = pq.ErrorCode("40P01")
+	pgErrObjectInUse           = pq.ErrorCode("55006")
+	pgErrUndefinedObject       = pq.ErrorCode("42704")
+	pgErrUndefinedColumn       = pq.ErrorCode("42703")
+	pgErrUndefinedFunction     = pq.ErrorCode("42883")
+	pgErrInvalidCatalogName    = pq.ErrorCode("3D000")
+	pgErrInvalidSchemaName     = pq.ErrorCode("3F000")
 )
 
+// retryDelay is the base delay between two attempts of a transaction (see withRetries).
+var retryDelay = 200 * time.Millisecond
+
 func PGResourceFunc(fn func(*DBConnection, *schema.ResourceData) error) func(*schema.ResourceData, interface{}) error {
 	return func(d *schema.ResourceData, meta interface{}) error {
 		client := meta.(*Client)
 
+		release := client.acquireSlot()
+		defer release()
+
 		db, err := client.Connect()
 		if err != nil {
-			return err
+			return classifyError(err)
 		}
 
-		return fn(db, d)
+		return classifyError(fn(db, d))
+	}
+}
+
+// onMaintenanceDatabase wraps a resource function to execute it connected to the maintenance database
+// (see maintenance_database), e.g. as a database cannot be dropped or renamed while connected to it.
+func onMaintenanceDatabase(fn func(*DBConnection, *schema.ResourceData) error) func(*DBConnection, *schema.ResourceData) error {
+	return func(db *DBConnection, d *schema.ResourceData) error {
+		maintenanceDB, err := db.client.maintenanceClient().Connect()
+		if err != nil {
+			return err
+		}
+		return fn(maintenanceDB, d)
 	}
 }
 
@@ -33,13 +71,108 @@ func PGResourceExistsFunc(fn func(*DBConnection, *schema.ResourceData) (bool, er
 	return func(d *schema.ResourceData, meta interface{}) (bool, error) {
 		client := meta.(*Client)
 
+		release := client.acquireSlot()
+		defer release()
+
 		db, err := client.Connect()
 		if err != nil {
-			return false, err
+			return false, classifyError(err)
+		}
+
+		exists, err := fn(db, d)
+		return exists, classifyError(err)
+	}
+}
+
+// removeIfNotFound wraps the Read or Delete function of a resource to remove it from the state
+// when an object it depends on does not exist anymore (e.g.: its database or its schema
+// was dropped outside of Terraform), instead of failing with ErrObjectNotFound.
+func removeIfNotFound(fn func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	return func(d *schema.ResourceData, meta interface{}) error {
+		err := fn(d, meta)
+		if errors.Is(err, ErrObjectNotFound) {
+			log.Printf("[WARN] %s: %v, removing it from the state", d.Id(), err)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+}
+
+// withRetries executes fn again, up to maxRetries times, while it fails with a serialization failure
+// or a deadlock (e.g.: when several applies update the catalog concurrently).
+// fn must be safe to replay, see withTransaction. The wait between two attempts stops when ctx is done.
+func withRetries(ctx context.Context, maxRetries int, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isRetryableError(err) || attempt > maxRetries {
+			return err
+		}
+
+		// Wait longer at each attempt, with some jitter so concurrent operations do not collide again.
+		delay := time.Duration(attempt)*retryDelay + time.Duration(rand.Int63n(int64(retryDelay)+1))
+		log.Printf("[WARN] retrying in %s after a concurrent update error (attempt %d/%d): %v", delay, attempt, maxRetries, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// withTransaction executes fn in a transaction on the database and commits it.
+// If the transaction fails with a serialization failure or a deadlock, PostgreSQL has rolled it back,
+// so it is executed again (see withRetries): fn must only change the database through txn.
+func withTransaction(client *Client, database string, fn func(txn *sql.Tx) error) error {
+	ctx := client.config.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return withRetries(ctx, client.config.MaxRetries, func() error {
+		txn, err := startTransaction(client, database)
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
+
+		if err := fn(txn); err != nil {
+			return err
 		}
+		if err := txn.Commit(); err != nil {
+			return fmt.Errorf("could not commit transaction: %w", err)
+		}
+		return nil
+	})
+}
 
-		return fn(db, d)
+// nonTransactionalStatements are the statements PostgreSQL refuses to execute in a transaction block.
+// The resources execute their statements in a transaction so a failure does not leave
+// a partial change behind, except for these ones which are executed on their own.
+var nonTransactionalStatements = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)^\s*CREATE\s+DATABASE\b`),
+	regexp.MustCompile(`(?is)^\s*DROP\s+DATABASE\b`),
+	regexp.MustCompile(`(?is)^\s*ALTER\s+DATABASE\s+.*\bSET\s+TABLESPACE\b`),
+	regexp.MustCompile(`(?is)^\s*(CREATE|DROP)\s+TABLESPACE\b`),
+	regexp.MustCompile(`(?is)^\s*CREATE\s+(UNIQUE\s+)?INDEX\s+CONCURRENTLY\b`),
+	regexp.MustCompile(`(?is)^\s*DROP\s+INDEX\s+CONCURRENTLY\b`),
+	regexp.MustCompile(`(?is)^\s*REINDEX\b.*\bCONCURRENTLY\b`),
+	regexp.MustCompile(`(?is)^\s*ALTER\s+TYPE\s+.*\bADD\s+VALUE\b`),
+	regexp.MustCompile(`(?is)^\s*ALTER\s+SYSTEM\b`),
+	regexp.MustCompile(`(?is)^\s*VACUUM\b`),
+}
+
+// isNonTransactionalStatement returns true if one of the statements, separated with semicolons,
+// cannot be executed in a transaction block.
+func isNonTransactionalStatement(statements string) bool {
+	for _, statement := range strings.Split(statements, ";") {
+		for _, re := range nonTransactionalStatements {
+			if re.MatchString(statement) {
+				return true
+			}
+		}
 	}
+	return false
 }
 
 // QueryAble is a DB connection (sql.DB/Tx)
@@ -59,12 +192,40 @@ func pqQuoteLiteral(in string) string {
 	return in
 }
 
+// membershipOptionsCondition filters out, since PostgreSQL 16, the memberships conferring
+// neither SET nor INHERIT, e.g.: the ADMIN OPTION a CREATEROLE user receives on the roles it creates
+// (without their membership unless createrole_self_grant is set).
+const membershipOptionsCondition = "(set_option OR inherit_option)"
+
+// hasMembershipOptions returns true if the role memberships have the SET and INHERIT options (PostgreSQL >= 16).
+// It is checked from the catalog as the server version is not known in a transaction.
+func hasMembershipOptions(db QueryAble) (bool, error) {
+	var exists bool
+	if err := db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_attribute " +
+			"WHERE attrelid = 'pg_catalog.pg_auth_members'::regclass AND attname = 'set_option')",
+	).Scan(&exists); err != nil {
+		return false, fmt.Errorf("could not check role membership options: %w", err)
+	}
+	return exists, nil
+}
+
+// isMemberOfRole returns true if member is granted role directly.
+// Since PostgreSQL 16, only the memberships allowing to SET ROLE are considered, so a CREATEROLE user
+// having only the ADMIN OPTION on a role it created is temporarily granted its membership when needed.
 func isMemberOfRole(db QueryAble, role, member string) (bool, error) {
+	query := "SELECT 1 FROM pg_auth_members WHERE pg_get_userbyid(roleid) = $1 AND pg_get_userbyid(member) = $2"
+
+	membershipOptions, err := hasMembershipOptions(db)
+	if err != nil {
+		return false, err
+	}
+	if membershipOptions {
+		query += " AND set_option"
+	}
+
 	var _rez int
-	err := db.QueryRow(
-		"SELECT 1 FROM pg_auth_members WHERE pg_get_userbyid(roleid) = $1 AND pg_get_userbyid(member) = $2",
-		role, member,
-	).Scan(&_rez)
+	err = db.QueryRow(query, role, member).Scan(&_rez)
 
 	switch {
 	case err == sql.ErrNoRows:
@@ -98,11 +259,53 @@ func grantRoleMembership(db QueryAble, role, member string) (bool, error) {
 
 	sql := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
 	if _, err := db.Exec(sql); err != nil {
+		if isInsufficientPrivilege(err) {
+			return false, fmt.Errorf(
+				"Error granting role %s to %s, the connected user needs the ADMIN OPTION on role %s "+
+					"(or the CREATEROLE privilege before PostgreSQL 16) to get its membership: %w",
+				role, member, role, err,
+			)
+		}
 		return false, fmt.Errorf("Error granting role %s to %s: %w", role, member, err)
 	}
 	return true, nil
 }
 
+// adminOptionRequiredError explains why the connected user could not grant or revoke role to/from member.
+// Since PostgreSQL 16, the CREATEROLE privilege is not enough: the ADMIN OPTION on the role is needed,
+// which a CREATEROLE user only receives automatically on the roles it creates.
+func adminOptionRequiredError(db *DBConnection, action, role, member string, err error) error {
+	if db.featureSupported(featureRoleMembershipOptions) {
+		return fmt.Errorf(
+			"could not %s role %s to/from %s: since PostgreSQL 16 the connected user needs the ADMIN OPTION on role %s, "+
+				"CREATEROLE is not enough. It is granted automatically on the roles it creates, "+
+				"otherwise run GRANT %s TO <provider user> WITH ADMIN OPTION as a user having it: %w",
+			action, role, member, role, pq.QuoteIdentifier(role), err,
+		)
+	}
+	return fmt.Errorf(
+		"could not %s role %s to/from %s, the connected user needs the CREATEROLE privilege or the ADMIN OPTION on it: %w",
+		action, role, member, err,
+	)
+}
+
+// checkRoleMembership returns a precise error if the connected user is not a member of the role
+// (superusers are members of all roles), for the operations requiring it (e.g.: to change an owner).
+func checkRoleMembership(txn QueryAble, role, operation string) error {
+	var member bool
+	if err := txn.QueryRow("SELECT pg_has_role(CURRENT_USER, $1, 'MEMBER')", role).Scan(&member); err != nil {
+		return fmt.Errorf("could not check membership of role %s: %w", role, err)
+	}
+	if !member {
+		currentUser, err := getCurrentUser(txn)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("the connected user %s needs to be a member of role %s to %s", currentUser, role, operation)
+	}
+	return nil
+}
+
 // revokeRoleMembership revokes the role *role* from the user *member*.
 // It returns false if the revoke is not needed because the user is not a member of this role.
 func revokeRoleMembership(db QueryAble, role, member string) (bool, error) {
@@ -238,22 +441,60 @@ func sliceContainsStr(haystack []string, needle string) bool {
 	return false
 }
 
+// sortedStrings returns the sorted strings of the set.
+func sortedStrings(set *schema.Set) []string {
+	result := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		result = append(result, v.(string))
+	}
+	sort.Strings(result)
+	return result
+}
+
 // allowedPrivileges is the list of privileges allowed per object types in Postgres.
 // see: https://www.postgresql.org/docs/current/sql-grant.html
 var allowedPrivileges = map[string][]string{
 	"database": []string{"ALL", "CREATE", "CONNECT", "TEMPORARY", "TEMP"},
-	"table":    []string{"ALL", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+	"table":    []string{"ALL", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER", "MAINTAIN"},
+	"column":   []string{"ALL", "SELECT", "INSERT", "UPDATE", "REFERENCES"},
 	"sequence": []string{"ALL", "USAGE", "SELECT", "UPDATE"},
 	"schema":   []string{"ALL", "CREATE", "USAGE"},
 	"function": []string{"ALL", "EXECUTE"},
 	"type":     []string{"ALL", "USAGE"},
+
+	"foreign_server":       []string{"ALL", "USAGE"},
+	"foreign_data_wrapper": []string{"ALL", "USAGE"},
+}
+
+// versionedPrivileges are the privileges which are only supported from a given Postgres version.
+var versionedPrivileges = map[string]featureName{
+	"MAINTAIN": featureMaintainPrivilege,
+}
+
+// unknownPrivilegeRegexp matches the privileges which are not known by the provider but are passed
+// as is to the server (e.g.: a privilege added by a PostgreSQL version newer than the provider),
+// which is in charge of validating them. They are only made of keywords so they can be used unquoted.
+var unknownPrivilegeRegexp = regexp.MustCompile(`^[A-Z]+(_[A-Z]+)*$`)
+
+// isKnownPrivilege returns true if the privilege is allowed for any of the object types.
+func isKnownPrivilege(privilege string) bool {
+	for _, allowed := range allowedPrivileges {
+		if sliceContainsStr(allowed, privilege) {
+			return true
+		}
+	}
+	return false
 }
 
 // validatePrivileges checks that privileges to apply are allowed for this object type.
-func validatePrivileges(d *schema.ResourceData) error {
-	objectType := d.Get("object_type").(string)
-	privileges := d.Get("privileges").(*schema.Set).List()
+func validatePrivileges(db *DBConnection, d *schema.ResourceData) error {
+	return validateObjectPrivileges(db, d.Get("object_type").(string), d.Get("privileges").(*schema.Set).List())
+}
 
+// validateObjectPrivileges checks the privileges can be granted on this object type.
+// The versioned privileges are only checked against the server version if db is not nil
+// (e.g.: during the plan, the server may not be reachable yet).
+func validateObjectPrivileges(db *DBConnection, objectType string, privileges []interface{}) error {
 	allowed, ok := allowedPrivileges[objectType]
 	if !ok {
 		return fmt.Errorf("unknown object type %s", objectType)
@@ -261,12 +502,43 @@ func validatePrivileges(d *schema.ResourceData) error {
 
 	for _, priv := range privileges {
 		if !sliceContainsStr(allowed, priv.(string)) {
-			return fmt.Errorf("%s is not an allowed privilege for object type %s", priv, objectType)
+			// The known privileges are rejected as they are not valid for this object type
+			if isKnownPrivilege(priv.(string)) || !unknownPrivilegeRegexp.MatchString(priv.(string)) {
+				return fmt.Errorf(
+					"%s is not an allowed privilege for object type %s (allowed: %s)",
+					priv, objectType, strings.Join(allowed, ", "),
+				)
+			}
+			log.Printf(
+				"[WARN] %s is not a privilege known by the provider for object type %s, it is passed as is to the server",
+				priv, objectType,
+			)
+			continue
+		}
+		if feature, ok := versionedPrivileges[priv.(string)]; ok && db != nil && !db.featureSupported(feature) {
+			return unsupportedVersionError(db, fmt.Sprintf("%s privilege", priv))
 		}
 	}
 	return nil
 }
 
+// allPrivileges returns the privileges implied by ALL for this object type on the server,
+// as they depend on its version (e.g.: MAINTAIN on tables since Postgres 17).
+func allPrivileges(db *DBConnection, objectType string) []string {
+	var privileges []string
+	for _, priv := range allowedPrivileges[objectType] {
+		// TEMP is only an alias of TEMPORARY
+		if priv == "ALL" || priv == "TEMP" {
+			continue
+		}
+		if feature, ok := versionedPrivileges[priv]; ok && !db.featureSupported(feature) {
+			continue
+		}
+		privileges = append(privileges, priv)
+	}
+	return privileges
+}
+
 func pgArrayToSet(arr pq.ByteaArray) *schema.Set {
 	s := make([]interface{}, len(arr))
 	for i, v := range arr {
@@ -286,14 +558,45 @@ func setToPgIdentList(schema string, idents *schema.Set) string {
 	return strings.Join(quotedIdents, ",")
 }
 
+// setToQuotedList returns the identifiers of the set as a comma separated list of quoted identifiers.
+func setToQuotedList(idents *schema.Set) string {
+	quotedIdents := make([]string, idents.Len())
+	for i, ident := range idents.List() {
+		quotedIdents[i] = pq.QuoteIdentifier(ident.(string))
+	}
+	return strings.Join(quotedIdents, ",")
+}
+
+// lockTimeoutSchema is the schema of the lock_timeout attribute of the resources executing DDL statements.
+func lockTimeoutSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      0,
+		Description:  "Maximum time (in milliseconds) to wait for the locks needed by the statements, 0 to wait indefinitely",
+		ValidateFunc: validation.IntAtLeast(0),
+	}
+}
+
+// setLockTimeout sets the lock_timeout of the resource for the transaction only,
+// so blocked statements fail fast instead of waiting for the locks (and blocking other queries meanwhile).
+func setLockTimeout(txn *sql.Tx, d *schema.ResourceData) error {
+	lockTimeout := d.Get(lockTimeoutAttr).(int)
+	if lockTimeout == 0 {
+		return nil
+	}
+
+	if _, err := txn.Exec(fmt.Sprintf("SET LOCAL lock_timeout = %d", lockTimeout)); err != nil {
+		return fmt.Errorf("could not set lock_timeout: %w", err)
+	}
+	return nil
+}
+
 // startTransaction starts a new DB transaction on the specified database.
 // If the database is specified and different from the one configured in the provider,
 // it will create a new connection pool if needed.
 func startTransaction(client *Client, database string) (*sql.Tx, error) {
-	if database != "" && database != client.databaseName {
-		client = client.config.NewClient(database)
-	}
-	db, err := client.Connect()
+	db, err := client.forDatabase(database).Connect()
 	if err != nil {
 		return nil, err
 	}
@@ -367,8 +670,10 @@ func deferredRollback(txn *sql.Tx) {
 	}
 }
 
+// getDatabase returns the database configured in the resource
+// or the default one (usually the one configured in the provider) if not set.
 func getDatabase(d *schema.ResourceData, databaseName string) string {
-	if v, ok := d.GetOk(extDatabaseAttr); ok {
+	if v, ok := d.GetOk("database"); ok {
 		databaseName = v.(string)
 	}
 
@@ -413,6 +718,59 @@ SELECT rolname
 	return owner, nil
 }
 
+// isInsufficientPrivilege returns true if err is a PostgreSQL insufficient privilege error.
+func isInsufficientPrivilege(err error) bool {
+	return sqlState(err) == pgErrInsufficientPrivilege
+}
+
+// isDependentObjects returns true if the error is due to objects depending on the dropped or revoked one.
+func isDependentObjects(err error) bool {
+	return sqlState(err) == pgErrDependentObjects
+}
+
+// isRetryableError returns true if the error is due to a serialization failure or a deadlock,
+// after which the transaction can be retried.
+func isRetryableError(err error) bool {
+	code := sqlState(err)
+	return code == pgErrSerializationFailure || code == pgErrDeadlockDetected
+}
+
+// isObjectInUse returns true if the error is due to an object being used by other sessions
+// (e.g.: a database which has to be moved without any connection to it).
+func isObjectInUse(err error) bool {
+	return sqlState(err) == pgErrObjectInUse
+}
+
+// isUndefinedTable returns true if the error is due to a missing table (e.g.: a catalog
+// which does not exist in a PostgreSQL-compatible engine).
+func isUndefinedTable(err error) bool {
+	return sqlState(err) == pgErrUndefinedTable
+}
+
+// isUndefinedObject returns true if the error is due to a missing object
+// (e.g.: an extension required by the created one which is not installed).
+func isUndefinedObject(err error) bool {
+	return sqlState(err) == pgErrUndefinedObject
+}
+
+// setObjectOwner changes the owner of an object with ALTER <objectType> ... OWNER TO.
+// objectName must already be quoted (e.g. with pq.QuoteIdentifier).
+// Unless the connected user is a superuser, PostgreSQL requires it to be a member of
+// the new owner role, so we return an explicit error if it's not the case.
+func setObjectOwner(db QueryAble, objectType, objectName, owner string) error {
+	sql := fmt.Sprintf("ALTER %s %s OWNER TO %s", objectType, objectName, pq.QuoteIdentifier(owner))
+	if _, err := db.Exec(sql); err != nil {
+		if isInsufficientPrivilege(err) {
+			return fmt.Errorf(
+				"could not change owner of %s %s to %s, the connected user needs to be a member of role %s: %w",
+				strings.ToLower(objectType), objectName, owner, owner, err,
+			)
+		}
+		return fmt.Errorf("Error updating %s OWNER: %w", strings.ToLower(objectType), err)
+	}
+	return nil
+}
+
 // getTablesOwner retrieves all the owners for all the tables in the specified schema.
 func getTablesOwner(db QueryAble, schemaName string) ([]string, error) {
 	rows, err := db.Query(
@@ -438,6 +796,30 @@ func getTablesOwner(db QueryAble, schemaName string) ([]string, error) {
 	return owners, nil
 }
 
+// getTypesOwner returns the owners of the types (including domains) of the schema.
+func getTypesOwner(db QueryAble, schemaName string) ([]string, error) {
+	rows, err := db.Query(
+		"SELECT DISTINCT pg_get_userbyid(t.typowner) FROM pg_catalog.pg_type t "+
+			"JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace WHERE n.nspname = $1",
+		schemaName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error while looking for owners of types in schema '%s': %w", schemaName, err)
+	}
+	defer rows.Close()
+
+	var owners []string
+	for rows.Next() {
+		var owner string
+		if err := rows.Scan(&owner); err != nil {
+			return nil, fmt.Errorf("could not scan types owner: %w", err)
+		}
+		owners = append(owners, owner)
+	}
+
+	return owners, rows.Err()
+}
+
 func isSuperuser(db QueryAble, role string) (bool, error) {
 	var superuser bool
 
@@ -450,9 +832,14 @@ func isSuperuser(db QueryAble, role string) (bool, error) {
 
 const publicRole = "public"
 
+// publicRoleOID is the grantee OID of the privileges granted to PUBLIC (e.g.: in aclexplode).
+// It is not the OID of a role, so it only identifies PUBLIC in the ACLs.
+const publicRoleOID = 0
+
+// getRoleOID returns the OID of the role, or publicRoleOID for PUBLIC.
 func getRoleOID(db QueryAble, role string) (int, error) {
 	if role == publicRole {
-		return 0, nil
+		return publicRoleOID, nil
 	}
 
 	var oid int
@@ -477,3 +864,18 @@ func pgLockRole(txn *sql.Tx, role string) error {
 
 	return nil
 }
+
+// planCollationVersionRefresh plans the change of the recorded collation version to the actual one
+// if the refresh is enabled and they differ, so the update refreshes it.
+// Both versions are read from the state, so no connection is needed.
+func planCollationVersionRefresh(d *schema.ResourceDiff, refreshAttr, versionAttr, actualVersionAttr string) error {
+	if d.Id() == "" || !d.Get(refreshAttr).(bool) {
+		return nil
+	}
+
+	actualVersion := d.Get(actualVersionAttr).(string)
+	if actualVersion == "" || d.Get(versionAttr).(string) == actualVersion {
+		return nil
+	}
+	return d.SetNew(versionAttr, actualVersion)
+}
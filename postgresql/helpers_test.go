@@ -0,0 +1,131 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/lib/pq"
+)
+
+func TestWithRetries(t *testing.T) {
+	defer func(delay time.Duration) { retryDelay = delay }(retryDelay)
+	retryDelay = 0
+
+	serializationErr := fmt.Errorf("could not grant: %w", &pq.Error{Code: pgErrSerializationFailure})
+	deadlockErr := &pq.Error{Code: pgErrDeadlockDetected}
+	otherErr := errors.New("other error")
+
+	cases := []struct {
+		description   string
+		maxRetries    int
+		errors        []error
+		expectedCalls int
+		expectedErr   error
+	}{
+		{"success", 3, nil, 1, nil},
+		{"serialization failure then success", 3, []error{serializationErr}, 2, nil},
+		{"deadlocks then success", 3, []error{deadlockErr, deadlockErr, deadlockErr}, 4, nil},
+		{"too many deadlocks", 2, []error{deadlockErr, deadlockErr, deadlockErr}, 3, deadlockErr},
+		{"retries disabled", 0, []error{serializationErr}, 1, serializationErr},
+		{"other errors are not retried", 3, []error{otherErr}, 1, otherErr},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			calls := 0
+			err := withRetries(context.Background(), c.maxRetries, func() error {
+				calls++
+				if calls <= len(c.errors) {
+					return c.errors[calls-1]
+				}
+				return nil
+			})
+			if err != c.expectedErr {
+				t.Errorf("expected error %v, got %v", c.expectedErr, err)
+			}
+			if calls != c.expectedCalls {
+				t.Errorf("expected %d calls, got %d", c.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestWithRetriesCancelled(t *testing.T) {
+	defer func(delay time.Duration) { retryDelay = delay }(retryDelay)
+	retryDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deadlockErr := &pq.Error{Code: pgErrDeadlockDetected}
+	calls := 0
+	err := withRetries(ctx, 3, func() error {
+		calls++
+		return deadlockErr
+	})
+	if err != deadlockErr {
+		t.Errorf("expected error %v, got %v", deadlockErr, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no retry once the context is done, got %d calls", calls)
+	}
+}
+
+func TestRemoveIfNotFound(t *testing.T) {
+	var tests = []struct {
+		name       string
+		err        error
+		wantErr    bool
+		wantRemove bool
+	}{
+		{"success", nil, false, false},
+		{"database dropped", classifyError(fmt.Errorf("could not read: %w", &pq.Error{Code: pgErrInvalidCatalogName})), false, true},
+		{"other error", classifyError(&pq.Error{Code: pgErrInsufficientPrivilege}), true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourcePostgreSQLSchema().Schema, map[string]interface{}{})
+			d.SetId("app.public")
+
+			err := removeIfNotFound(func(*schema.ResourceData, interface{}) error {
+				return test.err
+			})(d, nil)
+
+			if (err != nil) != test.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if removed := d.Id() == ""; removed != test.wantRemove {
+				t.Errorf("resource removed: got %t, want %t", removed, test.wantRemove)
+			}
+		})
+	}
+}
+
+func TestIsNonTransactionalStatement(t *testing.T) {
+	var tests = []struct {
+		statements string
+		want       bool
+	}{
+		{"CREATE TABLE t (id int)", false},
+		{"CREATE INDEX idx ON t (id)", false},
+		{"create index concurrently idx on t (id)", true},
+		{"CREATE UNIQUE INDEX CONCURRENTLY idx ON t (id)", true},
+		{"CREATE TABLE t (id int);\n  DROP INDEX CONCURRENTLY idx", true},
+		{`ALTER DATABASE "app" SET TABLESPACE fast`, true},
+		{"ALTER DATABASE app SET search_path TO public", false},
+		{"ALTER TYPE mood ADD VALUE 'happy'", true},
+		{"VACUUM ANALYZE t", true},
+		{"SELECT 'VACUUM'", false},
+	}
+
+	for _, test := range tests {
+		if got := isNonTransactionalStatement(test.statements); got != test.want {
+			t.Errorf("isNonTransactionalStatement(%q): got %t, want %t", test.statements, got, test.want)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"log"
-	"math/rand"
 	"net"
 	"os/exec"
 	"sync"
@@ -12,13 +11,16 @@ import (
 )
 
 const (
-	maximumPort = 60000
-	minimumPort = 10000
+	// The local address the tunnel listens on
+	jumpHostTunnelHost = "127.0.0.1"
+
+	// Attempts to get a free port not already allocated to another tunnel
+	tunnelPortAttempts = 10
 )
 
 var (
-	randomPortCache = make(map[string]int)
-	randomPortLock  = sync.Mutex{}
+	tunnelPortCache = make(map[string]int)
+	tunnelPortLock  = sync.Mutex{}
 
 	runningCommandsLock = &sync.Mutex{}
 	runningCommands     = make(map[int]chan error)
@@ -35,7 +37,9 @@ func connectToJumpHost(config *Config) error {
 	args := []string{
 		config.JumpHost,
 		"-o", "UserKnownHostsFile=/dev/null", "-o", "StrictHostKeyChecking=no",
-		"-L", fmt.Sprintf("127.0.0.1:%d:%s:%d", config.TunneledPort, config.Host, config.Port),
+		// Fail if the port was taken meanwhile, instead of connecting to whatever listens on it
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", jumpHostForward(config),
 		"-N",
 	}
 	var combinedOutput bytes.Buffer
@@ -58,7 +62,7 @@ func connectToJumpHost(config *Config) error {
 		case err := <-errorChannel:
 			return fmt.Errorf("ssh exited: %w %s", err, combinedOutput.String())
 		case <-time.After(time.Millisecond * 100):
-			_, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", config.TunneledPort))
+			_, err := net.Dial("tcp", hostPort(jumpHostTunnelHost, config.TunneledPort))
 			if err == nil {
 				log.Printf("[DEBUG] Sucessfuly connected on tunnel port %d\n", config.TunneledPort)
 				runningCommands[config.TunneledPort] = errorChannel
@@ -72,15 +76,55 @@ func connectToJumpHost(config *Config) error {
 	return fmt.Errorf("ssh failed to connect: %s", combinedOutput.String())
 }
 
-func getRandomPort(cacheKey string) int {
-	if port, ok := randomPortCache[cacheKey]; ok {
-		return port
+// jumpHostForward returns the ssh -L argument forwarding the tunnel port to the PostgreSQL server,
+// IPv6 literals being in brackets (127.0.0.1:10000:[2001:db8::1]:5432).
+func jumpHostForward(config *Config) string {
+	return fmt.Sprintf("%s:%s", hostPort(jumpHostTunnelHost, config.TunneledPort), hostPort(config.Host, config.Port))
+}
+
+// tunnelPortCacheKey returns the key of the tunnel port of the server,
+// the same for its bracketed and unbracketed IPv6 literal.
+func tunnelPortCacheKey(host string, port int) string {
+	return hostPort(host, port)
+}
+
+// getTunnelPort returns the local port of the tunnel to the server, allocated once per server.
+// The port is a free one assigned by the system (and not allocated to the tunnel of another server),
+// so the tunnels opened concurrently by the provider do not collide.
+func getTunnelPort(cacheKey string) (int, error) {
+	tunnelPortLock.Lock()
+	defer tunnelPortLock.Unlock()
+	if port, ok := tunnelPortCache[cacheKey]; ok {
+		return port, nil
+	}
+
+	allocated := make(map[int]bool, len(tunnelPortCache))
+	for _, port := range tunnelPortCache {
+		allocated[port] = true
+	}
+	for attempt := 0; attempt < tunnelPortAttempts; attempt++ {
+		port, err := freeLocalPort()
+		if err != nil {
+			return 0, err
+		}
+		if !allocated[port] {
+			log.Printf("[DEBUG] Allocated local port %d to the tunnel to %s", port, cacheKey)
+			tunnelPortCache[cacheKey] = port
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("could not find a free local port for the tunnel to %s", cacheKey)
+}
+
+// freeLocalPort returns a port of the tunnel address which is free, as assigned by the system.
+// It is released right away for ssh to listen on it.
+func freeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", hostPort(jumpHostTunnelHost, 0))
+	if err != nil {
+		return 0, fmt.Errorf("could not find a free local port for the tunnel: %w", err)
 	}
-	randomPortLock.Lock()
-	defer randomPortLock.Unlock()
-	result := rand.Intn(maximumPort-minimumPort) + minimumPort
-	randomPortCache[cacheKey] = result
-	return result
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
 }
 
 func WaitForRunningCommands() {
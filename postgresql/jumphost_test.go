@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"net"
+	"testing"
+)
+
+func TestJumpHostForward(t *testing.T) {
+	var tests = []struct {
+		host string
+		want string
+	}{
+		{"db.example.com", "127.0.0.1:10000:db.example.com:5432"},
+		{"10.0.0.1", "127.0.0.1:10000:10.0.0.1:5432"},
+		{"2001:db8::1", "127.0.0.1:10000:[2001:db8::1]:5432"},
+		{"[2001:db8::1]", "127.0.0.1:10000:[2001:db8::1]:5432"},
+	}
+
+	for _, test := range tests {
+		config := &Config{Host: test.host, Port: 5432, TunneledPort: 10000}
+		if got := jumpHostForward(config); got != test.want {
+			t.Errorf("jumpHostForward(%q) = %q, want %q", test.host, got, test.want)
+		}
+	}
+}
+
+func TestTunnelPortCacheKey(t *testing.T) {
+	// The same server gets the same tunnel port whether its IPv6 literal is bracketed or not
+	if tunnelPortCacheKey("::1", 5432) != tunnelPortCacheKey("[::1]", 5432) {
+		t.Errorf("tunnelPortCacheKey differs for ::1 and [::1]")
+	}
+	// ::1 on port 5432 is not ::15 on port 432
+	if tunnelPortCacheKey("::1", 5432) == tunnelPortCacheKey("::15", 432) {
+		t.Errorf("tunnelPortCacheKey is the same for ::1 port 5432 and ::15 port 432")
+	}
+}
+
+func TestGetTunnelPort(t *testing.T) {
+	first, err := getTunnelPort(tunnelPortCacheKey("db1.example.com", 5432))
+	if err != nil {
+		t.Fatalf("could not get tunnel port: %v", err)
+	}
+	second, err := getTunnelPort(tunnelPortCacheKey("db2.example.com", 5432))
+	if err != nil {
+		t.Fatalf("could not get tunnel port: %v", err)
+	}
+	if first == second {
+		t.Errorf("the tunnels to two servers got the same port %d", first)
+	}
+
+	// The port is allocated once per server
+	again, err := getTunnelPort(tunnelPortCacheKey("db1.example.com", 5432))
+	if err != nil {
+		t.Fatalf("could not get tunnel port: %v", err)
+	}
+	if again != first {
+		t.Errorf("the tunnel to the same server got port %d then %d", first, again)
+	}
+
+	// The port is free for ssh to listen on it
+	listener, err := net.Listen("tcp", hostPort(jumpHostTunnelHost, first))
+	if err != nil {
+		t.Fatalf("could not listen on tunnel port %d: %v", first, err)
+	}
+	listener.Close()
+}
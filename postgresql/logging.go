@@ -0,0 +1,130 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"log"
+	"regexp"
+)
+
+// passwordLiteralRe matches the password literals of CREATE/ALTER ROLE statements.
+var passwordLiteralRe = regexp.MustCompile(`(?i)(PASSWORD\s+)'(?:[^']|'')*'`)
+
+// redactStatement hides the secrets of a SQL statement before it is logged.
+func redactStatement(query string) string {
+	return passwordLiteralRe.ReplaceAllString(query, "${1}'********'")
+}
+
+// logStatement logs the statement executed by the provider.
+// Arguments are not logged as they may contain secrets.
+func logStatement(query string, nArgs int) {
+	log.Printf("[DEBUG] executing SQL statement (%d args): %s", nArgs, redactStatement(query))
+}
+
+// loggingConnector wraps a connector to log every statement executed
+// on its connections (cf. the log_statements provider option).
+type loggingConnector struct {
+	connector driver.Connector
+}
+
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{conn: conn}, nil
+}
+
+func (c *loggingConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// loggingConn logs the statements before forwarding them to the wrapped connection.
+type loggingConn struct {
+	conn driver.Conn
+}
+
+func (c *loggingConn) Prepare(query string) (driver.Stmt, error) {
+	logStatement(query, 0)
+	return c.conn.Prepare(query)
+}
+
+func (c *loggingConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *loggingConn) Begin() (driver.Tx, error) {
+	logStatement("BEGIN", 0)
+	tx, err := c.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &loggingTx{tx: tx}, nil
+}
+
+func (c *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	beginner, ok := c.conn.(driver.ConnBeginTx)
+	if !ok {
+		return c.Begin()
+	}
+	logStatement("BEGIN", 0)
+	tx, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingTx{tx: tx}, nil
+}
+
+func (c *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql will prepare the statement instead
+		return nil, driver.ErrSkip
+	}
+	logStatement(query, len(args))
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		// database/sql will prepare the statement instead
+		return nil, driver.ErrSkip
+	}
+	logStatement(query, len(args))
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *loggingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *loggingConn) ResetSession(ctx context.Context) error {
+	return resetSession(ctx, c.conn)
+}
+
+func (c *loggingConn) IsValid() bool {
+	return isValidConn(c.conn)
+}
+
+func (c *loggingConn) CheckNamedValue(value *driver.NamedValue) error {
+	return checkNamedValue(c.conn, value)
+}
+
+// loggingTx logs the end of the transactions.
+type loggingTx struct {
+	tx driver.Tx
+}
+
+func (t *loggingTx) Commit() error {
+	logStatement("COMMIT", 0)
+	return t.tx.Commit()
+}
+
+func (t *loggingTx) Rollback() error {
+	logStatement("ROLLBACK", 0)
+	return t.tx.Rollback()
+}
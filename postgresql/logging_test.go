@@ -0,0 +1,90 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedactStatement(t *testing.T) {
+	var tests = []struct {
+		query    string
+		expected string
+	}{
+		{
+			"SELECT 1",
+			"SELECT 1",
+		},
+		{
+			`CREATE ROLE "foo" LOGIN ENCRYPTED PASSWORD 'secret'`,
+			`CREATE ROLE "foo" LOGIN ENCRYPTED PASSWORD '********'`,
+		},
+		{
+			`ALTER ROLE "foo" password 'it''s secret' VALID UNTIL 'infinity'`,
+			`ALTER ROLE "foo" password '********' VALID UNTIL 'infinity'`,
+		},
+		{
+			`ALTER ROLE "foo" PASSWORD NULL`,
+			`ALTER ROLE "foo" PASSWORD NULL`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := redactStatement(test.query); got != test.expected {
+			t.Errorf("redactStatement(%q) = %q, want %q", test.query, got, test.expected)
+		}
+	}
+}
+
+// fakeConn is a driver connection doing nothing.
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+func (fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func TestLoggingConn(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	conn := &loggingConn{conn: fakeConn{}}
+
+	tx, err := conn.BeginTx(context.Background(), driver.TxOptions{})
+	if err != nil {
+		t.Fatalf("could not begin transaction: %v", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), `ALTER ROLE "foo" PASSWORD 'secret'`, nil); err != nil {
+		t.Fatalf("could not execute statement: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("could not commit transaction: %v", err)
+	}
+
+	// fakeConn does not support QueryContext, database/sql has to prepare the statement
+	if _, err := conn.QueryContext(context.Background(), "SELECT 1", nil); err != driver.ErrSkip {
+		t.Errorf("expected driver.ErrSkip, got %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{"BEGIN", `ALTER ROLE "foo" PASSWORD '********'`, "COMMIT"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q to be logged, got: %s", expected, output)
+		}
+	}
+	if strings.Contains(output, "secret") {
+		t.Errorf("password should not be logged, got: %s", output)
+	}
+}
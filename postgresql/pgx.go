@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/lib/pq"
+)
+
+// Values of the driver setting
+const (
+	driverPQ  = "pq"
+	driverPgx = "pgx"
+)
+
+// checkDriver returns an error if a setting cannot be applied with the pgx driver:
+// the settings implemented by the provider on top of lib/pq (e.g.: require_auth) are not available with it.
+func (c *Config) checkDriver() error {
+	if c.Driver != driverPgx {
+		return nil
+	}
+
+	if c.Scheme != "postgres" {
+		return fmt.Errorf("driver = pgx is only supported with the postgres scheme, got %q", c.Scheme)
+	}
+
+	var unsupported string
+	switch {
+	case c.RequireAuth != "":
+		unsupported = "require_auth"
+	case c.SSLNegotiation == sslNegotiationDirect:
+		unsupported = "sslnegotiation = direct"
+	case c.SSLHostname != "":
+		unsupported = "sslhostname"
+	case c.SSLCRLPath != "" || c.SSLCRLDir != "":
+		unsupported = "sslcrl and sslcrldir"
+	case c.SSLClientCert != nil && c.SSLClientCert.loadedByProvider():
+		unsupported = "clientcert key_passphrase and pkcs12"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%s cannot be used with driver = pgx, use driver = pq", unsupported)
+}
+
+// sqlDriver returns the database/sql driver of the connections.
+func (c *Config) sqlDriver() driver.Driver {
+	if c.Driver == driverPgx {
+		return stdlib.GetDefaultDriver()
+	}
+	return &pq.Driver{}
+}
+
+// newPgxConnector returns the connector opening the connections of the DSN with the pgx driver,
+// which sends a cancel request to the server when the context of a statement is done.
+func newPgxConnector(dsn string) (driver.Connector, error) {
+	config, err := pgxConnConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return stdlib.GetConnector(*config), nil
+}
+
+// pgxConnConfig parses the DSN for the pgx driver.
+//
+// The statements are executed with the simple protocol, as with lib/pq for the statements without parameters,
+// so several statements can be executed at once (e.g.: by postgresql_query) and no statement is prepared
+// (which would fail through a transaction pooler like PgBouncer).
+func pgxConnConfig(dsn string) (*pgx.ConnConfig, error) {
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse connection string: %w", err)
+	}
+	config.PreferSimpleProtocol = true
+
+	// pgx sends the unknown parameters to the server as settings,
+	// fallback_application_name is only known by the client libraries.
+	if name, ok := config.RuntimeParams["fallback_application_name"]; ok {
+		delete(config.RuntimeParams, "fallback_application_name")
+		if config.RuntimeParams["application_name"] == "" {
+			config.RuntimeParams["application_name"] = name
+		}
+	}
+	return config, nil
+}
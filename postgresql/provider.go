@@ -3,7 +3,9 @@ package postgresql
 import (
 	"context"
 	"fmt"
+	"log"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/terraform-providers/terraform-provider-postgresql/postgresql/contexts"
@@ -17,6 +19,7 @@ import (
 const (
 	defaultProviderMaxOpenConnections = 20
 	defaultExpectedPostgreSQLVersion  = "9.0.0"
+	defaultProviderMaxRetries         = 3
 )
 
 func init() {
@@ -46,19 +49,24 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 			"port": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PGPORT", 5432),
+				DefaultFunc: schema.EnvDefaultFunc("PGPORT", nil),
 				Description: "The PostgreSQL port number to connect to at the server host, or socket file name extension for Unix-domain connections",
 			},
 			"database": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "The name of the database to connect to in order to conenct to (defaults to `postgres`).",
-				DefaultFunc: schema.EnvDefaultFunc("PGDATABASE", "postgres"),
+				DefaultFunc: schema.EnvDefaultFunc("PGDATABASE", nil),
+			},
+			"maintenance_database": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The database to connect to in order to create, alter or drop databases (defaults to `database`).",
 			},
 			"username": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("PGUSER", "postgres"),
+				DefaultFunc: schema.EnvDefaultFunc("PGUSER", nil),
 				Description: "PostgreSQL user name to connect as",
 			},
 			"password": {
@@ -68,12 +76,60 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 				Description: "Password to be used if the PostgreSQL server demands password authentication",
 				Sensitive:   true,
 			},
+			"connection_string": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Connection string (URL or keyword/value pairs) to read the connection settings from, the other attributes take precedence",
+				Sensitive:   true,
+			},
+			"service": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGSERVICE", nil),
+				Description: "Name of the service of the connection service file (pg_service.conf) to read the connection settings from, the other attributes and the connection string take precedence",
+			},
+			"username_command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Command printing the user name to connect as (e.g.: for brokered or dynamic credentials), it takes precedence over username",
+				Sensitive:   true,
+			},
 			"password_command": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Description: "Password command to be used if the PostgreSQL server demands password authentication",
 				Sensitive:   true,
 			},
+			"password_command_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "Number of seconds the output of the password command is reused before running it again, 0 to reuse it for the whole run",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"aws_rds_iam_auth": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Authenticate with an AWS RDS IAM token (for RDS or RDS Proxy) instead of the password, " +
+					"a new token is generated for each connection",
+			},
+			"aws_rds_iam_profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The AWS profile of the credentials signing the RDS IAM tokens (the default credential chain if empty)",
+			},
+			"aws_rds_iam_region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The AWS region of the RDS instance or proxy (the region of the AWS profile or environment if empty)",
+			},
+			"aws_rds_iam_endpoint": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "The host:port the RDS IAM tokens are generated for, e.g.: the RDS Proxy endpoint " +
+					"when connecting through a tunnel (defaults to host:port)",
+			},
 			// Conection username can be different than database username with user name mapas (e.g.: in Azure)
 			// See https://www.postgresql.org/docs/current/auth-username-maps.html
 			"database_username": {
@@ -87,7 +143,8 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("PGSUPERUSER", true),
 				Description: "Specify if the user to connect as is a Postgres superuser or not." +
-					"If not, some feature might be disabled (e.g.: Refreshing state password from Postgres)",
+					"If not, some feature might be disabled (e.g.: Refreshing state password from Postgres). " +
+					"When true, the privileges of the connected user are still checked and these features are skipped if not allowed",
 			},
 
 			"sslmode": {
@@ -96,6 +153,34 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("PGSSLMODE", nil),
 				Description: "This option determines whether or with what priority a secure SSL TCP/IP connection will be negotiated with the PostgreSQL server",
 			},
+			"channel_binding": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PGCHANNELBINDING", nil),
+				ValidateFunc: validation.StringInSlice([]string{negotiationDisable, negotiationPrefer, negotiationRequire}, false),
+				Description:  "Whether SCRAM channel binding is used (disable, prefer or require)",
+			},
+			"gssencmode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PGGSSENCMODE", nil),
+				ValidateFunc: validation.StringInSlice([]string{negotiationDisable, negotiationPrefer, negotiationRequire}, false),
+				Description:  "Whether a GSSAPI encrypted connection is negotiated with the server (disable, prefer or require)",
+			},
+			"sslnegotiation": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PGSSLNEGOTIATION", nil),
+				ValidateFunc: validation.StringInSlice([]string{sslNegotiationPostgres, sslNegotiationDirect}, false),
+				Description:  "How the SSL connection is negotiated with the server (postgres or direct, PostgreSQL 17+)",
+			},
+			"require_auth": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("PGREQUIREAUTH", nil),
+				ValidateFunc: validateRequireAuth,
+				Description:  "The authentication methods the server may request (e.g.: scram-sha-256), or the forbidden ones if prefixed with !",
+			},
 			"ssl_mode": {
 				Type:       schema.TypeString,
 				Optional:   true,
@@ -110,12 +195,23 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 						"cert": {
 							Type:        schema.TypeString,
 							Description: "The SSL client certificate file path. The file must contain PEM encoded data.",
-							Required:    true,
+							Optional:    true,
 						},
 						"key": {
 							Type:        schema.TypeString,
 							Description: "The SSL client certificate private key file path. The file must contain PEM encoded data.",
-							Required:    true,
+							Optional:    true,
+						},
+						"key_passphrase": {
+							Type:        schema.TypeString,
+							Description: "The passphrase of the encrypted private key or PKCS#12 bundle.",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"pkcs12": {
+							Type:        schema.TypeString,
+							Description: "The PKCS#12 bundle file path containing the SSL client certificate and its private key, instead of cert and key.",
+							Optional:    true,
 						},
 					},
 				},
@@ -126,6 +222,23 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 				Description: "The SSL server root certificate file path. The file must contain PEM encoded data.",
 				Optional:    true,
 			},
+			"sslcrl": {
+				Type:        schema.TypeString,
+				Description: "The SSL certificate revocation list file path (PEM or DER encoded), to reject the revoked server certificates.",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGSSLCRL", nil),
+			},
+			"sslcrldir": {
+				Type:        schema.TypeString,
+				Description: "The directory containing SSL certificate revocation list files, to reject the revoked server certificates.",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGSSLCRLDIR", nil),
+			},
+			"sslhostname": {
+				Type:        schema.TypeString,
+				Description: "The hostname to verify the SSL server certificate against (with sslmode verify-full), if different from host.",
+				Optional:    true,
+			},
 
 			"connect_timeout": {
 				Type:         schema.TypeInt,
@@ -134,13 +247,26 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 				Description:  "Maximum wait for connection, in seconds. Zero or not specified means wait indefinitely.",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
+			"options": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGOPTIONS", nil),
+				Description: "Command-line options sent to the server at connection start (e.g.: -c search_path=foo -c timezone=UTC)",
+			},
 			"max_connections": {
 				Type:         schema.TypeInt,
 				Optional:     true,
 				Default:      defaultProviderMaxOpenConnections,
-				Description:  "Maximum number of connections to establish to the database. Zero means unlimited.",
+				Description:  "Maximum number of connections to establish to the databases. Zero means unlimited.",
 				ValidateFunc: validation.IntAtLeast(-1),
 			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      defaultProviderMaxRetries,
+				Description:  "Maximum number of times a transaction is retried after a serialization failure or a deadlock. Zero disables the retries.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
 			"expected_version": {
 				Type:         schema.TypeString,
 				Optional:     true,
@@ -153,18 +279,60 @@ func Provider(ctx context.Context) terraform.ResourceProvider {
 				Optional:    true,
 				Description: "Jumphost used to connect.",
 			},
+			"set_role_chain": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Roles to assume in order with SET ROLE after connecting, the provider then acts as the last one.",
+			},
+			"session_variables": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Settings or custom variables (prefix.name) set on each connection (e.g. to identify the Terraform run in audit triggers).",
+			},
+			"log_statements": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGLOGSTATEMENTS", false),
+				Description: "Log every SQL statement executed by the provider (at DEBUG level, passwords are redacted).",
+			},
+			"driver": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      driverPQ,
+				ValidateFunc: validation.StringInSlice([]string{driverPQ, driverPgx}, false),
+				Description:  "The PostgreSQL driver opening the connections (pq or pgx)",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
+			"postgresql_collation":          resourcePostgreSQLCollation(),
 			"postgresql_database":           resourcePostgreSQLDatabase(),
 			"postgresql_default_privileges": resourcePostgreSQLDefaultPrivileges(),
 			"postgresql_extension":          resourcePostgreSQLExtension(),
 			"postgresql_grant":              resourcePostgreSQLGrant(),
 			"postgresql_grant_role":         resourcePostgreSQLGrantRole(),
+			"postgresql_maintenance":        resourcePostgreSQLMaintenance(),
+			"postgresql_preload_libraries":  resourcePostgreSQLPreloadLibraries(),
+			"postgresql_query":              resourcePostgreSQLQuery(),
 			"postgresql_replication_slot":   resourcePostgreSQLReplicationSlot(),
 			"postgresql_schema":             resourcePostgreSQLSchema(),
+			"postgresql_sequence":           resourcePostgreSQLSequence(),
 			"postgresql_role":               resourcePostgreSQLRole(),
 		},
+
+		DataSourcesMap: map[string]*schema.Resource{
+			"postgresql_connection_info":    dataSourcePostgreSQLConnectionInfo(),
+			"postgresql_database_settings":  dataSourcePostgreSQLDatabaseSettings(),
+			"postgresql_object_privileges":  dataSourcePostgreSQLObjectPrivileges(),
+			"postgresql_ready":              dataSourcePostgreSQLReady(),
+			"postgresql_role_members":       dataSourcePostgreSQLRoleMembers(),
+			"postgresql_role_password_info": dataSourcePostgreSQLRolePasswordInfo(),
+			"postgresql_sequence":           dataSourcePostgreSQLSequence(),
+			"postgresql_tablespaces":        dataSourcePostgreSQLTablespaces(),
+			"postgresql_wait_notify":        dataSourcePostgreSQLWaitNotify(),
+		},
 	}
 	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
 		return providerConfigure(contexts.Merge(provider.StopContext(), ctx), d)
@@ -179,7 +347,46 @@ func validateExpectedVersion(v interface{}, key string) (warnings []string, erro
 	return
 }
 
+// getServiceName returns the service to read the connection settings from,
+// from the provider attribute (or PGSERVICE) or the connection string.
+func getServiceName(d *schema.ResourceData, connParams map[string]string) string {
+	if v, ok := d.GetOk("service"); ok {
+		return v.(string)
+	}
+	return connParams["service"]
+}
+
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, error) {
+	// Settings not specified with the attributes are read from the connection string
+	connParams := map[string]string{}
+	if connStr, ok := d.GetOk("connection_string"); ok {
+		var err error
+		if connParams, err = parseConnectionString(connStr.(string)); err != nil {
+			return nil, err
+		}
+	}
+	// Then from the service file, if a service is specified
+	if service := getServiceName(d, connParams); service != "" {
+		serviceParams, err := readServiceParams(service, serviceFilePaths())
+		if err != nil {
+			return nil, err
+		}
+		for param, value := range serviceParams {
+			if _, ok := connParams[param]; !ok {
+				connParams[param] = value
+			}
+		}
+	}
+	getSetting := func(attr, param, defaultValue string) string {
+		if v, ok := d.GetOk(attr); ok {
+			return v.(string)
+		}
+		if v, ok := connParams[param]; ok {
+			return v
+		}
+		return defaultValue
+	}
+
 	var sslMode string
 	if sslModeRaw, ok := d.GetOk("sslmode"); ok {
 		sslMode = sslModeRaw.(string)
@@ -187,44 +394,145 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		sslModeDeprecated := d.Get("ssl_mode").(string)
 		if sslModeDeprecated != "" {
 			sslMode = sslModeDeprecated
+		} else {
+			sslMode = connParams["sslmode"]
 		}
 	}
 	versionStr := d.Get("expected_version").(string)
 	version, _ := semver.ParseTolerant(versionStr)
 
-	host := d.Get("host").(string)
+	host := normalizeHost(getSetting("host", "host", ""))
 	port := d.Get("port").(int)
+	if port == 0 {
+		port = 5432
+		if v, ok := connParams["port"]; ok {
+			var err error
+			if port, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("invalid port in connection string (%q): %w", v, err)
+			}
+		}
+	}
+
+	var setRoleChain []string
+	for _, role := range d.Get("set_role_chain").([]interface{}) {
+		setRoleChain = append(setRoleChain, role.(string))
+	}
+
+	sessionVariables := map[string]string{}
+	for name, value := range d.Get("session_variables").(map[string]interface{}) {
+		if err := validateSessionVariableName(name); err != nil {
+			return nil, err
+		}
+		sessionVariables[name] = value.(string)
+	}
+
+	username := getSetting("username", "user", "postgres")
+	if command, ok := d.GetOk("username_command"); ok {
+		var err error
+		if username, err = getUsernameFromCommand(ctx, command.(string)); err != nil {
+			return nil, err
+		}
+	}
 
 	config := Config{
-		Scheme:            d.Get("scheme").(string),
-		Host:              host,
-		Port:              port,
-		Username:          d.Get("username").(string),
-		Password:          d.Get("password").(string),
-		DatabaseUsername:  d.Get("database_username").(string),
-		Superuser:         d.Get("superuser").(bool),
-		SSLMode:           sslMode,
-		ApplicationName:   "Terraform provider",
-		ConnectTimeoutSec: d.Get("connect_timeout").(int),
-		MaxConns:          d.Get("max_connections").(int),
-		ExpectedVersion:   version,
-		SSLRootCertPath:   d.Get("sslrootcert").(string),
-		JumpHost:          d.Get("jumphost").(string),
-		// 1024 to 65535
-		TunneledPort:    getRandomPort(fmt.Sprintf("%s%d", host, port)),
-		PasswordCommand: d.Get("password_command").(string),
-		ctx:             ctx,
+		Scheme:              d.Get("scheme").(string),
+		Host:                host,
+		Port:                port,
+		Username:            username,
+		Password:            getSetting("password", "password", ""),
+		DatabaseUsername:    d.Get("database_username").(string),
+		Superuser:           d.Get("superuser").(bool),
+		SSLMode:             sslMode,
+		ApplicationName:     "Terraform provider",
+		ConnectTimeoutSec:   d.Get("connect_timeout").(int),
+		MaxConns:            d.Get("max_connections").(int),
+		MaxRetries:          d.Get("max_retries").(int),
+		ExpectedVersion:     version,
+		SSLRootCertPath:     getSetting("sslrootcert", "sslrootcert", ""),
+		SSLCRLPath:          getSetting("sslcrl", "sslcrl", ""),
+		SSLCRLDir:           getSetting("sslcrldir", "sslcrldir", ""),
+		SSLHostname:         d.Get("sslhostname").(string),
+		Options:             getSetting("options", "options", ""),
+		ChannelBinding:      getSetting("channel_binding", "channel_binding", negotiationPrefer),
+		GSSEncMode:          getSetting("gssencmode", "gssencmode", negotiationPrefer),
+		SSLNegotiation:      getSetting("sslnegotiation", "sslnegotiation", sslNegotiationPostgres),
+		RequireAuth:         getSetting("require_auth", "require_auth", ""),
+		JumpHost:            d.Get("jumphost").(string),
+		LogStatements:       d.Get("log_statements").(bool),
+		SetRoleChain:        setRoleChain,
+		SessionVariables:    sessionVariables,
+		PasswordCommand:     d.Get("password_command").(string),
+		PasswordCommandTTL:  d.Get("password_command_ttl").(int),
+		MaintenanceDatabase: d.Get("maintenance_database").(string),
+		Driver:              d.Get("driver").(string),
+		ctx:                 ctx,
+	}
+
+	if config.JumpHost != "" {
+		tunneledPort, err := getTunnelPort(tunnelPortCacheKey(host, port))
+		if err != nil {
+			return nil, err
+		}
+		config.TunneledPort = tunneledPort
 	}
 
 	if value, ok := d.GetOk("clientcert"); ok {
 		if spec, ok := value.([]interface{})[0].(map[string]interface{}); ok {
-			config.SSLClientCert = &ClientCertificateConfig{
-				CertificatePath: spec["cert"].(string),
-				KeyPath:         spec["key"].(string),
+			clientCert, err := clientCertificateConfig(spec)
+			if err != nil {
+				return nil, err
 			}
+			config.SSLClientCert = clientCert
 		}
+	} else if connParams["sslcert"] != "" && connParams["sslkey"] != "" {
+		config.SSLClientCert = &ClientCertificateConfig{
+			CertificatePath: connParams["sslcert"],
+			KeyPath:         connParams["sslkey"],
+		}
+	}
+
+	if err := config.checkNegotiationModes(); err != nil {
+		return nil, err
+	}
+	if err := config.checkDriver(); err != nil {
+		return nil, err
+	}
+	if d.Get("aws_rds_iam_auth").(bool) {
+		generator, err := newRDSIAMTokenGenerator(
+			&config, d.Get("aws_rds_iam_profile").(string), d.Get("aws_rds_iam_region").(string), d.Get("aws_rds_iam_endpoint").(string),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if config.Password != "" || config.PasswordCommand != "" {
+			log.Printf("[WARN] password and password_command are ignored with aws_rds_iam_auth")
+		}
+		config.tokenGenerator = generator
+	}
+	if config.SSLNegotiation == sslNegotiationDirect && !config.requestsDirectSSL() {
+		log.Printf("[WARN] sslnegotiation = direct is ignored: it requires the postgres scheme (got %s)", config.Scheme)
 	}
 
-	client := config.NewClient(d.Get("database").(string))
+	client := config.NewClient(getSetting("database", "dbname", "postgres"))
 	return client, nil
 }
+
+// clientCertificateConfig returns the configuration of the clientcert block,
+// which requires either cert and key or pkcs12.
+func clientCertificateConfig(spec map[string]interface{}) (*ClientCertificateConfig, error) {
+	config := &ClientCertificateConfig{
+		CertificatePath: spec["cert"].(string),
+		KeyPath:         spec["key"].(string),
+		KeyPassphrase:   spec["key_passphrase"].(string),
+		PKCS12Path:      spec["pkcs12"].(string),
+	}
+
+	if config.PKCS12Path != "" {
+		if config.CertificatePath != "" || config.KeyPath != "" {
+			return nil, fmt.Errorf("clientcert: cannot specify cert or key with pkcs12")
+		}
+	} else if config.CertificatePath == "" || config.KeyPath == "" {
+		return nil, fmt.Errorf("clientcert: cert and key (or pkcs12) are required")
+	}
+	return config, nil
+}
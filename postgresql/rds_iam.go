@@ -0,0 +1,114 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+)
+
+// authTokenGenerator returns the password of each new connection
+// for the authentications based on short-lived tokens.
+type authTokenGenerator interface {
+	token() (string, error)
+}
+
+// rdsIAMTokenGenerator generates the IAM authentication tokens of AWS RDS and RDS Proxy:
+// they are signed the same way, for the endpoint the client connects to
+// (the instance, the cluster or the proxy endpoint).
+type rdsIAMTokenGenerator struct {
+	endpoint    string
+	region      string
+	user        string
+	credentials *credentials.Credentials
+}
+
+// newRDSIAMTokenGenerator returns the token generator for the provider configuration,
+// using the credentials of the AWS profile (or of the default credential chain).
+func newRDSIAMTokenGenerator(c *Config, profile, region, endpoint string) (*rdsIAMTokenGenerator, error) {
+	if c.Scheme != "postgres" {
+		return nil, fmt.Errorf("the AWS RDS IAM authentication requires the postgres scheme, got %s", c.Scheme)
+	}
+	if endpoint == "" {
+		endpoint = hostPort(c.Host, c.Port)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:           profile,
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            aws.Config{Region: aws.String(region)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load the AWS credentials for the RDS IAM authentication: %w", err)
+	}
+	if region == "" {
+		region = aws.StringValue(sess.Config.Region)
+	}
+	if region == "" {
+		return nil, errors.New("the AWS region of the RDS IAM authentication is not set (aws_rds_iam_region or AWS_REGION)")
+	}
+
+	return &rdsIAMTokenGenerator{
+		endpoint:    endpoint,
+		region:      region,
+		user:        c.Username,
+		credentials: sess.Config.Credentials,
+	}, nil
+}
+
+func (g *rdsIAMTokenGenerator) token() (string, error) {
+	log.Printf("[DEBUG] Generating an RDS IAM authentication token for %s@%s", g.user, g.endpoint)
+	token, err := rdsutils.BuildAuthToken(g.endpoint, g.region, g.user, g.credentials)
+	if err != nil {
+		return "", fmt.Errorf("could not generate the RDS IAM authentication token: %w", err)
+	}
+	return token, nil
+}
+
+// tokenConnector opens each connection with a new token as password, so the connections
+// opened late in a long run (e.g.: by a pool for another database) are not refused
+// because the token generated when the provider was configured expired
+// (the RDS IAM tokens are valid for 15 minutes).
+type tokenConnector struct {
+	dsn          string
+	generator    authTokenGenerator
+	newConnector func(dsn string) (driver.Connector, error)
+	driver       driver.Driver
+}
+
+func (c *tokenConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	token, err := c.generator.token()
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := dsnWithPassword(c.dsn, token)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := c.newConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *tokenConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnWithPassword returns the DSN (URL) with the password replaced.
+func dsnWithPassword(dsn, password string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("could not parse connection string: %w", err)
+	}
+	u.User = url.UserPassword(u.User.Username(), password)
+	return u.String(), nil
+}
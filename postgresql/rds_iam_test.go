@@ -0,0 +1,98 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// countingTokenGenerator returns a new token each time.
+type countingTokenGenerator struct {
+	count int
+}
+
+func (g *countingTokenGenerator) token() (string, error) {
+	g.count++
+	return fmt.Sprintf("token/%d?", g.count), nil
+}
+
+func TestTokenConnector(t *testing.T) {
+	var dsns []string
+	connector := &tokenConnector{
+		dsn:       "postgres://iam_user:@proxy.example.com:5432/postgres?sslmode=require",
+		generator: &countingTokenGenerator{},
+		newConnector: func(dsn string) (driver.Connector, error) {
+			dsns = append(dsns, dsn)
+			return &recordingConnector{}, nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := connector.Connect(context.Background()); err != nil {
+			t.Fatalf("could not connect: %v", err)
+		}
+	}
+
+	var passwords []string
+	for _, dsn := range dsns {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			t.Fatalf("could not parse DSN %s: %v", dsn, err)
+		}
+		password, _ := u.User.Password()
+		passwords = append(passwords, password)
+		if u.User.Username() != "iam_user" || u.Host != "proxy.example.com:5432" || u.RawQuery != "sslmode=require" {
+			t.Errorf("unexpected DSN %s", dsn)
+		}
+	}
+
+	expected := []string{"token/1?", "token/2?"}
+	if !reflect.DeepEqual(passwords, expected) {
+		t.Errorf("connections opened with passwords %v, want %v", passwords, expected)
+	}
+}
+
+func TestRDSIAMTokenGenerator(t *testing.T) {
+	generator := &rdsIAMTokenGenerator{
+		endpoint:    "proxy.proxy-abcdefghijkl.eu-west-1.rds.amazonaws.com:5432",
+		region:      "eu-west-1",
+		user:        "iam_user",
+		credentials: credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", ""),
+	}
+
+	token, err := generator.token()
+	if err != nil {
+		t.Fatalf("could not generate token: %v", err)
+	}
+	for _, part := range []string{generator.endpoint + "?", "Action=connect", "DBUser=iam_user", "X-Amz-Signature="} {
+		if !strings.Contains(token, part) {
+			t.Errorf("token %s does not contain %s", token, part)
+		}
+	}
+}
+
+func TestConnStrWithTokenGenerator(t *testing.T) {
+	config := &Config{
+		Scheme:         "postgres",
+		Host:           "localhost",
+		Port:           5432,
+		Username:       "iam_user",
+		Password:       "ignored",
+		SSLMode:        "require",
+		tokenGenerator: &countingTokenGenerator{},
+	}
+
+	dsn, err := config.connStr("postgres")
+	if err != nil {
+		t.Fatalf("could not build connection string: %v", err)
+	}
+	if !strings.HasPrefix(dsn, "postgres://iam_user:@localhost:5432/postgres?") {
+		t.Errorf("the password must not be in the connection string: %s", dsn)
+	}
+}
@@ -0,0 +1,251 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Authentication methods of the require_auth setting, as with libpq
+const (
+	requireAuthPassword    = "password"
+	requireAuthMD5         = "md5"
+	requireAuthGSS         = "gss"
+	requireAuthSSPI        = "sspi"
+	requireAuthSCRAMSHA256 = "scram-sha-256"
+	requireAuthNone        = "none"
+)
+
+// authRequestMethods maps the authentication requests of the server (AuthenticationRequest message codes)
+// to their require_auth method. The continuation requests (GSS, SASL) are not listed.
+var authRequestMethods = map[uint32]string{
+	3:  requireAuthPassword,
+	5:  requireAuthMD5,
+	7:  requireAuthGSS,
+	9:  requireAuthSSPI,
+	10: requireAuthSCRAMSHA256,
+}
+
+// authenticationOK is the code of the AuthenticationOk message.
+const authenticationOK = 0
+
+// requireAuth is the parsed require_auth setting: the allowed methods,
+// or the forbidden ones if the methods are prefixed with !.
+type requireAuth struct {
+	methods map[string]bool
+	negated bool
+}
+
+// parseRequireAuth parses the comma separated list of methods of the require_auth setting.
+// As with libpq, the methods are either all allowed or all forbidden (prefixed with !).
+func parseRequireAuth(value string) (*requireAuth, error) {
+	valid := map[string]bool{
+		requireAuthPassword: true, requireAuthMD5: true, requireAuthGSS: true,
+		requireAuthSSPI: true, requireAuthSCRAMSHA256: true, requireAuthNone: true,
+	}
+
+	result := &requireAuth{methods: map[string]bool{}}
+	for i, method := range strings.Split(value, ",") {
+		method = strings.TrimSpace(method)
+		negated := strings.HasPrefix(method, "!")
+		method = strings.TrimPrefix(method, "!")
+
+		if !valid[method] {
+			return nil, fmt.Errorf("invalid require_auth method %q", method)
+		}
+		if i == 0 {
+			result.negated = negated
+		} else if negated != result.negated {
+			return nil, fmt.Errorf("require_auth %q cannot mix allowed and forbidden (!) methods", value)
+		}
+		result.methods[method] = true
+	}
+	return result, nil
+}
+
+func validateRequireAuth(v interface{}, key string) ([]string, []error) {
+	if _, err := parseRequireAuth(v.(string)); err != nil {
+		return nil, []error{err}
+	}
+	return nil, nil
+}
+
+func (r *requireAuth) allowed(method string) bool {
+	return r.methods[method] != r.negated
+}
+
+// requireAuthConnector opens the connections of the DSN through a dialer
+// checking the authentication method requested by the server (see requireAuthConn).
+type requireAuthConnector struct {
+	dsn    string
+	dialer pq.DialerContext
+}
+
+func (c *requireAuthConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return dialOpen(ctx, c.dialer, c.dsn)
+}
+
+func (c *requireAuthConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// newRequireAuthConnector returns the connector to use for the DSN in order to enforce require_auth.
+// lib/pq does not implement it, so the authentication requests of the server are checked on the connection
+// before lib/pq reads them. As they cannot be read in an SSL connection established by lib/pq,
+// the SSL connection is then established by the provider (see directSSLConnector).
+func newRequireAuthConnector(dsn string, config *Config) (*requireAuthConnector, error) {
+	auth, err := parseRequireAuth(config.RequireAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer pq.DialerContext
+	if config.SSLMode == "disable" {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse connection string: %w", err)
+		}
+		dialer = addressDialer{address: u.Host}
+	} else {
+		sslConnector, err := newDirectSSLConnector(dsn, config)
+		if err != nil {
+			return nil, err
+		}
+		dsn, dialer = sslConnector.dsn, sslConnector.dialer
+	}
+
+	return &requireAuthConnector{
+		dsn:    dsn,
+		dialer: requireAuthDialer{dialer: dialer, auth: auth},
+	}, nil
+}
+
+// requireAuthDialer wraps the connections of the dialer to check the authentication method.
+type requireAuthDialer struct {
+	dialer pq.DialerContext
+	auth   *requireAuth
+}
+
+func (d requireAuthDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d requireAuthDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d requireAuthDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &requireAuthConn{Conn: conn, auth: d.auth}, nil
+}
+
+// requireAuthConn checks the authentication requests sent by the server at the start of the connection,
+// before they are read by lib/pq: a forbidden method fails the connection before any credential is sent.
+// Once the authentication is done, the messages are read as is.
+type requireAuthConn struct {
+	net.Conn
+	auth *requireAuth
+
+	// Messages read from the server but not complete yet
+	pending []byte
+	// Messages checked, not read by lib/pq yet
+	checked []byte
+	// Whether the server requested an authentication method
+	requested bool
+	// Whether the authentication is done (or failed)
+	done bool
+	// Buffer of the messages read until the authentication is done
+	buf []byte
+}
+
+func (c *requireAuthConn) Read(p []byte) (int, error) {
+	for len(c.checked) == 0 && !c.done {
+		if c.buf == nil {
+			c.buf = make([]byte, 4096)
+		}
+		n, err := c.Conn.Read(c.buf)
+		c.pending = append(c.pending, c.buf[:n]...)
+		if checkErr := c.checkPending(); checkErr != nil {
+			return 0, checkErr
+		}
+		if err != nil && len(c.checked) == 0 {
+			return 0, err
+		}
+	}
+
+	if c.done {
+		// The messages are read as is from now on
+		c.buf = nil
+	}
+
+	if len(c.checked) > 0 {
+		n := copy(p, c.checked)
+		c.checked = c.checked[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
+// checkPending checks the complete messages read, which lib/pq can then read.
+func (c *requireAuthConn) checkPending() error {
+	// Type (1 byte) then length (4 bytes, including itself)
+	for !c.done && len(c.pending) >= 5 {
+		size := 1 + int(binary.BigEndian.Uint32(c.pending[1:5]))
+		if len(c.pending) < size {
+			return nil
+		}
+		if err := c.checkMessage(c.pending[0], c.pending[5:size]); err != nil {
+			return err
+		}
+		c.checked = append(c.checked, c.pending[:size]...)
+		c.pending = c.pending[size:]
+	}
+	if c.done {
+		c.checked = append(c.checked, c.pending...)
+		c.pending = nil
+	}
+	return nil
+}
+
+func (c *requireAuthConn) checkMessage(messageType byte, body []byte) error {
+	switch messageType {
+	case 'R':
+		if len(body) < 4 {
+			return fmt.Errorf("invalid authentication request from the server")
+		}
+		code := binary.BigEndian.Uint32(body[:4])
+		if code == authenticationOK {
+			c.done = true
+			if !c.requested && !c.auth.allowed(requireAuthNone) {
+				return fmt.Errorf("require_auth: the server did not request any authentication")
+			}
+			return nil
+		}
+		method, ok := authRequestMethods[code]
+		if !ok {
+			// Continuation of the current method
+			return nil
+		}
+		if !c.auth.allowed(method) {
+			c.done = true
+			return fmt.Errorf("require_auth: the server requested the %s authentication method, which is not allowed", method)
+		}
+		c.requested = true
+	case 'E':
+		// The authentication failed, the error is read by lib/pq
+		c.done = true
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package postgresql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestParseRequireAuth(t *testing.T) {
+	var tests = []struct {
+		value         string
+		allowed       []string
+		forbidden     []string
+		expectedError string
+	}{
+		{"scram-sha-256", []string{"scram-sha-256"}, []string{"password", "md5", "none"}, ""},
+		{"scram-sha-256, gss", []string{"scram-sha-256", "gss"}, []string{"md5"}, ""},
+		{"!password,!md5", []string{"scram-sha-256", "none"}, []string{"password", "md5"}, ""},
+		{"!none", []string{"md5"}, []string{"none"}, ""},
+		{"scram-sha-256,!md5", nil, nil, "cannot mix"},
+		{"kerberos", nil, nil, `invalid require_auth method "kerberos"`},
+	}
+
+	for _, test := range tests {
+		auth, err := parseRequireAuth(test.value)
+		if test.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("parseRequireAuth(%q) error = %v, want %q", test.value, err, test.expectedError)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRequireAuth(%q) failed: %v", test.value, err)
+			continue
+		}
+		for _, method := range test.allowed {
+			if !auth.allowed(method) {
+				t.Errorf("parseRequireAuth(%q): %s must be allowed", test.value, method)
+			}
+		}
+		for _, method := range test.forbidden {
+			if auth.allowed(method) {
+				t.Errorf("parseRequireAuth(%q): %s must not be allowed", test.value, method)
+			}
+		}
+	}
+}
+
+// authMessage returns an AuthenticationRequest message of the server.
+func authMessage(code uint32, data string) []byte {
+	message := []byte{'R', 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(message[1:5], uint32(8+len(data)))
+	binary.BigEndian.PutUint32(message[5:9], code)
+	return append(message, data...)
+}
+
+func TestRequireAuthConn(t *testing.T) {
+	// ReadyForQuery after the authentication, read as is
+	readyForQuery := []byte{'Z', 0, 0, 0, 5, 'I'}
+
+	var tests = []struct {
+		requireAuth   string
+		messages      [][]byte
+		expectedError string
+	}{
+		{"scram-sha-256", [][]byte{authMessage(10, "SCRAM-SHA-256\x00\x00"), authMessage(11, "r=nonce"), authMessage(12, "v=sig"), authMessage(0, "")}, ""},
+		{"scram-sha-256", [][]byte{authMessage(3, "")}, "the server requested the password authentication method"},
+		{"scram-sha-256", [][]byte{authMessage(0, "")}, "the server did not request any authentication"},
+		{"!password,!md5", [][]byte{authMessage(5, "salt")}, "the server requested the md5 authentication method"},
+		{"!password,!md5", [][]byte{authMessage(0, "")}, ""},
+		{"md5,none", [][]byte{authMessage(0, "")}, ""},
+	}
+
+	for _, test := range tests {
+		auth, err := parseRequireAuth(test.requireAuth)
+		if err != nil {
+			t.Fatalf("could not parse require_auth %q: %v", test.requireAuth, err)
+		}
+
+		client, server := net.Pipe()
+		var sent []byte
+		for _, message := range append(test.messages, readyForQuery) {
+			sent = append(sent, message...)
+		}
+		go func() {
+			// Messages split across reads are checked once complete
+			for _, chunk := range [][]byte{sent[:3], sent[3:]} {
+				if _, err := server.Write(chunk); err != nil {
+					return
+				}
+			}
+		}()
+
+		conn := &requireAuthConn{Conn: client, auth: auth}
+		received := make([]byte, len(sent))
+		_, err = io.ReadFull(conn, received)
+		client.Close()
+		server.Close()
+
+		if test.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("require_auth %q: error = %v, want %q", test.requireAuth, err, test.expectedError)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("require_auth %q: could not read the messages: %v", test.requireAuth, err)
+		} else if !bytes.Equal(received, sent) {
+			t.Errorf("require_auth %q: read %v, want %v", test.requireAuth, received, sent)
+		}
+		if conn.buf != nil {
+			t.Errorf("require_auth %q: the read buffer must be released once the authentication is done", test.requireAuth)
+		}
+	}
+}
+
+func TestNewRequireAuthConnector(t *testing.T) {
+	for _, sslMode := range []string{"disable", "require"} {
+		config := &Config{
+			Scheme:      "postgres",
+			Host:        "10.0.0.1",
+			Port:        5433,
+			Username:    "user",
+			SSLMode:     sslMode,
+			RequireAuth: "scram-sha-256",
+		}
+		dsn, err := config.connStr("mydb")
+		if err != nil {
+			t.Fatalf("could not build connection string: %v", err)
+		}
+		if strings.Contains(dsn, "require_auth") {
+			t.Errorf("require_auth must not be sent to the server: %s", dsn)
+		}
+
+		connector, err := config.newConnector(dsn)
+		if err != nil {
+			t.Fatalf("could not create connector: %v", err)
+		}
+		authConnector, ok := connector.(*requireAuthConnector)
+		if !ok {
+			t.Fatalf("%s: connector is a %T, want *requireAuthConnector", sslMode, connector)
+		}
+		// The SSL connection is established by the provider, to read the authentication requests
+		if !strings.Contains(authConnector.dsn, "sslmode=disable") {
+			t.Errorf("%s: lib/pq must not negotiate SSL: %s", sslMode, authConnector.dsn)
+		}
+		dialer := authConnector.dialer.(requireAuthDialer).dialer
+		if _, isSSL := dialer.(directSSLDialer); isSSL != (sslMode != "disable") {
+			t.Errorf("%s: dialer is a %T", sslMode, dialer)
+		}
+	}
+}
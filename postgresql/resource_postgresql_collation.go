@@ -0,0 +1,317 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	collNameAttr           = "name"
+	collSchemaAttr         = "schema"
+	collDatabaseAttr       = "database"
+	collFromAttr           = "from"
+	collLocaleAttr         = "locale"
+	collProviderAttr       = "locale_provider"
+	collVersionAttr        = "version"
+	collActualVersionAttr  = "actual_version"
+	collRefreshVersionAttr = "refresh_version"
+)
+
+func resourcePostgreSQLCollation() *schema.Resource {
+	return &schema.Resource{
+		Create: PGResourceFunc(resourcePostgreSQLCollationCreate),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLCollationRead)),
+		Update: PGResourceFunc(resourcePostgreSQLCollationUpdate),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLCollationDelete)),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: resourcePostgreSQLCollationCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			collNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the collation",
+			},
+			collSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema in which the collation is created",
+			},
+			collDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database in which the collation is created",
+			},
+			collFromAttr: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{collLocaleAttr, collProviderAttr},
+				Description:   "The name of an existing collation to copy (e.g.: en-x-icu)",
+				// Not read from the database: empty after an import
+				DiffSuppressFunc: suppressUnknownCollationSource,
+			},
+			collLocaleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The locale of the collation, used for both LC_COLLATE and LC_CTYPE",
+				// Not read from the database: empty after an import
+				DiffSuppressFunc: suppressUnknownCollationSource,
+			},
+			collProviderAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"libc", "icu"}, false),
+				Description:  "The provider of the locale (libc or icu)",
+			},
+			collVersionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the collation recorded when it was created or refreshed",
+			},
+			collActualVersionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the collation currently provided by the operating system or ICU",
+			},
+			collRefreshVersionAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "If true, the recorded version is refreshed when it does not match the actual one " +
+					"(the indexes depending on the collation should be rebuilt first)",
+			},
+		},
+	}
+}
+
+// resourcePostgreSQLCollationCustomizeDiff plans the refresh of the version
+// when it is enabled and the recorded version does not match the actual one.
+func resourcePostgreSQLCollationCustomizeDiff(d *schema.ResourceDiff, _ interface{}) error {
+	return planCollationVersionRefresh(d, collRefreshVersionAttr, collVersionAttr, collActualVersionAttr)
+}
+
+func resourcePostgreSQLCollationCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureCollation) {
+		return unsupportedVersionError(db, "postgresql_collation resource")
+	}
+
+	database := getDatabase(d, db.client.databaseName)
+	collSchema := d.Get(collSchemaAttr).(string)
+	collName := d.Get(collNameAttr).(string)
+
+	query, err := createCollationQuery(
+		collSchema, collName, d.Get(collFromAttr).(string), d.Get(collLocaleAttr).(string), d.Get(collProviderAttr).(string),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("Error creating collation %s: %w", collName, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.SetId(generateCollationID(database, collSchema, collName))
+
+	return resourcePostgreSQLCollationReadImpl(db, d)
+}
+
+func resourcePostgreSQLCollationRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureCollation) {
+		return unsupportedVersionError(db, "postgresql_collation resource")
+	}
+
+	return resourcePostgreSQLCollationReadImpl(db, d)
+}
+
+func resourcePostgreSQLCollationReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	database, collSchema, collName, err := getDBCollationName(d, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+
+	exists, err := dbExists(db, database)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Printf("[WARN] database %s of collation %s not found", database, d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	// The versions are NULL for the C and POSIX locales, which have no version
+	var provider string
+	var version, actualVersion sql.NullString
+	query := "SELECT c.collprovider, c.collversion, pg_catalog.pg_collation_actual_version(c.oid) " +
+		"FROM pg_catalog.pg_collation c " +
+		"JOIN pg_catalog.pg_namespace n ON n.oid = c.collnamespace " +
+		"WHERE n.nspname = $1 AND c.collname = $2"
+	err = txn.QueryRow(query, collSchema, collName).Scan(&provider, &version, &actualVersion)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL collation %s.%s not found in database %s", collSchema, collName, database)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("Error reading collation: %w", err)
+	}
+
+	if version.String != actualVersion.String {
+		log.Printf(
+			"[WARN] collation %s.%s was created with version %s, but version %s is provided: "+
+				"rebuild the objects depending on the collation and run ALTER COLLATION ... REFRESH VERSION (or set %s)",
+			collSchema, collName, version.String, actualVersion.String, collRefreshVersionAttr,
+		)
+	}
+
+	_ = d.Set(collNameAttr, collName)
+	_ = d.Set(collSchemaAttr, collSchema)
+	_ = d.Set(collDatabaseAttr, database)
+	_ = d.Set(collProviderAttr, collationProviderName(provider))
+	_ = d.Set(collVersionAttr, version.String)
+	_ = d.Set(collActualVersionAttr, actualVersion.String)
+	d.SetId(generateCollationID(database, collSchema, collName))
+
+	return nil
+}
+
+func resourcePostgreSQLCollationUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureCollation) {
+		return unsupportedVersionError(db, "postgresql_collation resource")
+	}
+
+	// The version is only changed by the diff when a refresh is planned
+	if d.Get(collRefreshVersionAttr).(bool) && d.HasChange(collVersionAttr) {
+		database, collSchema, collName, err := getDBCollationName(d, db.client.databaseName)
+		if err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf("ALTER COLLATION %s.%s REFRESH VERSION", pq.QuoteIdentifier(collSchema), pq.QuoteIdentifier(collName))
+		if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+			if _, err := txn.Exec(query); err != nil {
+				return fmt.Errorf("Error refreshing version of collation %s: %w", collName, err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return resourcePostgreSQLCollationReadImpl(db, d)
+}
+
+func resourcePostgreSQLCollationDelete(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureCollation) {
+		return unsupportedVersionError(db, "postgresql_collation resource")
+	}
+
+	database, collSchema, collName, err := getDBCollationName(d, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DROP COLLATION IF EXISTS %s.%s", pq.QuoteIdentifier(collSchema), pq.QuoteIdentifier(collName))
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("Error deleting collation %s: %w", collName, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// suppressUnknownCollationSource ignores the from and locale of an imported collation,
+// they are unknown as they cannot be reliably read back from pg_collation across versions.
+func suppressUnknownCollationSource(k, old, new string, d *schema.ResourceData) bool {
+	return old == "" && d.Id() != ""
+}
+
+// createCollationQuery returns the statement creating the collation,
+// either as a copy of an existing collation or from a locale.
+func createCollationQuery(collSchema, collName, from, locale, provider string) (string, error) {
+	name := pq.QuoteIdentifier(collSchema) + "." + pq.QuoteIdentifier(collName)
+
+	if from != "" {
+		return fmt.Sprintf("CREATE COLLATION %s FROM %s", name, pq.QuoteIdentifier(from)), nil
+	}
+	if locale == "" {
+		return "", fmt.Errorf("one of %s or %s is required to create collation %s", collFromAttr, collLocaleAttr, collName)
+	}
+
+	options := []string{fmt.Sprintf("LOCALE = %s", pq.QuoteLiteral(locale))}
+	if provider != "" {
+		options = append([]string{fmt.Sprintf("PROVIDER = %s", provider)}, options...)
+	}
+	return fmt.Sprintf("CREATE COLLATION %s (%s)", name, strings.Join(options, ", ")), nil
+}
+
+// collationProviderName returns the name of the provider from its code in pg_collation.collprovider.
+func collationProviderName(code string) string {
+	switch code {
+	case "i":
+		return "icu"
+	case "c":
+		return "libc"
+	}
+	// d: the database default, only used by the "default" collation
+	return ""
+}
+
+func generateCollationID(database, collSchema, collName string) string {
+	return strings.Join([]string{database, collSchema, collName}, ".")
+}
+
+// getDBCollationName returns the database, schema and name of the collation.
+// When importing, they are parsed from the resource ID.
+// The name is the last part as collation names often contain dots (e.g.: en_US.utf8).
+func getDBCollationName(d *schema.ResourceData, databaseName string) (string, string, string, error) {
+	database := getDatabase(d, databaseName)
+	collSchema := d.Get(collSchemaAttr).(string)
+	collName := d.Get(collNameAttr).(string)
+
+	if collName == "" {
+		parsed := strings.SplitN(d.Id(), ".", 3)
+		if len(parsed) != 3 {
+			return "", "", "", fmt.Errorf("collation ID %s has not the expected format 'database.schema.collation': %v", d.Id(), parsed)
+		}
+		database = parsed[0]
+		collSchema = parsed[1]
+		collName = parsed[2]
+	}
+	return database, collSchema, collName, nil
+}
@@ -0,0 +1,170 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestCreateCollationQuery(t *testing.T) {
+	var tests = []struct {
+		name     string
+		from     string
+		locale   string
+		provider string
+		want     string
+		wantErr  bool
+	}{
+		{"from", "en_US.utf8", "", "", `CREATE COLLATION "public"."my coll" FROM "en_US.utf8"`, false},
+		{"locale", "", "fr_FR.utf8", "", `CREATE COLLATION "public"."my coll" (LOCALE = 'fr_FR.utf8')`, false},
+		{"icu", "", "de-DE", "icu", `CREATE COLLATION "public"."my coll" (PROVIDER = icu, LOCALE = 'de-DE')`, false},
+		{"missing locale", "", "", "libc", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := createCollationQuery("public", "my coll", test.from, test.locale, test.provider)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("createCollationQuery() error = %v, want error %t", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("createCollationQuery() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCollationRefreshVersion(t *testing.T) {
+	var tests = []struct {
+		name          string
+		refresh       bool
+		version       string
+		actualVersion string
+		expectDiff    bool
+	}{
+		{"refresh disabled", false, "153.80", "153.112", false},
+		{"versions match", true, "153.112", "153.112", false},
+		{"versions mismatch", true, "153.80", "153.112", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &terraform.InstanceState{
+				ID: "mydb.public.mycoll",
+				Attributes: map[string]string{
+					"id":                   "mydb.public.mycoll",
+					collNameAttr:           "mycoll",
+					collSchemaAttr:         "public",
+					collDatabaseAttr:       "mydb",
+					collFromAttr:           "und-x-icu",
+					collProviderAttr:       "icu",
+					collVersionAttr:        test.version,
+					collActualVersionAttr:  test.actualVersion,
+					collRefreshVersionAttr: fmt.Sprintf("%t", test.refresh),
+				},
+			}
+			config := map[string]interface{}{
+				collNameAttr:           "mycoll",
+				collDatabaseAttr:       "mydb",
+				collFromAttr:           "und-x-icu",
+				collRefreshVersionAttr: test.refresh,
+			}
+
+			diff, err := resourcePostgreSQLCollation().Diff(state, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("could not compute diff: %v", err)
+			}
+			var attrDiff *terraform.ResourceAttrDiff
+			if diff != nil {
+				attrDiff = diff.Attributes[collVersionAttr]
+			}
+			if hasDiff := attrDiff != nil; hasDiff != test.expectDiff {
+				t.Fatalf("version diff = %t, want %t", hasDiff, test.expectDiff)
+			}
+			if diff != nil && diff.RequiresNew() {
+				t.Errorf("the collation must not be recreated")
+			}
+		})
+	}
+}
+
+func TestAccPostgresqlCollation_Basic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureCollation)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlCollationDestroy(t, dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_collation" "posix" {
+	database = "%[1]s"
+	schema   = "test_schema"
+	name     = "my_posix"
+	from     = "POSIX"
+}
+
+resource "postgresql_collation" "c" {
+	database        = "%[1]s"
+	name            = "my_c"
+	locale          = "C"
+	locale_provider = "libc"
+	refresh_version = true
+}
+`, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_collation.posix", "id", dbName+".test_schema.my_posix"),
+					resource.TestCheckResourceAttr("postgresql_collation.posix", "locale_provider", "libc"),
+					// The C and POSIX locales have no version
+					resource.TestCheckResourceAttr("postgresql_collation.c", "version", ""),
+					resource.TestCheckResourceAttr("postgresql_collation.c", "actual_version", ""),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlCollationDestroy(t *testing.T, dbName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := getTestProvider(t).Meta().(*Client)
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "postgresql_collation" {
+				continue
+			}
+
+			db, err := client.forDatabase(dbName).Connect()
+			if err != nil {
+				return err
+			}
+
+			var found bool
+			err = db.QueryRow(
+				"SELECT true FROM pg_catalog.pg_collation c "+
+					"JOIN pg_catalog.pg_namespace n ON n.oid = c.collnamespace "+
+					"WHERE n.nspname = $1 AND c.collname = $2",
+				rs.Primary.Attributes[collSchemaAttr], rs.Primary.Attributes[collNameAttr],
+			).Scan(&found)
+			switch {
+			case err == sql.ErrNoRows:
+				continue
+			case err != nil:
+				return fmt.Errorf("could not check if collation exists: %w", err)
+			}
+			return fmt.Errorf("collation %s still exists after destroy", rs.Primary.ID)
+		}
+		return nil
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
@@ -22,21 +24,46 @@ const (
 	dbIsTemplateAttr = "is_template"
 	dbNameAttr       = "name"
 	dbOwnerAttr      = "owner"
+	dbStrategyAttr   = "strategy"
 	dbTablespaceAttr = "tablespace_name"
 	dbTemplateAttr   = "template"
+
+	dbTablespaceTerminateConnsAttr = "terminate_connections_on_tablespace_move"
+	dbMigrateDefaultPrivilegesAttr = "migrate_default_privileges"
+	dbGrantOwnerMembershipAttr     = "grant_owner_membership"
+
+	dbOIDAttr                     = "oid"
+	dbCollationVersionAttr        = "collation_version"
+	dbActualCollationVersionAttr  = "actual_collation_version"
+	dbRefreshCollationVersionAttr = "refresh_collation_version"
+
+	dbVerifyTemplateCopyAttr = "verify_template_copy"
 )
 
+// dbRecreateAttrs are the attributes which can only be set when the database is created (ForceNew),
+// PostgreSQL has no ALTER DATABASE for them so changing them requires to recreate the database.
+// All the other attributes are altered online by resourcePostgreSQLDatabaseUpdate.
+var dbRecreateAttrs = []string{
+	dbTemplateAttr,
+	dbEncodingAttr,
+	dbCollationAttr,
+	dbCTypeAttr,
+	dbStrategyAttr,
+}
+
 func resourcePostgreSQLDatabase() *schema.Resource {
 	return &schema.Resource{
-		Create: PGResourceFunc(resourcePostgreSQLDatabaseCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDatabaseRead),
-		Update: PGResourceFunc(resourcePostgreSQLDatabaseUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLDatabaseDelete),
+		Create: PGResourceFunc(onMaintenanceDatabase(resourcePostgreSQLDatabaseCreate)),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLDatabaseRead)),
+		Update: PGResourceFunc(onMaintenanceDatabase(resourcePostgreSQLDatabaseUpdate)),
+		Delete: removeIfNotFound(PGResourceFunc(onMaintenanceDatabase(resourcePostgreSQLDatabaseDelete))),
 		Exists: PGResourceExistsFunc(resourcePostgreSQLDatabaseExists),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourcePostgreSQLDatabaseCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			dbNameAttr: {
 				Type:        schema.TypeString,
@@ -56,6 +83,13 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Computed:    true,
 				Description: "The name of the template from which to create the new database",
 			},
+			dbStrategyAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"WAL_LOG", "FILE_COPY"}, true),
+				Description:  "The strategy used to copy the template (WAL_LOG or FILE_COPY), ignored before PostgreSQL 15",
+			},
 			dbEncodingAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -82,6 +116,31 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Optional:    true,
 				Computed:    true,
 				Description: "The name of the tablespace that will be associated with the new database",
+				// DEFAULT is the tablespace of the template, pg_default for the default templates
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return strings.ToUpper(new) == "DEFAULT" && old == "pg_default"
+				},
+			},
+			dbTablespaceTerminateConnsAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "If true, the connections to the database are terminated before moving it to another tablespace " +
+					"(which fails if the database is being accessed)",
+			},
+			dbMigrateDefaultPrivilegesAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "If true, the default privileges defined by the previous owner in the database are moved to the new owner " +
+					"when the owner changes (otherwise a warning is logged)",
+			},
+			dbGrantOwnerMembershipAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				Description: "When true, the connected user is temporarily granted the membership of the owner if needed, " +
+					"otherwise it must already be a member of it",
 			},
 			dbConnLimitAttr: {
 				Type:         schema.TypeInt,
@@ -108,10 +167,45 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Default:     false,
 				Description: "If true, it tries to create the database with IF NOT EXISTS",
 			},
+			dbOIDAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The OID of the database",
+			},
+			dbCollationVersionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The collation version recorded when the database was created or refreshed (PostgreSQL >= 15)",
+			},
+			dbActualCollationVersionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The collation version currently provided by the operating system (PostgreSQL >= 15)",
+			},
+			dbRefreshCollationVersionAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "If true, the recorded collation version is refreshed when it does not match the actual one " +
+					"(the indexes depending on the collation should be rebuilt first)",
+			},
+			dbVerifyTemplateCopyAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "If true, the number of relations of the new database is compared with the one of the template " +
+					"once created, failing the creation if the copy looks incomplete",
+			},
 		},
 	}
 }
 
+// resourcePostgreSQLDatabaseCustomizeDiff plans the refresh of the collation version
+// when it is enabled and the recorded version does not match the actual one.
+func resourcePostgreSQLDatabaseCustomizeDiff(d *schema.ResourceDiff, _ interface{}) error {
+	return planCollationVersionRefresh(d, dbRefreshCollationVersionAttr, dbCollationVersionAttr, dbActualCollationVersionAttr)
+}
+
 func resourcePostgreSQLDatabaseCreate(db *DBConnection, d *schema.ResourceData) error {
 	if err := createDatabase(db, d); err != nil {
 		return err
@@ -119,9 +213,106 @@ func resourcePostgreSQLDatabaseCreate(db *DBConnection, d *schema.ResourceData)
 
 	d.SetId(d.Get(dbNameAttr).(string))
 
+	// The ID is set first, so a database failing the check is tainted and recreated by the next apply
+	if d.Get(dbVerifyTemplateCopyAttr).(bool) {
+		if err := verifyTemplateCopy(db.client, d); err != nil {
+			return err
+		}
+	}
+
 	return resourcePostgreSQLDatabaseReadImpl(db, d)
 }
 
+// relationKinds names the kinds of relations (pg_class.relkind) in the errors of verifyTemplateCopy.
+var relationKinds = map[string]string{
+	"r": "tables",
+	"p": "partitioned tables",
+	"v": "views",
+	"m": "materialized views",
+	"S": "sequences",
+	"f": "foreign tables",
+	"i": "indexes",
+	"I": "partitioned indexes",
+	"c": "composite types",
+}
+
+// verifyTemplateCopy compares the number of relations of each kind, outside of the system schemas,
+// between the created database and its template.
+// There is nothing to compare with template0, which does not accept connections.
+func verifyTemplateCopy(client *Client, d *schema.ResourceData) error {
+	template := d.Get(dbTemplateAttr).(string)
+	switch {
+	case template == "" || template == "template0":
+		log.Printf("[DEBUG] Database created from template0, nothing to verify")
+		return nil
+	case strings.ToUpper(template) == "DEFAULT":
+		template = "template1"
+	}
+	name := d.Get(dbNameAttr).(string)
+
+	templateCounts, err := countDatabaseRelations(client, template)
+	if err != nil {
+		return err
+	}
+	counts, err := countDatabaseRelations(client, name)
+	if err != nil {
+		return err
+	}
+
+	var differences []string
+	for kind, description := range relationKinds {
+		if counts[kind] != templateCounts[kind] {
+			differences = append(differences, fmt.Sprintf("%d %s instead of %d", counts[kind], description, templateCounts[kind]))
+		}
+	}
+	if len(differences) > 0 {
+		sort.Strings(differences)
+		return fmt.Errorf(
+			"database %s looks like an incomplete copy of the template %s: %s",
+			name, template, strings.Join(differences, ", "),
+		)
+	}
+	return nil
+}
+
+// countDatabaseRelations returns the number of relations of each kind outside of the system schemas of the database.
+// The connection is not kept in the pool of the provider, as a template cannot be copied while it is accessed.
+func countDatabaseRelations(client *Client, database string) (map[string]int, error) {
+	conn, err := client.openDedicatedDB(database)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to database %s: %w", database, err)
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query(
+		"SELECT c.relkind::text, count(*) FROM pg_catalog.pg_class c " +
+			"JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace " +
+			"WHERE n.nspname NOT IN ('pg_catalog', 'information_schema') " +
+			"AND n.nspname NOT LIKE 'pg\\_toast%' AND n.nspname NOT LIKE 'pg\\_temp\\_%' " +
+			"GROUP BY c.relkind",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not count the relations of database %s: %w", database, err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var kind string
+		var count int
+		if err := rows.Scan(&kind, &count); err != nil {
+			return nil, fmt.Errorf("could not scan the relations of database %s: %w", database, err)
+		}
+		counts[kind] = count
+	}
+	return counts, rows.Err()
+}
+
+// databaseOwnerLock serializes the database operations holding the lock of the connected user,
+// which execute their statements on a second connection: the ones waiting for the lock
+// would otherwise hold all the connections of the pool (see connectionBudget).
+var databaseOwnerLock sync.Mutex
+
 func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 	currentUser := db.client.config.getDatabaseUsername()
 	owner := d.Get(dbOwnerAttr).(string)
@@ -130,6 +321,8 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 	if owner != "" {
 		// Take a lock on db currentUser to avoid multiple database creation at the same time
 		// It can fail if they grant the same owner to current at the same time as it's not done in transaction.
+		databaseOwnerLock.Lock()
+		defer databaseOwnerLock.Unlock()
 		lockTxn, err := startTransaction(db.client, "")
 		if err := pgLockRole(lockTxn, currentUser); err != nil {
 			return err
@@ -138,7 +331,7 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 
 		// Needed in order to set the owner of the db if the connection user is not a
 		// superuser
-		ownerGranted, err := grantRoleMembership(db, owner, currentUser)
+		ownerGranted, err := grantDBOwnerMembership(db, d, owner, currentUser)
 		if err != nil {
 			return err
 		}
@@ -219,6 +412,14 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 		fmt.Fprint(b, " TABLESPACE ", pq.QuoteIdentifier(v.(string)))
 	}
 
+	if v, ok := d.GetOk(dbStrategyAttr); ok {
+		if db.featureSupported(featureDBStrategy) {
+			fmt.Fprint(b, " STRATEGY ", strings.ToUpper(v.(string)))
+		} else {
+			log.Printf("[WARN] %s is only supported since PostgreSQL 15, it is ignored to create database %s", dbStrategyAttr, dbName)
+		}
+	}
+
 	if db.featureSupported(featureDBAllowConnections) {
 		val := d.Get(dbAllowConnsAttr).(bool)
 		fmt.Fprint(b, " ALLOW_CONNECTIONS ", val)
@@ -234,6 +435,7 @@ func createDatabase(db *DBConnection, d *schema.ResourceData) error {
 		fmt.Fprint(b, " IS_TEMPLATE ", val)
 	}
 
+	// CREATE DATABASE cannot be executed inside a transaction block (see nonTransactionalStatements).
 	sql := b.String()
 	if _, err := db.Exec(sql); err != nil {
 		return fmt.Errorf("Error creating database %q: %w", dbName, err)
@@ -251,6 +453,8 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 	var dropWithForce string
 	var err error
 	if owner != "" {
+		databaseOwnerLock.Lock()
+		defer databaseOwnerLock.Unlock()
 		lockTxn, err := startTransaction(db.client, "")
 		if err := pgLockRole(lockTxn, currentUser); err != nil {
 			return err
@@ -259,7 +463,7 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 
 		// Needed in order to set the owner of the db if the connection user is not a
 		// superuser
-		ownerGranted, err := grantRoleMembership(db, owner, currentUser)
+		ownerGranted, err := grantDBOwnerMembership(db, d, owner, currentUser)
 		if err != nil {
 			return err
 		}
@@ -275,13 +479,13 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 		if isTemplate := d.Get(dbIsTemplateAttr).(bool); isTemplate {
 			// Template databases must have this attribute cleared before
 			// they can be dropped.
-			if err := doSetDBIsTemplate(db, dbName, false); err != nil {
+			if err := doSetDBIsTemplate(db, db, dbName, false); err != nil {
 				return fmt.Errorf("Error updating database IS_TEMPLATE during DROP DATABASE: %w", err)
 			}
 		}
 	}
 
-	if err := setDBIsTemplate(db, d); err != nil {
+	if err := setDBIsTemplate(db, db, d); err != nil {
 		return err
 	}
 
@@ -295,6 +499,7 @@ func resourcePostgreSQLDatabaseDelete(db *DBConnection, d *schema.ResourceData)
 		dropWithForce = "WITH ( FORCE )"
 	}
 
+	// DROP DATABASE cannot be executed inside a transaction block (see nonTransactionalStatements).
 	sql := fmt.Sprintf("DROP DATABASE %s %s", pq.QuoteIdentifier(dbName), dropWithForce)
 	if _, err := db.Exec(sql); err != nil {
 		return fmt.Errorf("Error dropping database: %w", err)
@@ -334,7 +539,7 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 	}
 
 	var dbEncoding, dbCollation, dbCType, dbTablespaceName string
-	var dbConnLimit int
+	var dbConnLimit, dbOID int
 
 	columns := []string{
 		"pg_catalog.pg_encoding_to_char(d.encoding)",
@@ -342,6 +547,7 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 		"d.datctype",
 		"ts.spcname",
 		"d.datconnlimit",
+		"d.oid",
 	}
 
 	dbSQLFmt := `SELECT %s ` +
@@ -355,6 +561,7 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 			&dbCType,
 			&dbTablespaceName,
 			&dbConnLimit,
+			&dbOID,
 		)
 	switch {
 	case err == sql.ErrNoRows:
@@ -372,6 +579,7 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 	_ = d.Set(dbCTypeAttr, dbCType)
 	_ = d.Set(dbTablespaceAttr, dbTablespaceName)
 	_ = d.Set(dbConnLimitAttr, dbConnLimit)
+	_ = d.Set(dbOIDAttr, dbOID)
 	dbTemplate := d.Get(dbTemplateAttr).(string)
 	if dbTemplate == "" {
 		dbTemplate = "template0"
@@ -400,31 +608,77 @@ func resourcePostgreSQLDatabaseReadImpl(db *DBConnection, d *schema.ResourceData
 		_ = d.Set(dbIsTemplateAttr, dbIsTemplate)
 	}
 
+	if db.featureSupported(featureDBCollationVersion) {
+		// Both are NULL for the C and POSIX collations, which have no version
+		var collationVersion, actualCollationVersion sql.NullString
+		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datcollversion, pg_catalog.pg_database_collation_actual_version(d.oid)")
+		err = db.QueryRow(dbSQL, dbId).Scan(&collationVersion, &actualCollationVersion)
+		if err != nil {
+			return fmt.Errorf("Error reading collation version of DATABASE: %w", err)
+		}
+
+		if collationVersion.String != actualCollationVersion.String {
+			log.Printf(
+				"[WARN] database %s was created with collation version %s, but the operating system provides version %s: "+
+					"rebuild the objects depending on the collation and run ALTER DATABASE %s REFRESH COLLATION VERSION "+
+					"(or set %s)",
+				dbName, collationVersion.String, actualCollationVersion.String, pq.QuoteIdentifier(dbName), dbRefreshCollationVersionAttr,
+			)
+		}
+
+		_ = d.Set(dbCollationVersionAttr, collationVersion.String)
+		_ = d.Set(dbActualCollationVersionAttr, actualCollationVersion.String)
+	}
+
 	return nil
 }
 
 func resourcePostgreSQLDatabaseUpdate(db *DBConnection, d *schema.ResourceData) error {
-	if err := setDBName(db, d); err != nil {
-		return err
+	// Should not happen as these attributes are ForceNew,
+	// but never apply a partial update if they are changed.
+	for _, attr := range dbRecreateAttrs {
+		if d.HasChange(attr) {
+			return fmt.Errorf("Error updating database: %s cannot be altered, the database must be recreated", attr)
+		}
 	}
 
-	if err := setDBOwner(db, d); err != nil {
-		return err
-	}
+	// The changes are applied in a transaction so a failure does not leave the database half updated.
+	if err := withTransaction(db.client, "", func(txn *sql.Tx) error {
+		if err := setDBName(txn, d); err != nil {
+			return err
+		}
 
-	if err := setDBTablespace(db, d); err != nil {
-		return err
-	}
+		if err := setDBOwner(txn, d); err != nil {
+			return err
+		}
 
-	if err := setDBConnLimit(db, d); err != nil {
+		if err := setDBConnLimit(txn, d); err != nil {
+			return err
+		}
+
+		if err := setDBAllowConns(db, txn, d); err != nil {
+			return err
+		}
+
+		if err := setDBIsTemplate(db, txn, d); err != nil {
+			return err
+		}
+
+		return refreshDBCollationVersion(db, txn, d)
+	}); err != nil {
 		return err
 	}
+	d.SetId(d.Get(dbNameAttr).(string))
 
-	if err := setDBAllowConns(db, d); err != nil {
+	// ALTER DATABASE ... SET TABLESPACE cannot be executed inside a transaction block
+	// (see nonTransactionalStatements), so it is applied once the other changes are committed.
+	if err := setDBTablespace(db, d); err != nil {
 		return err
 	}
 
-	if err := setDBIsTemplate(db, d); err != nil {
+	// The default privileges are stored in the database itself, so they are migrated
+	// with a connection to it once the new owner is committed.
+	if err := migrateDBOwnerDefaultPrivileges(db, d); err != nil {
 		return err
 	}
 
@@ -449,12 +703,11 @@ func setDBName(db QueryAble, d *schema.ResourceData) error {
 	if _, err := db.Exec(sql); err != nil {
 		return fmt.Errorf("Error updating database name: %w", err)
 	}
-	d.SetId(n)
 
 	return nil
 }
 
-func setDBOwner(db *DBConnection, d *schema.ResourceData) error {
+func setDBOwner(txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(dbOwnerAttr) {
 		return nil
 	}
@@ -463,49 +716,100 @@ func setDBOwner(db *DBConnection, d *schema.ResourceData) error {
 	if owner == "" {
 		return nil
 	}
-	currentUser := db.client.config.getDatabaseUsername()
 
-	lockTxn, err := startTransaction(db.client, "")
-	if err := pgLockRole(lockTxn, currentUser); err != nil {
+	currentUser, err := getCurrentUser(txn)
+	if err != nil {
 		return err
 	}
-	defer deferredRollback(lockTxn)
-
-	//needed in order to set the owner of the db if the connection user is not a superuser
-	ownerGranted, err := grantRoleMembership(db, owner, currentUser)
-	if err != nil {
+	if err := pgLockRole(txn, currentUser); err != nil {
 		return err
 	}
-	if ownerGranted {
-		defer func() {
-			_, err = revokeRoleMembership(db, owner, currentUser)
-		}()
+
+	// Needed in order to set the owner of the db if the connection user is not a superuser
+	dbName := d.Get(dbNameAttr).(string)
+	if !d.Get(dbGrantOwnerMembershipAttr).(bool) {
+		if err := checkRoleMembership(txn, owner, dbOwnerMembershipOperation(dbName)); err != nil {
+			return err
+		}
+		return setObjectOwner(txn, "DATABASE", pq.QuoteIdentifier(dbName), owner)
+	}
+	return withRolesGranted(txn, []string{owner}, func() error {
+		return setObjectOwner(txn, "DATABASE", pq.QuoteIdentifier(dbName), owner)
+	})
+}
+
+// grantDBOwnerMembership grants the membership of the owner to the connected user if needed
+// and allowed by grant_owner_membership, otherwise it checks the connected user is already a member of it.
+// It returns true if the membership has been granted (and must be revoked afterwards).
+func grantDBOwnerMembership(db *DBConnection, d *schema.ResourceData, owner, currentUser string) (bool, error) {
+	if d.Get(dbGrantOwnerMembershipAttr).(bool) {
+		return grantRoleMembership(db, owner, currentUser)
+	}
+	return false, checkRoleMembership(db, owner, dbOwnerMembershipOperation(d.Get(dbNameAttr).(string)))
+}
+
+func dbOwnerMembershipOperation(dbName string) string {
+	return fmt.Sprintf("manage the database %s it owns (or set %s to true)", dbName, dbGrantOwnerMembershipAttr)
+}
+
+func migrateDBOwnerDefaultPrivileges(db *DBConnection, d *schema.ResourceData) error {
+	if !d.HasChange(dbOwnerAttr) {
+		return nil
+	}
+
+	oldOwner, newOwner := d.GetChange(dbOwnerAttr)
+	if oldOwner.(string) == "" || newOwner.(string) == "" {
+		return nil
 	}
 
 	dbName := d.Get(dbNameAttr).(string)
-	sql := fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(owner))
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database OWNER: %w", err)
+	if db.featureSupported(featureDBAllowConnections) && !d.Get(dbAllowConnsAttr).(bool) {
+		log.Printf("[WARN] connections to database %s are not allowed, the default privileges of its previous owner %s are not checked", dbName, oldOwner)
+		return nil
 	}
 
-	return err
+	return migrateOwnerDefaultPrivileges(
+		db.client, dbName, oldOwner.(string), newOwner.(string), d.Get(dbMigrateDefaultPrivilegesAttr).(bool),
+	)
 }
 
-func setDBTablespace(db QueryAble, d *schema.ResourceData) error {
+func setDBTablespace(db *DBConnection, d *schema.ResourceData) error {
 	if !d.HasChange(dbTablespaceAttr) {
 		return nil
 	}
 
 	tbspName := d.Get(dbTablespaceAttr).(string)
 	dbName := d.Get(dbNameAttr).(string)
-	var sql string
 	if tbspName == "" || strings.ToUpper(tbspName) == "DEFAULT" {
-		sql = fmt.Sprintf("ALTER DATABASE %s RESET TABLESPACE", pq.QuoteIdentifier(dbName))
-	} else {
-		sql = fmt.Sprintf("ALTER DATABASE %s SET TABLESPACE %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(tbspName))
+		tbspName = "pg_default"
+	}
+
+	if d.Get(dbTablespaceTerminateConnsAttr).(bool) {
+		// Block the new connections while the database is moved
+		if db.featureSupported(featureDBAllowConnections) && d.Get(dbAllowConnsAttr).(bool) {
+			if err := doSetDBAllowConns(db, dbName, false); err != nil {
+				return err
+			}
+			defer func() {
+				if err := doSetDBAllowConns(db, dbName, true); err != nil {
+					log.Printf("[ERROR] could not allow connections to database %s again: %v", dbName, err)
+				}
+			}()
+		}
+		if err := terminateDBConnections(db, dbName); err != nil {
+			return err
+		}
 	}
 
+	sql := fmt.Sprintf("ALTER DATABASE %s SET TABLESPACE %s", pq.QuoteIdentifier(dbName), pq.QuoteIdentifier(tbspName))
 	if _, err := db.Exec(sql); err != nil {
+		if isObjectInUse(err) {
+			return fmt.Errorf(
+				"could not move database %s to tablespace %s, it is being accessed by other users: "+
+					"close their connections or set %s to true to terminate them: %w",
+				dbName, tbspName, dbTablespaceTerminateConnsAttr, err,
+			)
+		}
 		return fmt.Errorf("Error updating database TABLESPACE: %w", err)
 	}
 
@@ -527,66 +831,96 @@ func setDBConnLimit(db QueryAble, d *schema.ResourceData) error {
 	return nil
 }
 
-func setDBAllowConns(db *DBConnection, d *schema.ResourceData) error {
+func setDBAllowConns(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(dbAllowConnsAttr) {
 		return nil
 	}
 
 	if !db.featureSupported(featureDBAllowConnections) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database ALLOW_CONNECTIONS", db.version.String())
-	}
-
-	allowConns := d.Get(dbAllowConnsAttr).(bool)
-	dbName := d.Get(dbNameAttr).(string)
-	sql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS %t", pq.QuoteIdentifier(dbName), allowConns)
-	if _, err := db.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating database ALLOW_CONNECTIONS: %w", err)
+		return withErrorKind(
+			fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database ALLOW_CONNECTIONS", db.version.String()),
+			ErrVersionUnsupported,
+		)
 	}
 
-	return nil
+	return doSetDBAllowConns(txn, d.Get(dbNameAttr).(string), d.Get(dbAllowConnsAttr).(bool))
 }
 
-func setDBIsTemplate(db *DBConnection, d *schema.ResourceData) error {
+func setDBIsTemplate(db *DBConnection, q QueryAble, d *schema.ResourceData) error {
 	if !d.HasChange(dbIsTemplateAttr) {
 		return nil
 	}
 
-	if err := doSetDBIsTemplate(db, d.Get(dbNameAttr).(string), d.Get(dbIsTemplateAttr).(bool)); err != nil {
+	if err := doSetDBIsTemplate(db, q, d.Get(dbNameAttr).(string), d.Get(dbIsTemplateAttr).(bool)); err != nil {
 		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
 	}
 
 	return nil
 }
 
-func doSetDBIsTemplate(db *DBConnection, dbName string, isTemplate bool) error {
+func doSetDBIsTemplate(db *DBConnection, q QueryAble, dbName string, isTemplate bool) error {
 	if !db.featureSupported(featureDBIsTemplate) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database IS_TEMPLATE", db.version.String())
+		return withErrorKind(
+			fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database IS_TEMPLATE", db.version.String()),
+			ErrVersionUnsupported,
+		)
 	}
 
 	sql := fmt.Sprintf("ALTER DATABASE %s IS_TEMPLATE %t", pq.QuoteIdentifier(dbName), isTemplate)
-	if _, err := db.Exec(sql); err != nil {
+	if _, err := q.Exec(sql); err != nil {
 		return fmt.Errorf("Error updating database IS_TEMPLATE: %w", err)
 	}
 
 	return nil
 }
 
-func terminateBConnections(db *DBConnection, dbName string) error {
-	var terminateSql string
+// refreshDBCollationVersion records the collation version provided by the operating system,
+// if the refresh is enabled and the diff planned it.
+func refreshDBCollationVersion(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.Get(dbRefreshCollationVersionAttr).(bool) || !d.HasChange(dbCollationVersionAttr) {
+		return nil
+	}
+	if !db.featureSupported(featureDBCollationVersion) {
+		return withErrorKind(
+			fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database collation versions", db.version.String()),
+			ErrVersionUnsupported,
+		)
+	}
 
-	if db.featureSupported(featureDBAllowConnections) {
-		alterSql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS false", pq.QuoteIdentifier(dbName))
+	dbName := d.Get(dbNameAttr).(string)
+	sql := fmt.Sprintf("ALTER DATABASE %s REFRESH COLLATION VERSION", pq.QuoteIdentifier(dbName))
+	if _, err := txn.Exec(sql); err != nil {
+		return fmt.Errorf("Error refreshing collation version of database %s: %w", dbName, err)
+	}
 
-		if _, err := db.Exec(alterSql); err != nil {
+	return nil
+}
+
+func terminateBConnections(db *DBConnection, dbName string) error {
+	if db.featureSupported(featureDBAllowConnections) {
+		if err := doSetDBAllowConns(db, dbName, false); err != nil {
 			return fmt.Errorf("Error blocking connections to database: %w", err)
 		}
 	}
+	return terminateDBConnections(db, dbName)
+}
+
+func doSetDBAllowConns(db QueryAble, dbName string, allowConns bool) error {
+	sql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS %t", pq.QuoteIdentifier(dbName), allowConns)
+	if _, err := db.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating database ALLOW_CONNECTIONS: %w", err)
+	}
+	return nil
+}
+
+// terminateDBConnections terminates the connections to the database, except the current one.
+func terminateDBConnections(db *DBConnection, dbName string) error {
 	pid := "procpid"
 	if db.featureSupported(featurePid) {
 		pid = "pid"
 	}
-	terminateSql = fmt.Sprintf("SELECT pg_terminate_backend(%s) FROM pg_stat_activity WHERE datname = '%s' AND %s <> pg_backend_pid()", pid, dbName, pid)
-	if _, err := db.Exec(terminateSql); err != nil {
+	terminateSql := fmt.Sprintf("SELECT pg_terminate_backend(%s) FROM pg_stat_activity WHERE datname = $1 AND %s <> pg_backend_pid()", pid, pid)
+	if _, err := db.Exec(terminateSql, dbName); err != nil {
 		return fmt.Errorf("Error terminating database connections: %w", err)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"testing"
 
@@ -43,6 +44,8 @@ func TestAccPostgresqlDatabase_Basic(t *testing.T) {
 						"postgresql_database.default_opts", "connection_limit", "-1"),
 					resource.TestCheckResourceAttr(
 						"postgresql_database.default_opts", "is_template", "false"),
+					resource.TestCheckResourceAttrSet(
+						"postgresql_database.default_opts", "oid"),
 
 					resource.TestCheckResourceAttr(
 						"postgresql_database.modified_opts", "owner", "myrole"),
@@ -187,6 +190,62 @@ resource postgresql_database test_db {
 	})
 }
 
+// Test that a failed update does not leave the database half updated.
+func TestAccPostgresqlDatabase_UpdateRollback(t *testing.T) {
+	var config = `
+resource postgresql_database test_db {
+	name             = "%s"
+	owner            = "%s"
+	connection_limit = %d
+}
+`
+
+	checkDatabase := func(name string, exists bool) {
+		client := getTestProvider(t).Meta().(*Client)
+		found, err := checkDatabaseExists(client, name)
+		if err != nil {
+			t.Fatalf("could not check if database %s exists: %v", name, err)
+		}
+		if found != exists {
+			t.Fatalf("database %s exists: %t, want %t", name, found, exists)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, "test_db", "postgres", -1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists(t, "postgresql_database.test_db"),
+				),
+			},
+			{
+				// The rename is executed before the owner change which fails.
+				Config:      fmt.Sprintf(config, "test_db_renamed", "doesnotexist", 2),
+				ExpectError: regexp.MustCompile("doesnotexist"),
+			},
+			{
+				PreConfig: func() {
+					checkDatabase("test_db", true)
+					checkDatabase("test_db_renamed", false)
+				},
+				Config: fmt.Sprintf(config, "test_db", "postgres", -1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists(t, "postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "name", "test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "connection_limit", "-1"),
+				),
+			},
+		},
+	})
+}
+
 // Test the case where we need to grant the owner to the connected user.
 // The owner should be revoked
 func TestAccPostgresqlDatabase_GrantOwner(t *testing.T) {
@@ -266,6 +325,188 @@ resource postgresql_database "test_db" {
 	})
 }
 
+func TestAccPostgresqlDatabase_NoOwnerMembershipGrant(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+
+	var stateConfig = `
+resource postgresql_role "test_owner" {
+       name = "test_owner"
+}
+resource postgresql_database "test_db" {
+       name                   = "test_db"
+       owner                  = "${postgresql_role.test_owner.name}"
+       grant_owner_membership = false
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			// Superusers are members of all roles.
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: stateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists(t, "postgresql_database.test_db"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "owner", "test_owner"),
+					resource.TestCheckResourceAttr("postgresql_database.test_db", "grant_owner_membership", "false"),
+
+					// the membership has never been granted.
+					checkUserMembership(t, dsn, config.Username, "test_owner", false),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Strategy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureDBStrategy)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_database" "file_copy_db" {
+  name     = "file_copy_db"
+  template = "template1"
+  strategy = "FILE_COPY"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists(t, "postgresql_database.file_copy_db"),
+					resource.TestCheckResourceAttr("postgresql_database.file_copy_db", "strategy", "FILE_COPY"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_VerifyTemplateCopy(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+	templateName, _ := getTestDBNames(dbSuffix)
+
+	// dbExecute closes its connections, the template must not be accessed while it is copied
+	config := getTestConfig(t)
+	templateDsn, _ := config.connStr(templateName)
+	dbExecute(t, templateDsn, "CREATE TABLE test_schema.test_table (id serial PRIMARY KEY)")
+	dbExecute(t, templateDsn, "CREATE VIEW test_schema.test_view AS SELECT id FROM test_schema.test_table")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_database" "verified_copy" {
+  name                 = "verified_copy_db"
+  template             = "%s"
+  verify_template_copy = true
+}
+`, templateName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists(t, "postgresql_database.verified_copy"),
+					resource.TestCheckResourceAttr("postgresql_database.verified_copy", "verify_template_copy", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_OwnerChangeDefaultPrivileges(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := func(owner string) string {
+		return fmt.Sprintf(`
+resource "postgresql_role" "old_owner" {
+  name = "test_dacl_old_owner"
+}
+
+resource "postgresql_role" "new_owner" {
+  name = "test_dacl_new_owner"
+}
+
+resource "postgresql_role" "reader" {
+  name = "test_dacl_reader"
+}
+
+resource "postgresql_database" "dacl_db" {
+  name                       = "test_dacl_db"
+  owner                      = postgresql_role.%s.name
+  migrate_default_privileges = true
+}
+`, owner)
+	}
+
+	countDefaultACLs := func(owner string, expected int) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			testConfig := getTestConfig(t)
+			dsn, _ := testConfig.connStr("test_dacl_db")
+			db, err := sql.Open("postgres", dsn)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			var count int
+			if err := db.QueryRow(
+				"SELECT count(*) FROM pg_default_acl WHERE pg_get_userbyid(defaclrole) = $1", owner,
+			).Scan(&count); err != nil {
+				return err
+			}
+			if count != expected {
+				return fmt.Errorf("expected %d default privileges entries of %s, got %d", expected, owner, count)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config("old_owner"),
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						testConfig := getTestConfig(t)
+						dsn, _ := testConfig.connStr("test_dacl_db")
+						dbExecute(t, dsn, "ALTER DEFAULT PRIVILEGES FOR ROLE test_dacl_old_owner GRANT SELECT ON TABLES TO test_dacl_reader")
+						dbExecute(t, dsn, "ALTER DEFAULT PRIVILEGES FOR ROLE test_dacl_old_owner IN SCHEMA public GRANT USAGE ON SEQUENCES TO test_dacl_reader")
+						return nil
+					},
+					countDefaultACLs("test_dacl_old_owner", 2),
+				),
+			},
+			{
+				Config: config("new_owner"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_database.dacl_db", "owner", "test_dacl_new_owner"),
+					countDefaultACLs("test_dacl_old_owner", 0),
+					countDefaultACLs("test_dacl_new_owner", 2),
+				),
+			},
+		},
+	})
+}
+
 func checkUserMembership(
 	t *testing.T, dsn, member, role string, shouldHaveRole bool,
 ) resource.TestCheckFunc {
@@ -449,3 +690,177 @@ resource "postgresql_database" "mydb_default_owner" {
 }
 
 `
+
+func TestDatabaseRecreateAttributes(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "mydb",
+		Attributes: map[string]string{
+			"id":                  "mydb",
+			dbNameAttr:            "mydb",
+			dbOwnerAttr:           "myrole",
+			dbTemplateAttr:        "template0",
+			dbEncodingAttr:        "UTF8",
+			dbCollationAttr:       "C",
+			dbCTypeAttr:           "C",
+			dbTablespaceAttr:      "pg_default",
+			dbConnLimitAttr:       "-1",
+			dbAllowConnsAttr:      "true",
+			dbIsTemplateAttr:      "false",
+			createIfNotExistsAttr: "false",
+		},
+	}
+	baseConfig := map[string]interface{}{
+		dbNameAttr:      "mydb",
+		dbOwnerAttr:     "myrole",
+		dbTemplateAttr:  "template0",
+		dbEncodingAttr:  "UTF8",
+		dbCollationAttr: "C",
+		dbCTypeAttr:     "C",
+	}
+
+	var tests = []struct {
+		attr        string
+		value       interface{}
+		requiresNew bool
+	}{
+		{dbEncodingAttr, "LATIN1", true},
+		{dbCollationAttr, "en_US.UTF-8", true},
+		{dbCTypeAttr, "en_US.UTF-8", true},
+		{dbTemplateAttr, "template1", true},
+		{dbStrategyAttr, "FILE_COPY", true},
+		{dbOwnerAttr, "otherrole", false},
+		{dbNameAttr, "otherdb", false},
+		{dbTablespaceAttr, "other_tablespace", false},
+		{dbConnLimitAttr, 10, false},
+		{dbAllowConnsAttr, false, false},
+		{dbIsTemplateAttr, true, false},
+	}
+
+	// The update refuses to alter the same attributes as the schema recreates the database for
+	for name, attr := range resourcePostgreSQLDatabase().Schema {
+		if attr.ForceNew != sliceContainsStr(dbRecreateAttrs, name) {
+			t.Errorf("%s: ForceNew = %t, but in dbRecreateAttrs = %t", name, attr.ForceNew, !attr.ForceNew)
+		}
+	}
+
+	for _, test := range tests {
+		t.Run(test.attr, func(t *testing.T) {
+			config := make(map[string]interface{}, len(baseConfig)+1)
+			for k, v := range baseConfig {
+				config[k] = v
+			}
+			config[test.attr] = test.value
+
+			diff, err := resourcePostgreSQLDatabase().Diff(state, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("could not compute diff: %v", err)
+			}
+			if diff == nil || diff.Attributes[test.attr] == nil {
+				t.Fatalf("expected a diff on %s", test.attr)
+			}
+			if diff.RequiresNew() != test.requiresNew {
+				t.Errorf("changing %s: requires new = %t, want %t", test.attr, diff.RequiresNew(), test.requiresNew)
+			}
+		})
+	}
+}
+
+func TestDatabaseTablespaceDefault(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "mydb",
+		Attributes: map[string]string{
+			"id":                           "mydb",
+			dbNameAttr:                     "mydb",
+			dbOwnerAttr:                    "myrole",
+			dbTablespaceAttr:               "pg_default",
+			dbConnLimitAttr:                "-1",
+			dbAllowConnsAttr:               "true",
+			createIfNotExistsAttr:          "false",
+			dbTablespaceTerminateConnsAttr: "false",
+		},
+	}
+
+	var tests = []struct {
+		tablespace string
+		expectDiff bool
+	}{
+		{"pg_default", false},
+		{"DEFAULT", false},
+		{"default", false},
+		{"other_tablespace", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.tablespace, func(t *testing.T) {
+			config := map[string]interface{}{
+				dbNameAttr:       "mydb",
+				dbOwnerAttr:      "myrole",
+				dbTablespaceAttr: test.tablespace,
+			}
+
+			diff, err := resourcePostgreSQLDatabase().Diff(state, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("could not compute diff: %v", err)
+			}
+			hasDiff := diff != nil && diff.Attributes[dbTablespaceAttr] != nil
+			if hasDiff != test.expectDiff {
+				t.Errorf("tablespace %s: diff = %t, want %t", test.tablespace, hasDiff, test.expectDiff)
+			}
+		})
+	}
+}
+
+func TestDatabaseRefreshCollationVersion(t *testing.T) {
+	var tests = []struct {
+		name          string
+		refresh       bool
+		version       string
+		actualVersion string
+		expectDiff    bool
+	}{
+		{"refresh disabled", false, "2.31", "2.36", false},
+		{"versions match", true, "2.36", "2.36", false},
+		{"no collation version", true, "", "", false},
+		{"versions mismatch", true, "2.31", "2.36", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &terraform.InstanceState{
+				ID: "mydb",
+				Attributes: map[string]string{
+					"id":                          "mydb",
+					dbNameAttr:                    "mydb",
+					dbOwnerAttr:                   "myrole",
+					dbTablespaceAttr:              "pg_default",
+					dbConnLimitAttr:               "-1",
+					dbAllowConnsAttr:              "true",
+					createIfNotExistsAttr:         "false",
+					dbCollationVersionAttr:        test.version,
+					dbActualCollationVersionAttr:  test.actualVersion,
+					dbRefreshCollationVersionAttr: fmt.Sprintf("%t", test.refresh),
+				},
+			}
+			config := map[string]interface{}{
+				dbNameAttr:                    "mydb",
+				dbOwnerAttr:                   "myrole",
+				dbRefreshCollationVersionAttr: test.refresh,
+			}
+
+			diff, err := resourcePostgreSQLDatabase().Diff(state, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("could not compute diff: %v", err)
+			}
+			var attrDiff *terraform.ResourceAttrDiff
+			if diff != nil {
+				attrDiff = diff.Attributes[dbCollationVersionAttr]
+			}
+			if hasDiff := attrDiff != nil; hasDiff != test.expectDiff {
+				t.Fatalf("collation version diff = %t, want %t", hasDiff, test.expectDiff)
+			}
+			if test.expectDiff && attrDiff.New != test.actualVersion {
+				t.Errorf("new collation version = %q, want %q", attrDiff.New, test.actualVersion)
+			}
+		})
+	}
+}
@@ -17,8 +17,8 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 	return &schema.Resource{
 		Create: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
 		Update: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead),
-		Delete: PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLDefaultPrivilegesRead)),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLDefaultPrivilegesDelete)),
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -29,7 +29,8 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 			},
 			"database": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 				Description: "The database to grant default privileges for this role",
 			},
@@ -62,8 +63,7 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 				Required:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Set:         schema.HashString,
-				MinItems:    1,
-				Description: "The list of privileges to apply as default privileges",
+				Description: "The list of privileges to apply as default privileges (empty to revoke the built-in ones, e.g.: EXECUTE on functions for PUBLIC)",
 			},
 			"with_grant_option": {
 				Type:        schema.TypeBool,
@@ -101,48 +101,39 @@ func resourcePostgreSQLDefaultPrivilegesCreate(db *DBConnection, d *schema.Resou
 		return fmt.Errorf("with_grant_option cannot be true for role 'public'")
 	}
 
-	if err := validatePrivileges(d); err != nil {
+	if err := validatePrivileges(db, d); err != nil {
 		return err
 	}
 
-	database := d.Get("database").(string)
+	database := getDatabase(d, db.client.databaseName)
+	d.Set("database", database)
 	owner := d.Get("owner").(string)
 
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
-
-	if err := pgLockRole(txn, owner); err != nil {
-		return err
-	}
-
-	// Needed in order to set the owner of the db if the connection user is not a superuser
-	if err := withRolesGranted(txn, []string{owner}, func() error {
-
-		// Revoke all privileges before granting otherwise reducing privileges will not work.
-		// We just have to revoke them in the same transaction so role will not lost his privileges
-		// between revoke and grant.
-		if err = revokeRoleDefaultPrivileges(txn, d); err != nil {
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := pgLockRole(txn, owner); err != nil {
 			return err
 		}
 
-		if err = grantRoleDefaultPrivileges(txn, d); err != nil {
-			return err
-		}
-		return nil
-	}); err != nil {
-		return err
-	}
+		// Needed in order to set the owner of the db if the connection user is not a superuser
+		return withRolesGranted(txn, []string{owner}, func() error {
 
-	if err := txn.Commit(); err != nil {
+			// Revoke all privileges before granting otherwise reducing privileges will not work.
+			// We just have to revoke them in the same transaction so role will not lost his privileges
+			// between revoke and grant.
+			if err := revokeRoleDefaultPrivileges(txn, d); err != nil {
+				return err
+			}
+
+			return grantRoleDefaultPrivileges(txn, d)
+		})
+	}); err != nil {
 		return err
 	}
+	invalidateDefaultPrivilegesObjects(db.client, d)
 
 	d.SetId(generateDefaultPrivilegesID(d))
 
-	txn, err = startTransaction(db.client, d.Get("database").(string))
+	txn, err := startTransaction(db.client, d.Get("database").(string))
 	if err != nil {
 		return err
 	}
@@ -154,30 +145,43 @@ func resourcePostgreSQLDefaultPrivilegesCreate(db *DBConnection, d *schema.Resou
 func resourcePostgreSQLDefaultPrivilegesDelete(db *DBConnection, d *schema.ResourceData) error {
 	owner := d.Get("owner").(string)
 
-	txn, err := startTransaction(db.client, d.Get("database").(string))
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	if err := withTransaction(db.client, d.Get("database").(string), func(txn *sql.Tx) error {
+		if err := pgLockRole(txn, owner); err != nil {
+			return err
+		}
 
-	if err := pgLockRole(txn, owner); err != nil {
-		return err
-	}
+		// The default privileges of a schema are dropped with it
+		// (e.g.: by a postgresql_schema destroyed first, with cleanup_default_privileges or not).
+		if pgSchema := d.Get("schema").(string); pgSchema != "" {
+			exists, err := schemaExists(txn, pgSchema)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				log.Printf("[DEBUG] schema %s does not exist anymore, nothing to revoke", pgSchema)
+				return nil
+			}
+		}
 
-	// Needed in order to set the owner of the db if the connection user is not a superuser
-	if err := withRolesGranted(txn, []string{owner}, func() error {
-		return revokeRoleDefaultPrivileges(txn, d)
+		// Needed in order to set the owner of the db if the connection user is not a superuser
+		return withRolesGranted(txn, []string{owner}, func() error {
+			return revokeRoleDefaultPrivileges(txn, d)
+		})
 	}); err != nil {
 		return err
 	}
-
-	if err := txn.Commit(); err != nil {
-		return err
-	}
+	invalidateDefaultPrivilegesObjects(db.client, d)
 
 	return nil
 }
 
+// invalidateDefaultPrivilegesObjects removes the cached privileges of the objects the default privileges apply to
+// (in all the schemas of the database if no schema is set): the objects created afterwards get them,
+// e.g. by the owner outside of Terraform, without invalidating the cache.
+func invalidateDefaultPrivilegesObjects(client *Client, d *schema.ResourceData) {
+	invalidateCachedObjectsPrivileges(client, d.Get("database").(string), d.Get("schema").(string), d.Get("object_type").(string))
+}
+
 func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	role := d.Get("role").(string)
 	owner := d.Get("owner").(string)
@@ -188,11 +192,82 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		return err
 	}
 
-	roleOID, err := getRoleOID(txn, role)
+	privileges, err := readDefaultPrivileges(txn, owner, pgSchema, objectType, role)
 	if err != nil {
 		return err
 	}
 
+	// We consider no privileges as "not exists", unless no privileges are expected
+	if len(privileges) == 0 && d.Get("privileges").(*schema.Set).Len() > 0 {
+		log.Printf("[DEBUG] no default privileges for role %s in schema %s", role, pgSchema)
+		d.SetId("")
+		return nil
+	}
+
+	privilegesSet := pgArrayToSet(privileges)
+	_ = d.Set("privileges", privilegesSet)
+	d.SetId(generateDefaultPrivilegesID(d))
+
+	return nil
+}
+
+func grantRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+	privileges := []string{}
+	for _, priv := range d.Get("privileges").(*schema.Set).List() {
+		privileges = append(privileges, priv.(string))
+	}
+	if len(privileges) == 0 {
+		// Only revoked (see revokeRoleDefaultPrivileges)
+		return nil
+	}
+
+	query := grantDefaultPrivilegesQuery(
+		d.Get("owner").(string),
+		d.Get("schema").(string),
+		d.Get("object_type").(string),
+		d.Get("role").(string),
+		privileges,
+		d.Get("with_grant_option").(bool),
+	)
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not alter default privileges: %w", err)
+	}
+
+	return nil
+}
+
+func revokeRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+	query := revokeDefaultPrivilegesQuery(
+		d.Get("owner").(string),
+		d.Get("schema").(string),
+		d.Get("object_type").(string),
+		d.Get("role").(string),
+	)
+	if _, err := txn.Exec(query); err != nil {
+		return fmt.Errorf("could not revoke default privileges: %w", err)
+	}
+	return nil
+}
+
+// builtinDefaultACLTypes maps the object types to their type in acldefault,
+// which returns the privileges of the objects created without any default privileges altered.
+var builtinDefaultACLTypes = map[string]string{
+	"table":    "r",
+	"sequence": "s",
+	"function": "f",
+	"type":     "T",
+}
+
+// readDefaultPrivileges returns the default privileges granted by owner to role
+// on the objects of this type created in pgSchema (or globally if pgSchema is empty).
+// Until the global default privileges of the owner are altered, the built-in ones apply
+// (e.g.: EXECUTE on functions and USAGE on types for PUBLIC), so they are read with acldefault.
+func readDefaultPrivileges(txn *sql.Tx, owner, pgSchema, objectType, role string) (pq.ByteaArray, error) {
+	roleOID, err := getRoleOID(txn, role)
+	if err != nil {
+		return nil, err
+	}
+
 	var query string
 	var queryArgs []interface{}
 
@@ -207,12 +282,14 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		queryArgs = []interface{}{roleOID, pgSchema, objectTypes[objectType], owner}
 	} else {
 		query = `SELECT array_agg(prtype) FROM (
-		SELECT defaclnamespace, (aclexplode(defaclacl)).* FROM pg_default_acl
-		WHERE defaclobjtype = $2
-	) AS t (namespace, grantor_oid, grantee_oid, prtype, grantable)
-	WHERE grantee_oid = $1 AND namespace = 0 AND pg_get_userbyid(grantor_oid) = $3;
+		SELECT (aclexplode(COALESCE(
+			(SELECT defaclacl FROM pg_default_acl WHERE defaclobjtype = $2 AND defaclnamespace = 0 AND defaclrole = pg_roles.oid),
+			acldefault($4::"char", pg_roles.oid)
+		))).* FROM pg_roles WHERE rolname = $3
+	) AS t (grantor_oid, grantee_oid, prtype, grantable)
+	WHERE grantee_oid = $1;
 `
-		queryArgs = []interface{}{roleOID, objectTypes[objectType], owner}
+		queryArgs = []interface{}{roleOID, objectTypes[objectType], owner, builtinDefaultACLTypes[objectType]}
 	}
 
 	// This query aggregates the list of default privileges type (prtype)
@@ -223,93 +300,414 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	if err := txn.QueryRow(
 		query, queryArgs...,
 	).Scan(&privileges); err != nil {
-		return fmt.Errorf("could not read default privileges: %w", err)
+		return nil, fmt.Errorf("could not read default privileges: %w", err)
 	}
+	return privileges, nil
+}
 
-	// We consider no privileges as "not exists"
-	if len(privileges) == 0 {
-		log.Printf("[DEBUG] no default privileges for role %s in schema %s", role, pgSchema)
-		d.SetId("")
-		return nil
+// inSchemaClause returns the IN SCHEMA clause of ALTER DEFAULT PRIVILEGES,
+// empty if the default privileges are not specific to a schema.
+func inSchemaClause(pgSchema string) string {
+	if pgSchema == "" {
+		return ""
 	}
+	return fmt.Sprintf("IN SCHEMA %s", pq.QuoteIdentifier(pgSchema))
+}
 
-	privilegesSet := pgArrayToSet(privileges)
-	_ = d.Set("privileges", privilegesSet)
-	d.SetId(generateDefaultPrivilegesID(d))
+func grantDefaultPrivilegesQuery(owner, pgSchema, objectType, role string, privileges []string, withGrantOption bool) string {
+	query := fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s %s GRANT %s ON %sS TO %s",
+		pq.QuoteIdentifier(owner),
+		inSchemaClause(pgSchema),
+		strings.Join(privileges, ","),
+		strings.ToUpper(objectType),
+		pq.QuoteIdentifier(role),
+	)
 
-	return nil
+	if withGrantOption {
+		query = query + " WITH GRANT OPTION"
+	}
+	return query
 }
 
-func grantRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
-	role := d.Get("role").(string)
-	pgSchema := d.Get("schema").(string)
+func revokeDefaultPrivilegesQuery(owner, pgSchema, objectType, role string) string {
+	return fmt.Sprintf(
+		"ALTER DEFAULT PRIVILEGES FOR ROLE %s %s REVOKE ALL ON %sS FROM %s",
+		pq.QuoteIdentifier(owner),
+		inSchemaClause(pgSchema),
+		strings.ToUpper(objectType),
+		pq.QuoteIdentifier(role),
+	)
+}
 
-	privileges := []string{}
-	for _, priv := range d.Get("privileges").(*schema.Set).List() {
-		privileges = append(privileges, priv.(string))
+func generateDefaultPrivilegesID(d *schema.ResourceData) string {
+	pgSchema := d.Get("schema").(string)
+	if pgSchema == "" {
+		pgSchema = "noschema"
 	}
 
-	var inSchema string
+	return strings.Join([]string{
+		d.Get("role").(string), d.Get("database").(string), pgSchema,
+		d.Get("owner").(string), d.Get("object_type").(string),
+	}, "_")
 
-	// If a schema is specified we need to build the part of the query string to action this
-	if pgSchema != "" {
-		inSchema = fmt.Sprintf("IN SCHEMA %s", pq.QuoteIdentifier(pgSchema))
+}
+
+// defaultACLEntry is a grantee of a pg_default_acl entry.
+type defaultACLEntry struct {
+	owner      string
+	schema     string
+	objectType string
+	grantee    string
+}
+
+// defaultACLObjectTypes maps pg_default_acl.defaclobjtype to the ALTER DEFAULT PRIVILEGES object type.
+var defaultACLObjectTypes = map[string]string{
+	"r": "TABLES",
+	"S": "SEQUENCES",
+	"f": "FUNCTIONS",
+	"T": "TYPES",
+	"n": "SCHEMAS",
+}
+
+// defaultACLPublicPrivileges are the privileges granted to PUBLIC by default
+// (i.e.: without any pg_default_acl entry) per object type.
+var defaultACLPublicPrivileges = map[string]string{
+	"f": "EXECUTE",
+	"T": "USAGE",
+}
+
+// cleanupRoleDefaultPrivileges revokes, in every database of the cluster,
+// the default privileges defined by the role or granted to it, so no stale
+// pg_default_acl entry prevents the role from being dropped.
+// The templates are skipped: they are not changed behind the back of their owner.
+func cleanupRoleDefaultPrivileges(db *DBConnection, role string) error {
+	rows, err := db.Query("SELECT datname FROM pg_catalog.pg_database WHERE datallowconn AND NOT datistemplate")
+	if err != nil {
+		return fmt.Errorf("could not list databases: %w", err)
 	}
+	defer rows.Close()
 
-	query := fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s %s GRANT %s ON %sS TO %s",
-		pq.QuoteIdentifier(d.Get("owner").(string)),
-		inSchema,
-		strings.Join(privileges, ","),
-		strings.ToUpper(d.Get("object_type").(string)),
-		pq.QuoteIdentifier(role),
-	)
+	var databases []string
+	for rows.Next() {
+		var database string
+		if err := rows.Scan(&database); err != nil {
+			return fmt.Errorf("could not scan database name: %w", err)
+		}
+		databases = append(databases, database)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not list databases: %w", err)
+	}
 
-	if d.Get("with_grant_option").(bool) {
-		query = query + " WITH GRANT OPTION"
+	for _, database := range databases {
+		if err := cleanupRoleDefaultPrivilegesInDB(db.client, database, role); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	_, err := txn.Exec(
-		query,
-	)
+func cleanupRoleDefaultPrivilegesInDB(client *Client, database, role string) error {
+	return withTransaction(client, database, func(txn *sql.Tx) error {
+		entries, err := readRoleDefaultACLEntries(txn, role)
+		if err != nil {
+			return fmt.Errorf("could not read default privileges of role %s in database %s: %w", role, database, err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		// Needed in order to alter the default privileges of the owners if the connection user is not a superuser
+		return withRolesGranted(txn, defaultACLEntriesOwners(entries), func() error {
+			for _, query := range cleanupDefaultACLQueries(role, entries) {
+				log.Printf("[DEBUG] cleaning up default privileges of role %s in database %s: %s", role, database, query)
+				if _, err := txn.Exec(query); err != nil {
+					return fmt.Errorf("could not cleanup default privileges of role %s in database %s: %w", role, database, err)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// readRoleDefaultACLEntries returns the pg_default_acl grantees of the current database
+// for which the role is either the owner or the grantee.
+func readRoleDefaultACLEntries(txn *sql.Tx, role string) ([]defaultACLEntry, error) {
+	query := "SELECT DISTINCT pg_get_userbyid(a.defaclrole), COALESCE(n.nspname, ''), a.defaclobjtype, " +
+		"CASE WHEN acl.grantee = 0 THEN 'public' ELSE pg_get_userbyid(acl.grantee) END " +
+		"FROM pg_catalog.pg_default_acl a " +
+		"LEFT JOIN pg_catalog.pg_namespace n ON n.oid = a.defaclnamespace, " +
+		"aclexplode(a.defaclacl) acl " +
+		"WHERE pg_get_userbyid(a.defaclrole) = $1 OR pg_get_userbyid(acl.grantee) = $1"
+
+	rows, err := txn.Query(query, role)
 	if err != nil {
-		return fmt.Errorf("could not alter default privileges: %w", err)
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
+	var entries []defaultACLEntry
+	for rows.Next() {
+		var entry defaultACLEntry
+		if err := rows.Scan(&entry.owner, &entry.schema, &entry.objectType, &entry.grantee); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
 }
 
-func revokeRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
-	pgSchema := d.Get("schema").(string)
+// cleanupDefaultACLQueries generates the ALTER DEFAULT PRIVILEGES queries
+// to remove the entries linked to the role.
+// Privileges of other owners are only revoked from the role itself,
+// while all the privileges defined by the role are revoked.
+// Global default privileges (i.e.: without schema) of the role are then reset
+// to the PostgreSQL defaults, so PostgreSQL removes the entries.
+func cleanupDefaultACLQueries(role string, entries []defaultACLEntry) []string {
+	var queries []string
+	var resets []string
+	seenResets := make(map[string]bool)
+
+	for _, entry := range entries {
+		if entry.owner != role && entry.grantee != role {
+			continue
+		}
+		query, ok := revokeDefaultACLEntryQuery(entry)
+		if !ok {
+			continue
+		}
+		queries = append(queries, query)
+		objectType := defaultACLObjectTypes[entry.objectType]
+
+		if entry.owner == role && entry.schema == "" && !seenResets[entry.objectType] {
+			seenResets[entry.objectType] = true
+			resets = append(resets, fmt.Sprintf(
+				"ALTER DEFAULT PRIVILEGES FOR ROLE %s GRANT ALL ON %s TO %s",
+				pq.QuoteIdentifier(role), objectType, pq.QuoteIdentifier(role),
+			))
+			if privilege, ok := defaultACLPublicPrivileges[entry.objectType]; ok {
+				resets = append(resets, fmt.Sprintf(
+					"ALTER DEFAULT PRIVILEGES FOR ROLE %s GRANT %s ON %s TO PUBLIC",
+					pq.QuoteIdentifier(role), privilege, objectType,
+				))
+			}
+		}
+	}
+
+	return append(queries, resets...)
+}
+
+// revokeDefaultACLEntryQuery returns the ALTER DEFAULT PRIVILEGES query revoking all the privileges
+// of the entry, false if its object type is unknown.
+func revokeDefaultACLEntryQuery(entry defaultACLEntry) (string, bool) {
+	objectType, ok := defaultACLObjectTypes[entry.objectType]
+	if !ok {
+		log.Printf("[WARN] unknown default privileges object type %q, skipping", entry.objectType)
+		return "", false
+	}
 
 	var inSchema string
+	if entry.schema != "" {
+		inSchema = fmt.Sprintf(" IN SCHEMA %s", pq.QuoteIdentifier(entry.schema))
+	}
 
-	// If a schema is specified we need to build the part of the query string to action this
-	if pgSchema != "" {
-		inSchema = fmt.Sprintf("IN SCHEMA %s", pq.QuoteIdentifier(pgSchema))
+	grantee := "PUBLIC"
+	if entry.grantee != publicRole {
+		grantee = pq.QuoteIdentifier(entry.grantee)
 	}
-	query := fmt.Sprintf(
-		"ALTER DEFAULT PRIVILEGES FOR ROLE %s %s REVOKE ALL ON %sS FROM %s",
-		pq.QuoteIdentifier(d.Get("owner").(string)),
-		inSchema,
-		strings.ToUpper(d.Get("object_type").(string)),
-		pq.QuoteIdentifier(d.Get("role").(string)),
-	)
 
-	if _, err := txn.Exec(query); err != nil {
-		return fmt.Errorf("could not revoke default privileges: %w", err)
+	return fmt.Sprintf(
+		"ALTER DEFAULT PRIVILEGES FOR ROLE %s%s REVOKE ALL ON %s FROM %s",
+		pq.QuoteIdentifier(entry.owner), inSchema, objectType, grantee,
+	), true
+}
+
+// defaultACLEntriesOwners returns the distinct owners of the entries, in order.
+func defaultACLEntriesOwners(entries []defaultACLEntry) []string {
+	var owners []string
+	seenOwners := make(map[string]bool)
+	for _, entry := range entries {
+		if !seenOwners[entry.owner] {
+			seenOwners[entry.owner] = true
+			owners = append(owners, entry.owner)
+		}
 	}
-	return nil
+	return owners
 }
 
-func generateDefaultPrivilegesID(d *schema.ResourceData) string {
-	pgSchema := d.Get("schema").(string)
-	if pgSchema == "" {
-		pgSchema = "noschema"
+// schemaDefaultACLCleanupQueries generates the ALTER DEFAULT PRIVILEGES queries
+// revoking all the entries of a schema, so PostgreSQL removes them.
+func schemaDefaultACLCleanupQueries(entries []defaultACLEntry) []string {
+	var queries []string
+	for _, entry := range entries {
+		if query, ok := revokeDefaultACLEntryQuery(entry); ok {
+			queries = append(queries, query)
+		}
 	}
+	return queries
+}
 
-	return strings.Join([]string{
-		d.Get("role").(string), d.Get("database").(string), pgSchema,
-		d.Get("owner").(string), d.Get("object_type").(string),
-	}, "_")
+// readSchemaDefaultACLEntries returns the pg_default_acl grantees of the current database
+// defined specifically for the schema (ALTER DEFAULT PRIVILEGES ... IN SCHEMA), whatever their owner.
+func readSchemaDefaultACLEntries(txn *sql.Tx, schemaName string) ([]defaultACLEntry, error) {
+	query := "SELECT DISTINCT pg_get_userbyid(a.defaclrole), n.nspname, a.defaclobjtype, " +
+		"CASE WHEN acl.grantee = 0 THEN 'public' ELSE pg_get_userbyid(acl.grantee) END " +
+		"FROM pg_catalog.pg_default_acl a " +
+		"JOIN pg_catalog.pg_namespace n ON n.oid = a.defaclnamespace, " +
+		"aclexplode(a.defaclacl) acl " +
+		"WHERE n.nspname = $1"
+
+	rows, err := txn.Query(query, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []defaultACLEntry
+	for rows.Next() {
+		var entry defaultACLEntry
+		if err := rows.Scan(&entry.owner, &entry.schema, &entry.objectType, &entry.grantee); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// defaultACLGrant is a privilege of a pg_default_acl entry.
+type defaultACLGrant struct {
+	schema      string
+	objectType  string
+	grantee     string
+	privilege   string
+	isGrantable bool
+}
+
+// migrateOwnerDefaultPrivileges moves the default privileges defined by oldOwner in the database to newOwner
+// (e.g.: when the owner of the database changes), as REASSIGN OWNED does not move them.
+// Only a warning is logged if migrate is false.
+func migrateOwnerDefaultPrivileges(client *Client, database, oldOwner, newOwner string, migrate bool) error {
+	return withTransaction(client, database, func(txn *sql.Tx) error {
+		grants, err := readOwnerDefaultACLGrants(txn, oldOwner)
+		if err != nil {
+			return fmt.Errorf("could not read default privileges of role %s in database %s: %w", oldOwner, database, err)
+		}
+		if len(grants) == 0 {
+			return nil
+		}
+
+		if !migrate {
+			log.Printf(
+				"[WARN] the default privileges defined by role %s in database %s (%d privileges) are not moved to the new owner %s, "+
+					"set migrate_default_privileges to move them",
+				oldOwner, database, len(grants), newOwner,
+			)
+			return nil
+		}
+
+		// Needed in order to alter the default privileges of the owners if the connection user is not a superuser
+		return withRolesGranted(txn, []string{oldOwner, newOwner}, func() error {
+			for _, query := range migrateDefaultACLQueries(oldOwner, newOwner, grants) {
+				log.Printf("[DEBUG] moving default privileges of role %s to %s in database %s: %s", oldOwner, newOwner, database, query)
+				if _, err := txn.Exec(query); err != nil {
+					return fmt.Errorf("could not move default privileges of role %s to %s in database %s: %w", oldOwner, newOwner, database, err)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// readOwnerDefaultACLGrants returns the privileges of the pg_default_acl entries of the current database
+// defined by the owner.
+func readOwnerDefaultACLGrants(txn *sql.Tx, owner string) ([]defaultACLGrant, error) {
+	query := "SELECT COALESCE(n.nspname, ''), a.defaclobjtype, " +
+		"CASE WHEN acl.grantee = 0 THEN 'public' ELSE pg_get_userbyid(acl.grantee) END, " +
+		"acl.privilege_type, acl.is_grantable " +
+		"FROM pg_catalog.pg_default_acl a " +
+		"LEFT JOIN pg_catalog.pg_namespace n ON n.oid = a.defaclnamespace, " +
+		"aclexplode(a.defaclacl) acl " +
+		"WHERE pg_get_userbyid(a.defaclrole) = $1 " +
+		"ORDER BY 1, 2, 3, 4"
+
+	rows, err := txn.Query(query, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []defaultACLGrant
+	for rows.Next() {
+		var grant defaultACLGrant
+		if err := rows.Scan(&grant.schema, &grant.objectType, &grant.grantee, &grant.privilege, &grant.isGrantable); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+// migrateDefaultACLQueries generates the ALTER DEFAULT PRIVILEGES queries
+// to define the default privileges of oldOwner for newOwner, then to remove them from oldOwner.
+// The privileges oldOwner grants to itself are granted by newOwner to itself.
+// Global default privileges (i.e.: without schema) are a complete ACL, so the ones of newOwner
+// for the same object types are replaced (its privileges and the PUBLIC ones are first revoked).
+func migrateDefaultACLQueries(oldOwner, newOwner string, grants []defaultACLGrant) []string {
+	var queries []string
+	var entries []defaultACLEntry
+	seenEntries := make(map[defaultACLEntry]bool)
+	seenGlobal := make(map[string]bool)
+
+	for _, grant := range grants {
+		objectType, ok := defaultACLObjectTypes[grant.objectType]
+		if !ok {
+			log.Printf("[WARN] unknown default privileges object type %q, skipping", grant.objectType)
+			continue
+		}
+
+		if grant.schema == "" && !seenGlobal[grant.objectType] {
+			seenGlobal[grant.objectType] = true
+			queries = append(queries,
+				fmt.Sprintf(
+					"ALTER DEFAULT PRIVILEGES FOR ROLE %s REVOKE ALL ON %s FROM PUBLIC",
+					pq.QuoteIdentifier(newOwner), objectType,
+				),
+				fmt.Sprintf(
+					"ALTER DEFAULT PRIVILEGES FOR ROLE %s REVOKE ALL ON %s FROM %s",
+					pq.QuoteIdentifier(newOwner), objectType, pq.QuoteIdentifier(newOwner),
+				),
+			)
+		}
+
+		var inSchema string
+		if grant.schema != "" {
+			inSchema = fmt.Sprintf(" IN SCHEMA %s", pq.QuoteIdentifier(grant.schema))
+		}
+
+		grantee := "PUBLIC"
+		switch grant.grantee {
+		case publicRole:
+		case oldOwner:
+			grantee = pq.QuoteIdentifier(newOwner)
+		default:
+			grantee = pq.QuoteIdentifier(grant.grantee)
+		}
+
+		query := fmt.Sprintf(
+			"ALTER DEFAULT PRIVILEGES FOR ROLE %s%s GRANT %s ON %s TO %s",
+			pq.QuoteIdentifier(newOwner), inSchema, grant.privilege, objectType, grantee,
+		)
+		if grant.isGrantable {
+			query += " WITH GRANT OPTION"
+		}
+		queries = append(queries, query)
+
+		entry := defaultACLEntry{owner: oldOwner, schema: grant.schema, objectType: grant.objectType, grantee: grant.grantee}
+		if !seenEntries[entry] {
+			seenEntries[entry] = true
+			entries = append(entries, entry)
+		}
+	}
 
+	return append(queries, cleanupDefaultACLQueries(oldOwner, entries)...)
 }
@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
@@ -228,3 +229,206 @@ resource "postgresql_default_privileges" "test_ro" {
 		})
 	}
 }
+
+// Test the tightening of the built-in default privileges:
+// ALTER DEFAULT PRIVILEGES REVOKE EXECUTE ON FUNCTIONS FROM PUBLIC
+func TestAccPostgresqlDefaultPrivileges_RevokeBuiltin(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dsn, _ := config.connStr(dbName)
+
+	// We set PGUSER as owner as he will create the test function
+	var tfConfig = fmt.Sprintf(`
+resource "postgresql_default_privileges" "revoke_public_execute" {
+	database    = "%s"
+	owner       = "%s"
+	role        = "public"
+	object_type = "function"
+	privileges  = []
+}
+`, dbName, config.Username)
+
+	checkPublicExecute := func(allowed bool) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			dbExecute(t, dsn, "CREATE FUNCTION test_schema.test_func() RETURNS int LANGUAGE sql AS 'SELECT 1'")
+			defer dbExecute(t, dsn, "DROP FUNCTION test_schema.test_func()")
+
+			db := connectAsTestRole(t, roleName, dbName)
+			defer db.Close()
+			return testHasGrantForQuery(db, "SELECT test_schema.test_func()", allowed)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.revoke_public_execute", "privileges.#", "0"),
+					checkPublicExecute(false),
+				),
+			},
+			{
+				// The built-in default privileges granted again outside of Terraform are a drift
+				PreConfig: func() {
+					dbExecute(t, dsn, fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s GRANT EXECUTE ON FUNCTIONS TO PUBLIC", config.Username))
+				},
+				Config:             tfConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: tfConfig,
+				Check:  checkPublicExecute(false),
+			},
+		},
+	})
+}
+
+func TestCleanupDefaultACLQueries(t *testing.T) {
+	var tests = []struct {
+		description string
+		entries     []defaultACLEntry
+		expected    []string
+	}{
+		{
+			description: "no entries",
+			entries:     nil,
+			expected:    nil,
+		},
+		{
+			description: "privileges granted to the role by another owner",
+			entries: []defaultACLEntry{
+				{owner: "owner", schema: "test_schema", objectType: "r", grantee: "my_role"},
+			},
+			expected: []string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "owner" IN SCHEMA "test_schema" REVOKE ALL ON TABLES FROM "my_role"`,
+			},
+		},
+		{
+			description: "privileges of another owner not granted to the role are ignored",
+			entries: []defaultACLEntry{
+				{owner: "owner", schema: "test_schema", objectType: "r", grantee: "other_role"},
+			},
+			expected: nil,
+		},
+		{
+			description: "schema privileges defined by the role",
+			entries: []defaultACLEntry{
+				{owner: "my_role", schema: "test_schema", objectType: "S", grantee: "other_role"},
+				{owner: "my_role", schema: "test_schema", objectType: "S", grantee: "public"},
+			},
+			expected: []string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" IN SCHEMA "test_schema" REVOKE ALL ON SEQUENCES FROM "other_role"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" IN SCHEMA "test_schema" REVOKE ALL ON SEQUENCES FROM PUBLIC`,
+			},
+		},
+		{
+			description: "global privileges defined by the role are reset",
+			entries: []defaultACLEntry{
+				{owner: "my_role", objectType: "f", grantee: "my_role"},
+				{owner: "my_role", objectType: "f", grantee: "other_role"},
+				{owner: "my_role", objectType: "r", grantee: "my_role"},
+			},
+			expected: []string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" REVOKE ALL ON FUNCTIONS FROM "my_role"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" REVOKE ALL ON FUNCTIONS FROM "other_role"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" REVOKE ALL ON TABLES FROM "my_role"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" GRANT ALL ON FUNCTIONS TO "my_role"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" GRANT EXECUTE ON FUNCTIONS TO PUBLIC`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "my_role" GRANT ALL ON TABLES TO "my_role"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			queries := cleanupDefaultACLQueries("my_role", test.entries)
+			if !reflect.DeepEqual(queries, test.expected) {
+				t.Errorf("%v != %v", queries, test.expected)
+			}
+		})
+	}
+}
+
+func TestSchemaDefaultACLCleanupQueries(t *testing.T) {
+	entries := []defaultACLEntry{
+		{owner: "owner", schema: "test_schema", objectType: "r", grantee: "my_role"},
+		{owner: "other_owner", schema: "test_schema", objectType: "T", grantee: "public"},
+		{owner: "owner", schema: "test_schema", objectType: "x", grantee: "my_role"},
+	}
+	expected := []string{
+		`ALTER DEFAULT PRIVILEGES FOR ROLE "owner" IN SCHEMA "test_schema" REVOKE ALL ON TABLES FROM "my_role"`,
+		`ALTER DEFAULT PRIVILEGES FOR ROLE "other_owner" IN SCHEMA "test_schema" REVOKE ALL ON TYPES FROM PUBLIC`,
+	}
+
+	if queries := schemaDefaultACLCleanupQueries(entries); !reflect.DeepEqual(queries, expected) {
+		t.Errorf("%v != %v", queries, expected)
+	}
+	if owners := defaultACLEntriesOwners(entries); !reflect.DeepEqual(owners, []string{"owner", "other_owner"}) {
+		t.Errorf("unexpected owners %v", owners)
+	}
+}
+
+func TestMigrateDefaultACLQueries(t *testing.T) {
+	var tests = []struct {
+		description string
+		grants      []defaultACLGrant
+		expected    []string
+	}{
+		{
+			description: "no privileges",
+			grants:      nil,
+			expected:    nil,
+		},
+		{
+			description: "schema privileges",
+			grants: []defaultACLGrant{
+				{schema: "test_schema", objectType: "r", grantee: "reader", privilege: "SELECT"},
+				{schema: "test_schema", objectType: "r", grantee: "writer", privilege: "INSERT", isGrantable: true},
+			},
+			expected: []string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "new_owner" IN SCHEMA "test_schema" GRANT SELECT ON TABLES TO "reader"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "new_owner" IN SCHEMA "test_schema" GRANT INSERT ON TABLES TO "writer" WITH GRANT OPTION`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "old_owner" IN SCHEMA "test_schema" REVOKE ALL ON TABLES FROM "reader"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "old_owner" IN SCHEMA "test_schema" REVOKE ALL ON TABLES FROM "writer"`,
+			},
+		},
+		{
+			description: "global privileges replace the ones of the new owner",
+			grants: []defaultACLGrant{
+				{objectType: "f", grantee: "old_owner", privilege: "EXECUTE"},
+				{objectType: "f", grantee: "app", privilege: "EXECUTE"},
+			},
+			expected: []string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "new_owner" REVOKE ALL ON FUNCTIONS FROM PUBLIC`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "new_owner" REVOKE ALL ON FUNCTIONS FROM "new_owner"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "new_owner" GRANT EXECUTE ON FUNCTIONS TO "new_owner"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "new_owner" GRANT EXECUTE ON FUNCTIONS TO "app"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "old_owner" REVOKE ALL ON FUNCTIONS FROM "old_owner"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "old_owner" REVOKE ALL ON FUNCTIONS FROM "app"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "old_owner" GRANT ALL ON FUNCTIONS TO "old_owner"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "old_owner" GRANT EXECUTE ON FUNCTIONS TO PUBLIC`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			queries := migrateDefaultACLQueries("old_owner", "new_owner", test.grants)
+			if !reflect.DeepEqual(queries, test.expected) {
+				t.Errorf("%v != %v", queries, test.expected)
+			}
+		})
+	}
+}
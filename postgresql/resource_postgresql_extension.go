@@ -2,13 +2,17 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/lib/pq"
 )
 
@@ -18,18 +22,28 @@ const (
 	extVersionAttr     = "version"
 	extDatabaseAttr    = "database"
 	extDropCascadeAttr = "drop_cascade"
+	extCascadeAttr     = "create_cascade"
+	extDropAttr        = "drop"
+	extRelocatableAttr = "relocatable"
+	extWaitForAttr     = "wait_for_object"
+	extWaitTimeoutAttr = "wait_timeout"
+
+	// extVersionLatest is the version to keep the extension up to date with
+	// the newest version available on the server.
+	extVersionLatest = "latest"
 )
 
 func resourcePostgreSQLExtension() *schema.Resource {
 	return &schema.Resource{
 		Create: PGResourceFunc(resourcePostgreSQLExtensionCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLExtensionRead),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLExtensionRead)),
 		Update: PGResourceFunc(resourcePostgreSQLExtensionUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLExtensionDelete),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLExtensionDelete)),
 		Exists: PGResourceExistsFunc(resourcePostgreSQLExtensionExists),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourcePostgreSQLExtensionCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			extNameAttr: {
@@ -47,7 +61,7 @@ func resourcePostgreSQLExtension() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "Sets the version number of the extension",
+				Description: "Sets the version number of the extension, `latest` to always use the newest available version",
 			},
 			extDatabaseAttr: {
 				Type:        schema.TypeString,
@@ -62,45 +76,209 @@ func resourcePostgreSQLExtension() *schema.Resource {
 				Default:     false,
 				Description: "When true, will also drop all the objects that depend on the extension, and in turn all objects that depend on those objects",
 			},
+			extCascadeAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, the extensions required by this extension are also created if they are not installed yet",
+			},
+			extDropAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "When false, the extension is never dropped (e.g.: for shared extensions like plpgsql), it is only removed from the state on destroy",
+			},
+			extRelocatableAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the extension can be moved to another schema after creation",
+			},
+			extWaitForAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "An object created by the extension (type, table, view or function) to wait for after creating it",
+			},
+			extWaitTimeoutAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum time (in seconds) to wait for wait_for_object to be visible",
+			},
+			lockTimeoutAttr: lockTimeoutSchema(),
 		},
 	}
 }
 
-func resourcePostgreSQLExtensionCreate(db *DBConnection, d *schema.ResourceData) error {
-	if !db.featureSupported(featureExtension) {
+// resourcePostgreSQLExtensionCustomizeDiff plans the recreation of the extension when its schema changes
+// only if the extension is not relocatable (as read from pg_extension), the relocatable ones are moved
+// with ALTER EXTENSION ... SET SCHEMA so the objects depending on them are kept.
+// Likewise, the extension is only recreated to change its version if there is no update path
+// to the new version (e.g.: most extensions cannot be downgraded).
+func resourcePostgreSQLExtensionCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := planExtensionVersionChange(d, meta); err != nil {
+		return err
+	}
+
+	if d.Id() == "" || !d.HasChange(extSchemaAttr) || d.Get(extRelocatableAttr).(bool) {
+		return nil
+	}
+
+	extName := d.Get(extNameAttr).(string)
+	oldSchema, newSchema := d.GetChange(extSchemaAttr)
+	if !d.Get(extDropAttr).(bool) {
 		return fmt.Errorf(
-			"postgresql_extension resource is not supported for this Postgres version (%s)",
-			db.version,
+			"extension %s is not relocatable and %s is false, its schema cannot be changed from %s to %s",
+			extName, extDropAttr, oldSchema, newSchema,
 		)
 	}
 
-	extName := d.Get(extNameAttr).(string)
-	databaseName := getDatabaseForExtension(d, db.client.databaseName)
+	log.Printf(
+		"[WARN] extension %s is not relocatable, it will be dropped and recreated to change its schema from %s to %s",
+		extName, oldSchema, newSchema,
+	)
+	return d.ForceNew(extSchemaAttr)
+}
 
-	b := bytes.NewBufferString("CREATE EXTENSION IF NOT EXISTS ")
-	fmt.Fprint(b, pq.QuoteIdentifier(extName))
+// planExtensionVersionChange plans the recreation of the extension if the new version cannot be reached
+// with ALTER EXTENSION ... UPDATE, i.e.: if pg_extension_update_paths has no path from the current version
+// (e.g.: a downgrade without downgrade script). The server is only queried if it is reachable,
+// otherwise the update is attempted.
+func planExtensionVersionChange(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange(extVersionAttr) || !d.NewValueKnown(extVersionAttr) {
+		return nil
+	}
+	oldVersion, newVersion := d.GetChange(extVersionAttr)
+	if oldVersion.(string) == "" || newVersion.(string) == "" || newVersion.(string) == extVersionLatest {
+		return nil
+	}
 
-	if v, ok := d.GetOk(extSchemaAttr); ok {
-		fmt.Fprint(b, " SCHEMA ", pq.QuoteIdentifier(v.(string)))
+	client, ok := meta.(*Client)
+	if !ok || client == nil {
+		return nil
 	}
 
-	if v, ok := d.GetOk(extVersionAttr); ok {
-		fmt.Fprint(b, " VERSION ", pq.QuoteIdentifier(v.(string)))
+	release := client.acquireSlot()
+	defer release()
+
+	extName := d.Get(extNameAttr).(string)
+	database := d.Get(extDatabaseAttr).(string)
+	if database == "" {
+		database = client.databaseName
+	}
+	txn, err := startTransaction(client, database)
+	if err != nil {
+		log.Printf("[WARN] could not check the update paths of extension %s: %v", extName, err)
+		return nil
 	}
+	defer deferredRollback(txn)
 
-	txn, err := startTransaction(db.client, databaseName)
+	available, updatable, err := getExtensionUpdatePath(txn, extName, newVersion.(string))
 	if err != nil {
 		return err
 	}
-	defer deferredRollback(txn)
+	// The update fails with a clear error if the version does not exist
+	if !available || updatable {
+		return nil
+	}
 
-	sql := b.String()
-	if _, err := txn.Exec(sql); err != nil {
+	if !d.Get(extDropAttr).(bool) {
+		return fmt.Errorf(
+			"extension %s cannot be updated from version %s to %s (no update path) and %s is false, it cannot be recreated",
+			extName, oldVersion, newVersion, extDropAttr,
+		)
+	}
+	log.Printf(
+		"[WARN] extension %s cannot be updated from version %s to %s (no update path), "+
+			"it will be dropped and recreated: the data stored in its objects (e.g.: its tables) will be lost",
+		extName, oldVersion, newVersion,
+	)
+	return d.ForceNew(extVersionAttr)
+}
+
+// getExtensionUpdatePath returns if the version of the extension is available on the server
+// and if it can be reached with ALTER EXTENSION ... UPDATE from the installed version
+// (read from pg_extension, as the version in the state may be `latest`).
+func getExtensionUpdatePath(txn *sql.Tx, extName, to string) (available bool, updatable bool, err error) {
+	query := "SELECT true, EXISTS (" +
+		"SELECT 1 FROM pg_catalog.pg_extension e WHERE e.extname = $1 AND e.extversion = $2" +
+		") OR EXISTS (" +
+		"SELECT 1 FROM pg_catalog.pg_extension_update_paths($1) p JOIN pg_catalog.pg_extension e " +
+		"ON e.extname = $1 AND p.source = e.extversion WHERE p.target = $2 AND p.path IS NOT NULL" +
+		") FROM pg_catalog.pg_available_extension_versions WHERE name = $1 AND version = $2"
+	err = txn.QueryRow(query, extName, to).Scan(&available, &updatable)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, false, nil
+	case err != nil:
+		return false, false, fmt.Errorf("could not read the update paths of extension %s: %w", extName, err)
+	}
+	return available, updatable, nil
+}
+
+func resourcePostgreSQLExtensionCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureExtension) {
+		return unsupportedVersionError(db, "postgresql_extension resource")
+	}
+
+	extName := d.Get(extNameAttr).(string)
+	databaseName := getDatabase(d, db.client.databaseName)
+
+	// The statement is built in the transaction, as the version is resolved from the catalog
+	if err := withTransaction(db.client, databaseName, func(txn *sql.Tx) error {
+		b := bytes.NewBufferString("CREATE EXTENSION IF NOT EXISTS ")
+		fmt.Fprint(b, pq.QuoteIdentifier(extName))
+
+		if v, ok := d.GetOk(extSchemaAttr); ok {
+			fmt.Fprint(b, " SCHEMA ", pq.QuoteIdentifier(v.(string)))
+		}
+
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
+
+		var version string
+		if v, ok := d.GetOk(extVersionAttr); ok {
+			var err error
+			version, err = resolveExtVersion(txn, extName, v.(string))
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(b, " VERSION ", pq.QuoteIdentifier(version))
+		}
+
+		if d.Get(extCascadeAttr).(bool) {
+			if !db.featureSupported(featureExtensionCascade) {
+				return withErrorKind(
+					fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support CREATE EXTENSION ... CASCADE", db.version.String()),
+					ErrVersionUnsupported,
+				)
+			}
+			fmt.Fprint(b, " CASCADE")
+		}
+
+		if v, ok := d.GetOk(extSchemaAttr); ok {
+			if err := checkExtSchemaExists(txn, v.(string), databaseName); err != nil {
+				return err
+			}
+		}
+
+		// The extension is created as the connected user: since PostgreSQL 13, the trusted
+		// extensions can be created without being a superuser (with CREATE on the database).
+		sql := b.String()
+		if _, err := txn.Exec(sql); err != nil {
+			return extensionCreateError(db, extName, version, databaseName, err)
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error creating extension: %w", err)
+	if object, ok := d.GetOk(extWaitForAttr); ok {
+		timeout := time.Duration(d.Get(extWaitTimeoutAttr).(int)) * time.Second
+		if err := waitForExtensionObject(db.client, databaseName, extName, object.(string), timeout); err != nil {
+			return err
+		}
 	}
 
 	d.SetId(generateExtensionID(d, databaseName))
@@ -108,12 +286,55 @@ func resourcePostgreSQLExtensionCreate(db *DBConnection, d *schema.ResourceData)
 	return resourcePostgreSQLExtensionReadImpl(db, d)
 }
 
+// waitForExtensionObject waits for the extension and one of its objects to be visible
+// from other sessions (checked with a new connection each time, not in the creating transaction).
+func waitForExtensionObject(client *Client, database, extName, object string, timeout time.Duration) error {
+	ctx := client.config.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	query := extensionObjectQuery(object)
+	err := waitForReady(ctx, timeout, time.Second, func() error {
+		db, err := client.openDedicatedDB(database)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := db.PingContext(ctx); err != nil {
+			return err
+		}
+
+		var visible bool
+		if err := db.QueryRowContext(ctx, query, extName, object).Scan(&visible); err != nil {
+			return fmt.Errorf("could not check if %s exists: %w", object, err)
+		}
+		if !visible {
+			return fmt.Errorf("%s is not visible yet", object)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("objects of extension %s are not usable: %w", extName, err)
+	}
+	return nil
+}
+
+// extensionObjectQuery returns the query checking that the extension $1 and its object $2 exist.
+// A function signature (with its argument types) is looked up with to_regprocedure,
+// other names as a relation, a type or a function which is not overloaded.
+func extensionObjectQuery(object string) string {
+	objectCondition := "to_regclass($2) IS NOT NULL OR to_regtype($2) IS NOT NULL OR to_regproc($2) IS NOT NULL"
+	if strings.Contains(object, "(") {
+		objectCondition = "to_regprocedure($2) IS NOT NULL"
+	}
+	return "SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_extension WHERE extname = $1) AND (" + objectCondition + ")"
+}
+
 func resourcePostgreSQLExtensionExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
 	if !db.featureSupported(featureExtension) {
-		return false, fmt.Errorf(
-			"postgresql_extension resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+		return false, unsupportedVersionError(db, "postgresql_extension resource")
 	}
 
 	var extensionName string
@@ -149,10 +370,7 @@ func resourcePostgreSQLExtensionExists(db *DBConnection, d *schema.ResourceData)
 
 func resourcePostgreSQLExtensionRead(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featureExtension) {
-		return fmt.Errorf(
-			"postgresql_extension resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+		return unsupportedVersionError(db, "postgresql_extension resource")
 	}
 
 	return resourcePostgreSQLExtensionReadImpl(db, d)
@@ -171,10 +389,11 @@ func resourcePostgreSQLExtensionReadImpl(db *DBConnection, d *schema.ResourceDat
 	defer deferredRollback(txn)
 
 	var extSchema, extVersion string
-	query := `SELECT n.nspname, e.extversion ` +
+	var extRelocatable bool
+	query := `SELECT n.nspname, e.extversion, e.extrelocatable ` +
 		`FROM pg_catalog.pg_extension e, pg_catalog.pg_namespace n ` +
 		`WHERE n.oid = e.extnamespace AND e.extname = $1`
-	err = txn.QueryRow(query, extName).Scan(&extSchema, &extVersion)
+	err = txn.QueryRow(query, extName).Scan(&extSchema, &extVersion, &extRelocatable)
 	switch {
 	case err == sql.ErrNoRows:
 		log.Printf("[WARN] PostgreSQL extension (%s) not found for database %s", extName, database)
@@ -184,9 +403,22 @@ func resourcePostgreSQLExtensionReadImpl(db *DBConnection, d *schema.ResourceDat
 		return fmt.Errorf("Error reading extension: %w", err)
 	}
 
+	// When tracking the latest version, we keep it in the state as long as
+	// the installed version is the newest one, otherwise an update is planned.
+	if d.Get(extVersionAttr).(string) == extVersionLatest {
+		latestVersion, err := resolveExtVersion(txn, extName, extVersionLatest)
+		if err != nil {
+			return err
+		}
+		if extVersion == latestVersion {
+			extVersion = extVersionLatest
+		}
+	}
+
 	_ = d.Set(extNameAttr, extName)
 	_ = d.Set(extSchemaAttr, extSchema)
 	_ = d.Set(extVersionAttr, extVersion)
+	_ = d.Set(extRelocatableAttr, extRelocatable)
 	_ = d.Set(extDatabaseAttr, database)
 	d.SetId(generateExtensionID(d, database))
 
@@ -195,33 +427,50 @@ func resourcePostgreSQLExtensionReadImpl(db *DBConnection, d *schema.ResourceDat
 
 func resourcePostgreSQLExtensionDelete(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featureExtension) {
-		return fmt.Errorf(
-			"postgresql_extension resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+		return unsupportedVersionError(db, "postgresql_extension resource")
 	}
 
 	extName := d.Get(extNameAttr).(string)
-	database := getDatabaseForExtension(d, db.client.databaseName)
+	database := getDatabase(d, db.client.databaseName)
 
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
+	if !d.Get(extDropAttr).(bool) {
+		log.Printf("[DEBUG] extension %s in database %s is not dropped (%s is false), removing it from the state only", extName, database, extDropAttr)
+		d.SetId("")
+		return nil
 	}
-	defer deferredRollback(txn)
 
-	dropMode := "RESTRICT"
-	if d.Get(extDropCascadeAttr).(bool) {
-		dropMode = "CASCADE"
-	}
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
 
-	sql := fmt.Sprintf("DROP EXTENSION %s %s ", pq.QuoteIdentifier(extName), dropMode)
-	if _, err := txn.Exec(sql); err != nil {
-		return err
-	}
+		dropMode := "RESTRICT"
+		if d.Get(extDropCascadeAttr).(bool) {
+			dropMode = "CASCADE"
+		}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error deleting extension: %w", err)
+		sql := fmt.Sprintf("DROP EXTENSION %s %s ", pq.QuoteIdentifier(extName), dropMode)
+		if _, err := txn.Exec(sql); err != nil {
+			if !isDependentObjects(err) {
+				return err
+			}
+
+			// The transaction is aborted, the dependencies are read in a new one.
+			deferredRollback(txn)
+			dependents, depErr := getExtensionDependents(db.client, database, extName)
+			if depErr != nil {
+				log.Printf("[WARN] could not read the objects depending on extension %s: %v", extName, depErr)
+				return err
+			}
+			return fmt.Errorf(
+				"could not drop extension %s as other objects depend on it (%s), "+
+					"drop them first (e.g.: with depends_on) or set %s: %w",
+				extName, strings.Join(dependents, ", "), extDropCascadeAttr, err,
+			)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	d.SetId("")
@@ -231,37 +480,37 @@ func resourcePostgreSQLExtensionDelete(db *DBConnection, d *schema.ResourceData)
 
 func resourcePostgreSQLExtensionUpdate(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featureExtension) {
-		return fmt.Errorf(
-			"postgresql_extension resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+		return unsupportedVersionError(db, "postgresql_extension resource")
 	}
 
-	database := getDatabaseForExtension(d, db.client.databaseName)
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	// The schema and the version are changed in the same transaction, so if one of them fails
+	// the other one is rolled back too. The state is then kept as it was before the update
+	// (otherwise Terraform would save the new values of the configuration in it).
+	d.Partial(true)
 
-	// Can't rename a schema
+	database := getDatabase(d, db.client.databaseName)
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
 
-	if err := setExtSchema(txn, d); err != nil {
-		return err
-	}
+		// Can't rename a schema
 
-	if err := setExtVersion(txn, d); err != nil {
+		if err := setExtSchema(txn, d, database); err != nil {
+			return err
+		}
+
+		return setExtVersion(txn, d)
+	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error updating extension: %w", err)
-	}
+	d.Partial(false)
 
 	return resourcePostgreSQLExtensionReadImpl(db, d)
 }
 
-func setExtSchema(txn *sql.Tx, d *schema.ResourceData) error {
+func setExtSchema(txn *sql.Tx, d *schema.ResourceData, database string) error {
 	if !d.HasChange(extSchemaAttr) {
 		return nil
 	}
@@ -273,6 +522,18 @@ func setExtSchema(txn *sql.Tx, d *schema.ResourceData) error {
 		return errors.New("Error setting extension name to an empty string")
 	}
 
+	// Non relocatable extensions are recreated instead (see resourcePostgreSQLExtensionCustomizeDiff)
+	if !d.Get(extRelocatableAttr).(bool) {
+		return fmt.Errorf(
+			"extension %s is not relocatable, its schema cannot be changed after creation (it has to be recreated)",
+			extName,
+		)
+	}
+
+	if err := checkExtSchemaExists(txn, n, database); err != nil {
+		return err
+	}
+
 	sql := fmt.Sprintf("ALTER EXTENSION %s SET SCHEMA %s",
 		pq.QuoteIdentifier(extName), pq.QuoteIdentifier(n))
 	if _, err := txn.Exec(sql); err != nil {
@@ -282,6 +543,98 @@ func setExtSchema(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
+// getExtensionAvailability returns if the extension is available on the server and if it is trusted
+// (i.e.: since PostgreSQL 13, if it can be created by the non-superusers having the CREATE privilege
+// on the database). An extension is trusted if one of its available versions is.
+func getExtensionAvailability(db *DBConnection, extName string) (available bool, trusted bool, err error) {
+	query := "SELECT true, false FROM pg_catalog.pg_available_extensions WHERE name = $1"
+	if db.featureSupported(featureTrustedExtensions) {
+		query = "SELECT true, bool_or(trusted) FROM pg_catalog.pg_available_extension_versions WHERE name = $1 GROUP BY name"
+	}
+
+	err = db.QueryRow(query, extName).Scan(&available, &trusted)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, false, nil
+	case err != nil:
+		return false, false, fmt.Errorf("could not read availability of extension %s: %w", extName, err)
+	}
+	return available, trusted, nil
+}
+
+// extensionCreateError returns the error of the creation of an extension, explaining why it failed
+// if it is not available on the server, if the extensions it requires are not installed
+// or if the connected user is not allowed to create it.
+func extensionCreateError(db *DBConnection, extName, version, database string, err error) error {
+	// The transaction of the creation is aborted, the availability is read outside of it.
+	available, trusted, availErr := getExtensionAvailability(db, extName)
+	switch {
+	case availErr != nil:
+		log.Printf("[WARN] %v", availErr)
+	case !available:
+		return fmt.Errorf("extension %s is not available on the server: %w", extName, err)
+	case isUndefinedObject(err):
+		// PostgreSQL cannot skip the requirements: they have to be created before
+		// (e.g.: with other postgresql_extension resources) or with CASCADE.
+		requires, reqErr := getExtensionRequirements(db, extName, version)
+		if reqErr != nil {
+			log.Printf("[WARN] %v", reqErr)
+		} else if len(requires) > 0 {
+			return fmt.Errorf(
+				"extension %s requires extensions %s in database %s, create them first or set %s: %w",
+				extName, strings.Join(requires, ", "), database, extCascadeAttr, err,
+			)
+		}
+	case !isInsufficientPrivilege(err):
+	case trusted:
+		return fmt.Errorf(
+			"extension %s is trusted, the connected user needs the CREATE privilege on database %s to create it: %w",
+			extName, database, err,
+		)
+	case db.featureSupported(featureTrustedExtensions):
+		return fmt.Errorf("extension %s is not trusted, it can only be created by a superuser: %w", extName, err)
+	default:
+		return fmt.Errorf(
+			"extension %s can only be created by a superuser before PostgreSQL 13 (%s): %w",
+			extName, db.version, err,
+		)
+	}
+
+	return fmt.Errorf("Error creating extension %s: %w", extName, err)
+}
+
+// getExtensionRequirements returns the extensions required by a version of the extension,
+// as listed in pg_available_extension_versions (the default version if version is empty).
+func getExtensionRequirements(db *DBConnection, extName, version string) ([]string, error) {
+	var requires pq.StringArray
+	err := db.QueryRow(
+		"SELECT requires FROM pg_catalog.pg_available_extension_versions v "+
+			"WHERE v.name = $1 AND v.version = COALESCE(NULLIF($2, ''), "+
+			"(SELECT e.default_version FROM pg_catalog.pg_available_extensions e WHERE e.name = $1))",
+		extName, version,
+	).Scan(&requires)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the extensions required by extension %s: %w", extName, err)
+	}
+	return requires, nil
+}
+
+// checkExtSchemaExists returns an explicit error if the schema in which the extension
+// should be installed does not exist, instead of the server's error.
+func checkExtSchemaExists(txn *sql.Tx, schemaName, database string) error {
+	exists, err := schemaExists(txn, schemaName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf(
+			"schema %s does not exist in database %s, it has to be created before the extension",
+			schemaName, database,
+		)
+	}
+	return nil
+}
+
 func setExtVersion(txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(extVersionAttr) {
 		return nil
@@ -295,7 +648,11 @@ func setExtVersion(txn *sql.Tx, d *schema.ResourceData) error {
 	_, nraw := d.GetChange(extVersionAttr)
 	n := nraw.(string)
 	if n != "" {
-		fmt.Fprintf(b, " TO %s", pq.QuoteIdentifier(n))
+		version, err := resolveExtVersion(txn, extName, n)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, " TO %s", pq.QuoteIdentifier(version))
 	}
 
 	sql := b.String()
@@ -306,12 +663,110 @@ func setExtVersion(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func getDatabaseForExtension(d *schema.ResourceData, databaseName string) string {
-	if v, ok := d.GetOk(extDatabaseAttr); ok {
-		databaseName = v.(string)
+// resolveExtVersion returns the version to install for the extension,
+// i.e.: the newest available version if `latest` is requested.
+// Versions are compared as semantic versions, if some of them cannot be parsed
+// we fall back on the default version of the extension.
+func resolveExtVersion(txn *sql.Tx, extName, version string) (string, error) {
+	if version != extVersionLatest {
+		return version, nil
+	}
+
+	rows, err := txn.Query(
+		"SELECT version FROM pg_catalog.pg_available_extension_versions WHERE name = $1", extName,
+	)
+	if err != nil {
+		return "", fmt.Errorf("could not read available versions of extension %s: %w", extName, err)
+	}
+	defer rows.Close()
+
+	var versions []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return "", fmt.Errorf("could not read available versions of extension %s: %w", extName, err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("could not read available versions of extension %s: %w", extName, err)
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("extension %s is not available on the server", extName)
+	}
+
+	if latest, ok := newestVersion(versions); ok {
+		return latest, nil
+	}
+
+	var defaultVersion string
+	if err := txn.QueryRow(
+		"SELECT default_version FROM pg_catalog.pg_available_extensions WHERE name = $1", extName,
+	).Scan(&defaultVersion); err != nil {
+		return "", fmt.Errorf("could not read default version of extension %s: %w", extName, err)
+	}
+	return defaultVersion, nil
+}
+
+// newestVersion returns the newest of the versions if they can all be parsed as semantic versions.
+func newestVersion(versions []string) (string, bool) {
+	var newest string
+	var newestParsed semver.Version
+
+	for _, v := range versions {
+		parsed, err := semver.ParseTolerant(v)
+		if err != nil {
+			return "", false
+		}
+		if newest == "" || parsed.GT(newestParsed) {
+			newest = v
+			newestParsed = parsed
+		}
+	}
+	return newest, newest != ""
+}
+
+// getExtensionDependents returns the description of the objects depending on the extension
+// or on one of its member objects (e.g.: a table using a type of the extension).
+// The members are left joined so the dependents of an extension without member objects are returned too.
+func getExtensionDependents(client *Client, database, extName string) ([]string, error) {
+	txn, err := startTransaction(client, database)
+	if err != nil {
+		return nil, err
+	}
+	defer deferredRollback(txn)
+
+	query := `
+SELECT DISTINCT pg_catalog.pg_describe_object(dep.classid, dep.objid, dep.objsubid) AS description
+FROM pg_catalog.pg_extension e
+LEFT JOIN pg_catalog.pg_depend member ON member.refclassid = 'pg_catalog.pg_extension'::regclass
+	AND member.refobjid = e.oid AND member.deptype = 'e'
+JOIN pg_catalog.pg_depend dep ON (
+	(dep.refclassid = member.classid AND dep.refobjid = member.objid)
+	OR (dep.refclassid = 'pg_catalog.pg_extension'::regclass AND dep.refobjid = e.oid)
+) AND dep.deptype = 'n'
+WHERE e.extname = $1
+AND NOT EXISTS (
+	SELECT 1 FROM pg_catalog.pg_depend m
+	WHERE m.classid = dep.classid AND m.objid = dep.objid AND m.deptype = 'e' AND m.refobjid = e.oid
+)
+ORDER BY description`
+
+	rows, err := txn.Query(query, extName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read dependencies of extension %s: %w", extName, err)
 	}
+	defer rows.Close()
 
-	return databaseName
+	var dependents []string
+	for rows.Next() {
+		var dependent string
+		if err := rows.Scan(&dependent); err != nil {
+			return nil, fmt.Errorf("could not scan dependency of extension %s: %w", extName, err)
+		}
+		dependents = append(dependents, dependent)
+	}
+	return dependents, rows.Err()
 }
 
 func generateExtensionID(d *schema.ResourceData, databaseName string) string {
@@ -330,7 +785,7 @@ func getExtensionNameFromID(ID string) string {
 // from the resource ID (it will return an error if parsing failed) otherwise they will be simply
 // get from the state.
 func getDBExtName(d *schema.ResourceData, client *Client) (string, string, error) {
-	database := getDatabaseForExtension(d, client.databaseName)
+	database := getDatabase(d, client.databaseName)
 	extName := d.Get(extNameAttr).(string)
 
 	// When importing, we have to parse the ID to find extension and database names.
@@ -3,6 +3,9 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
@@ -29,6 +32,8 @@ func TestAccPostgresqlExtension_Basic(t *testing.T) {
 						"postgresql_extension.myextension", "name", "pg_trgm"),
 					resource.TestCheckResourceAttr(
 						"postgresql_extension.myextension", "schema", "public"),
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.myextension", "relocatable", "true"),
 
 					// NOTE(sean): The version number drifts.  PG 9.6 ships with pg_trgm
 					// version 1.3 and PG 9.2 ships with pg_trgm 1.0.
@@ -156,6 +161,171 @@ func TestAccPostgresqlExtension_SchemaRename(t *testing.T) {
 	})
 }
 
+// Test that the schema and the version of an extension are changed together,
+// and that nothing is changed (neither on the server nor in the state) if one of them fails.
+func TestAccPostgresqlExtension_SchemaAndVersionChange(t *testing.T) {
+	config := func(schema, version string) string {
+		return fmt.Sprintf(`
+resource "postgresql_schema" "ext_from" {
+  name = "ext_from"
+}
+
+resource "postgresql_schema" "ext_to" {
+  name = "ext_to"
+}
+
+resource "postgresql_extension" "ext_move" {
+  name    = "pg_trgm"
+  schema  = postgresql_schema.%s.name
+  version = "%s"
+}
+`, schema, version)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config("ext_from", "1.3"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.ext_move"),
+					resource.TestCheckResourceAttr("postgresql_extension.ext_move", "schema", "ext_from"),
+					resource.TestCheckResourceAttr("postgresql_extension.ext_move", "version", "1.3"),
+				),
+			},
+			{
+				Config: config("ext_to", "latest"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_extension.ext_move", "schema", "ext_to"),
+					resource.TestCheckResourceAttr("postgresql_extension.ext_move", "version", "latest"),
+				),
+			},
+			{
+				// The schema change is rolled back as the version does not exist
+				Config:      config("ext_from", "0.0-doesnotexist"),
+				ExpectError: regexp.MustCompile("Error updating extension version"),
+			},
+			{
+				// The state still matches the server
+				Config:   config("ext_to", "latest"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_VersionDowngrade(t *testing.T) {
+	config := func(version string) string {
+		return fmt.Sprintf(`
+resource "postgresql_extension" "ext_downgrade" {
+  name    = "pg_trgm"
+  version = "%s"
+}
+`, version)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config("1.4"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.ext_downgrade"),
+					resource.TestCheckResourceAttr("postgresql_extension.ext_downgrade", "version", "1.4"),
+				),
+			},
+			{
+				// pg_trgm has no downgrade script, the extension is recreated
+				Config: config("1.3"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.ext_downgrade"),
+					resource.TestCheckResourceAttr("postgresql_extension.ext_downgrade", "version", "1.3"),
+				),
+			},
+			{
+				// While the upgrades are still applied with ALTER EXTENSION ... UPDATE
+				Config: config("1.4"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_extension.ext_downgrade", "version", "1.4"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_SchemaNotExists(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccPostgresqlExtensionSchemaNotExists,
+				ExpectError: regexp.MustCompile("schema doesnotexist does not exist in database"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_LatestVersion(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlExtensionLatestVersion,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.myextension"),
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.myextension", "version", "latest"),
+				),
+			},
+		},
+	})
+}
+
+func TestNewestVersion(t *testing.T) {
+	var tests = []struct {
+		input  []string
+		want   string
+		wantOk bool
+	}{
+		{[]string{"1.0", "1.3", "1.1"}, "1.3", true},
+		{[]string{"1.10", "1.9"}, "1.10", true},
+		{[]string{"2.5.1", "2.5.0", "2.4"}, "2.5.1", true},
+		{[]string{"1.0", "unpackaged"}, "", false},
+		{nil, "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := newestVersion(test.input)
+		if got != test.want || ok != test.wantOk {
+			t.Errorf("newestVersion(%v) = %q, %v, want %q, %v", test.input, got, ok, test.want, test.wantOk)
+		}
+	}
+}
+
 func checkExtensionExists(txn *sql.Tx, extensionName string) (bool, error) {
 	var _rez bool
 	err := txn.QueryRow("SELECT TRUE from pg_catalog.pg_extension d WHERE extname=$1", extensionName).Scan(&_rez)
@@ -169,6 +339,127 @@ func checkExtensionExists(txn *sql.Tx, extensionName string) (bool, error) {
 	return true, nil
 }
 
+func TestAccPostgresqlExtension_WaitForObject(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_extension" "trgm" {
+  name            = "pg_trgm"
+  database        = "%s"
+  wait_for_object = "public.similarity(text, text)"
+}
+
+resource "postgresql_extension" "hstore" {
+  name            = "hstore"
+  database        = "%[1]s"
+  wait_for_object = "public.hstore"
+}
+`, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.trgm"),
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.hstore"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_extension" "missing_object" {
+  name            = "pg_trgm"
+  database        = "%s"
+  schema          = "test_schema"
+  wait_for_object = "test_schema.does_not_exist"
+  wait_timeout    = 2
+}
+`, dbName),
+				ExpectError: regexp.MustCompile("objects of extension pg_trgm are not usable"),
+			},
+		},
+	})
+}
+
+func TestExtensionObjectQuery(t *testing.T) {
+	var tests = []struct {
+		object   string
+		contains string
+	}{
+		{"public.hstore", "to_regtype($2) IS NOT NULL"},
+		{"similarity", "to_regproc($2) IS NOT NULL"},
+		{"public.similarity(text, text)", "to_regprocedure($2) IS NOT NULL"},
+	}
+
+	for _, test := range tests {
+		if got := extensionObjectQuery(test.object); !strings.Contains(got, test.contains) {
+			t.Errorf("extensionObjectQuery(%q) = %q, want it to contain %q", test.object, got, test.contains)
+		}
+	}
+}
+
+func TestExtensionSchemaChange(t *testing.T) {
+	var tests = []struct {
+		name        string
+		relocatable bool
+		drop        bool
+		requiresNew bool
+		wantErr     bool
+	}{
+		{"relocatable", true, true, false, false},
+		{"relocatable without drop", true, false, false, false},
+		{"not relocatable", false, true, true, false},
+		{"not relocatable without drop", false, false, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &terraform.InstanceState{
+				ID: "mydb.myext",
+				Attributes: map[string]string{
+					"id":               "mydb.myext",
+					extNameAttr:        "myext",
+					extDatabaseAttr:    "mydb",
+					extSchemaAttr:      "foo",
+					extVersionAttr:     "1.0",
+					extDropAttr:        strconv.FormatBool(test.drop),
+					extRelocatableAttr: strconv.FormatBool(test.relocatable),
+				},
+			}
+			config := map[string]interface{}{
+				extNameAttr:     "myext",
+				extDatabaseAttr: "mydb",
+				extSchemaAttr:   "bar",
+				extDropAttr:     test.drop,
+			}
+
+			diff, err := resourcePostgreSQLExtension().Diff(state, terraform.NewResourceConfigRaw(config), nil)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Diff() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if diff == nil || diff.Attributes[extSchemaAttr] == nil {
+				t.Fatalf("expected a diff on %s", extSchemaAttr)
+			}
+			if diff.RequiresNew() != test.requiresNew {
+				t.Errorf("requires new = %t, want %t", diff.RequiresNew(), test.requiresNew)
+			}
+		})
+	}
+}
+
 func TestAccPostgresqlExtension_Database(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -211,6 +502,98 @@ func TestAccPostgresqlExtension_Database(t *testing.T) {
 	})
 }
 
+// earthdistance requires the cube extension, which is not installed in the test database.
+func TestAccPostgresqlExtension_CreateCascade(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	config := `
+resource "postgresql_extension" "earthdistance" {
+  name           = "earthdistance"
+  database       = "%s"
+  create_cascade = %t
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtensionCascade)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(config, dbName, false),
+				ExpectError: regexp.MustCompile("extension earthdistance requires extensions cube"),
+			},
+			{
+				Config: fmt.Sprintf(config, dbName, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.earthdistance"),
+					resource.TestCheckResourceAttr("postgresql_extension.earthdistance", "create_cascade", "true"),
+				),
+			},
+		},
+	})
+}
+
+// Test that a non-superuser having the CREATE privilege on the database can create
+// a trusted extension, and gets a clear error for an extension which is not trusted.
+func TestAccPostgresqlExtension_TrustedByNonSuperuser(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr(dbName)
+	dbExecute(t, dsn, fmt.Sprintf("GRANT CREATE ON DATABASE %s TO %s", dbName, roleName))
+	dbExecute(t, dsn, fmt.Sprintf("GRANT CREATE ON SCHEMA test_schema TO %s", roleName))
+
+	config := `
+	provider "postgresql" {
+		username  = "%s"
+		password  = "%s"
+		superuser = false
+	}
+
+	resource "postgresql_extension" "myextension" {
+		name     = "%s"
+		database = "%s"
+		schema   = "test_schema"
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureTrustedExtensions)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, roleName, testRolePassword, "pgcrypto", dbName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.myextension"),
+					resource.TestCheckResourceAttr("postgresql_extension.myextension", "name", "pgcrypto"),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(config, roleName, testRolePassword, "dblink", dbName),
+				ExpectError: regexp.MustCompile("extension dblink is not trusted"),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlExtension_DropCascade(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -287,3 +670,120 @@ resource "postgresql_extension" "ext1trgm" {
   schema = "${postgresql_schema.ext1foo.name}"
 }
 `
+
+var testAccPostgresqlExtensionSchemaNotExists = `
+resource "postgresql_extension" "ext1trgm" {
+  name   = "pg_trgm"
+  schema = "doesnotexist"
+}
+`
+
+var testAccPostgresqlExtensionLatestVersion = `
+resource "postgresql_extension" "myextension" {
+  name    = "pg_trgm"
+  version = "latest"
+}
+`
+
+func TestAccPostgresqlExtension_DropRestrictDependents(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr(dbName)
+
+	extConfig := fmt.Sprintf(`
+resource "postgresql_extension" "restrict" {
+  name     = "pgcrypto"
+  database = "%s"
+}
+`, dbName)
+	// Removing the resource from the configuration destroys it.
+	noExtConfig := "# no extension\n"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: extConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.restrict"),
+					func(*terraform.State) error {
+						dbExecute(t, dsn, "CREATE TABLE test_restrict (val bytea DEFAULT digest('val', 'sha1'))")
+						return nil
+					},
+				),
+			},
+			{
+				Config:      noExtConfig,
+				ExpectError: regexp.MustCompile(`could not drop extension pgcrypto as other objects depend on it \(.*table test_restrict.*\)`),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "DROP TABLE test_restrict")
+				},
+				Config: noExtConfig,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_NoDrop(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureExtension)
+			testSuperuserPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		// The extension must still exist after destroy.
+		CheckDestroy: func(*terraform.State) error {
+			client := getTestProvider(t).Meta().(*Client)
+			txn, err := startTransaction(client, dbName)
+			if err != nil {
+				return err
+			}
+			defer deferredRollback(txn)
+
+			exists, err := checkExtensionExists(txn, "pg_trgm")
+			if err != nil {
+				return fmt.Errorf("Error checking extension %s", err)
+			}
+			if !exists {
+				return fmt.Errorf("Extension should not have been dropped")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_extension" "no_drop" {
+  name     = "pg_trgm"
+  database = "%s"
+  drop     = false
+}
+`, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists(t, "postgresql_extension.no_drop"),
+					resource.TestCheckResourceAttr("postgresql_extension.no_drop", "drop", "false"),
+				),
+			},
+		},
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
@@ -14,11 +15,15 @@ import (
 )
 
 var allowedObjectTypes = []string{
+	"column",
 	"database",
+	"foreign_data_wrapper",
+	"foreign_server",
 	"function",
 	"schema",
 	"sequence",
 	"table",
+	"type",
 }
 
 var objectTypes = map[string]string{
@@ -28,24 +33,58 @@ var objectTypes = map[string]string{
 	"type":     "T",
 }
 
+// foreignObjectTypes maps the object types which do not belong to a schema
+// (granted by their name only) to their SQL keyword.
+var foreignObjectTypes = map[string]string{
+	"foreign_server":       "FOREIGN SERVER",
+	"foreign_data_wrapper": "FOREIGN DATA WRAPPER",
+}
+
+const (
+	// Privileges not in the configuration are revoked
+	grantReconcileExclusive = "exclusive"
+	// Privileges not in the configuration are left untouched
+	grantReconcileAdditive = "additive"
+	// Only the privileges granted by the resource are managed, even with an empty list
+	grantReconcilePrivilegesManaged = "privileges_managed"
+)
+
 func resourcePostgreSQLGrant() *schema.Resource {
-	return &schema.Resource{
+	resource := &schema.Resource{
 		Create: PGResourceFunc(resourcePostgreSQLGrantCreate),
 		// As create revokes and grants we can use it to update too
 		Update: PGResourceFunc(resourcePostgreSQLGrantCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantDelete),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLGrantRead)),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLGrantDelete)),
+		Importer: &schema.ResourceImporter{
+			State: resourcePostgreSQLGrantImportState,
+		},
+
+		CustomizeDiff: resourcePostgreSQLGrantCustomizeDiff,
+
+		SchemaVersion: 1,
 
 		Schema: map[string]*schema.Schema{
 			"role": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "The name of the role to grant privileges on",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"roles"},
+				Description:   "The name of the role to grant privileges on",
+			},
+			"roles": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				MinItems:      1,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Set:           schema.HashString,
+				ConflictsWith: []string{"role"},
+				Description:   "The names of the roles to grant the same privileges on (instead of role)",
 			},
 			"database": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 				Description: "The database to grant privileges on for this role",
 			},
@@ -63,12 +102,21 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Description:  "The PostgreSQL object type to grant the privileges on (one of: " + strings.Join(allowedObjectTypes, ", ") + ")",
 			},
 			"objects": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ForceNew:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Set:           schema.HashString,
+				Description:   "The specific objects to grant privileges on for this role (empty means all objects of the requested type)",
+				ConflictsWith: []string{"apply_to_all_existing"},
+			},
+			"columns": {
 				Type:        schema.TypeSet,
 				Optional:    true,
 				ForceNew:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Set:         schema.HashString,
-				Description: "The specific objects to grant privileges on for this role (empty means all objects of the requested type)",
+				Description: "The columns of the table (in objects) to grant privileges on for this role (only for the column object type)",
 			},
 			"privileges": &schema.Schema{
 				Type:        schema.TypeSet,
@@ -77,6 +125,25 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Set:         schema.HashString,
 				Description: "The list of privileges to grant",
 			},
+			"copy_from_role": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "A template role whose privileges on the objects are granted to the role too",
+			},
+			"configured_privileges": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The privileges of the configuration applied last, which the reconcile mode applies to",
+			},
+			"copied_privileges": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The privileges of the template role (copy_from_role) granted to the role",
+			},
 			"with_grant_option": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -84,16 +151,188 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Default:     false,
 				Description: "Permit the grant recipient to grant it to others",
 			},
+			"revoke_cascade": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Revoke the privileges with CASCADE, also revoking the privileges the role granted to others with its grant option",
+			},
+			"revoke_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Revoke the privileges when the resource is destroyed, otherwise it is only removed from the state",
+			},
+			"reconcile_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      grantReconcileAdditive,
+				ValidateFunc: validation.StringInSlice([]string{grantReconcileExclusive, grantReconcileAdditive, grantReconcilePrivilegesManaged}, false),
+				Description: "exclusive: the privileges of the role exactly match the configured ones, " +
+					"additive: only the configured privileges are managed, the other ones are left untouched, " +
+					"privileges_managed: same as additive, but an empty privileges list does not revoke the other ones",
+			},
+			"apply_to_all_existing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+				Description: "Grant the privileges once with GRANT ... ON ALL ... IN SCHEMA on the existing objects, " +
+					"without reconciling the privileges of each object",
+				ConflictsWith: []string{"objects"},
+			},
+			lockTimeoutAttr: lockTimeoutSchema(),
+		},
+	}
+	resource.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    resourcePostgreSQLGrantResourceV0(resource.Schema).CoreConfigSchema().ImpliedType(),
+			Upgrade: resourcePostgreSQLGrantStateUpgradeV0,
 		},
 	}
+	return resource
+}
+
+// resourcePostgreSQLGrantCustomizeDiff checks the privileges are valid for the object type
+// and reads the current privileges of the template role (copy_from_role) during the plan,
+// so the grant is updated when they change.
+func resourcePostgreSQLGrantCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := validateGrantPrivilegesDiff(d, meta); err != nil {
+		return err
+	}
+
+	if !d.NewValueKnown("privileges") {
+		if err := d.SetNewComputed("configured_privileges"); err != nil {
+			return err
+		}
+	} else if privileges := d.Get("privileges").(*schema.Set); !sameStringSets(privileges, d.Get("configured_privileges").(*schema.Set)) {
+		if err := d.SetNew("configured_privileges", privileges.List()); err != nil {
+			return err
+		}
+	}
+
+	if !d.NewValueKnown("copy_from_role") {
+		return d.SetNewComputed("copied_privileges")
+	}
+
+	template := d.Get("copy_from_role").(string)
+	if template == "" {
+		if d.Get("copied_privileges").(*schema.Set).Len() > 0 {
+			return d.SetNew("copied_privileges", []interface{}{})
+		}
+		return nil
+	}
+
+	// The privileges are read when the grant is created or when the template changes
+	// (the template role or the objects may not exist yet).
+	if d.Id() == "" || d.HasChange("copy_from_role") || !d.NewValueKnown("objects") {
+		return d.SetNewComputed("copied_privileges")
+	}
+
+	client, ok := meta.(*Client)
+	if !ok || client == nil {
+		return nil
+	}
+
+	release := client.acquireSlot()
+	defer release()
+
+	database := d.Get("database").(string)
+	if database == "" {
+		database = client.databaseName
+	}
+	txn, err := startTransaction(client, database)
+	if err != nil {
+		log.Printf("[WARN] could not read the privileges of template role %s: %v", template, err)
+		return d.SetNewComputed("copied_privileges")
+	}
+	defer deferredRollback(txn)
+
+	exists, err := roleExists(txn, template)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// The template role may be created in the same apply
+		return d.SetNewComputed("copied_privileges")
+	}
+
+	privileges, err := readTemplatePrivileges(
+		client, txn, database, d.Get("schema").(string), d.Get("object_type").(string),
+		d.Get("objects").(*schema.Set), template,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !sameStringSets(privileges, d.Get("copied_privileges").(*schema.Set)) {
+		return d.SetNew("copied_privileges", privileges)
+	}
+	return nil
+}
+
+// resourcePostgreSQLGrantResourceV0 returns the schema of the grant before configured_privileges was added.
+func resourcePostgreSQLGrantResourceV0(current map[string]*schema.Schema) *schema.Resource {
+	v0 := map[string]*schema.Schema{}
+	for name, attr := range current {
+		if name != "configured_privileges" {
+			v0[name] = attr
+		}
+	}
+	return &schema.Resource{Schema: v0}
+}
+
+// resourcePostgreSQLGrantStateUpgradeV0 sets configured_privileges from the privileges of the state,
+// which were the configured ones unless some of them were missing or granted outside of Terraform.
+func resourcePostgreSQLGrantStateUpgradeV0(rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+	rawState["configured_privileges"] = rawState["privileges"]
+	return rawState, nil
+}
+
+// validateGrantPrivilegesDiff rejects during the plan the privileges which cannot be granted
+// on the object type (e.g.: EXECUTE on a table), rather than failing with a server error during the apply.
+// The privileges only supported by recent versions (e.g.: MAINTAIN) are checked if the server is reachable.
+func validateGrantPrivilegesDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("object_type") || !d.NewValueKnown("privileges") {
+		return nil
+	}
+	privileges := d.Get("privileges").(*schema.Set).List()
+	if len(privileges) == 0 {
+		return nil
+	}
+
+	var db *DBConnection
+	if client, ok := meta.(*Client); ok && client != nil && hasVersionedPrivilege(privileges) {
+		release := client.acquireSlot()
+		defer release()
+
+		var err error
+		if db, err = client.Connect(); err != nil {
+			log.Printf("[WARN] could not check the privileges against the server version: %v", err)
+			db = nil
+		}
+	}
+
+	return validateObjectPrivileges(db, d.Get("object_type").(string), privileges)
+}
+
+// hasVersionedPrivilege returns true if one of the privileges is only supported from a given Postgres version.
+func hasVersionedPrivilege(privileges []interface{}) bool {
+	for _, priv := range privileges {
+		if _, ok := versionedPrivileges[priv.(string)]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func resourcePostgreSQLGrantRead(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featurePrivileges) {
-		return fmt.Errorf(
-			"postgresql_grant resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+		return unsupportedVersionError(db, "postgresql_grant resource")
 	}
 
 	exists, err := checkRoleDBSchemaExists(db.client, d)
@@ -112,143 +351,504 @@ func resourcePostgreSQLGrantRead(db *DBConnection, d *schema.ResourceData) error
 	}
 	defer deferredRollback(txn)
 
-	return readRolePrivileges(txn, d)
+	return readRolePrivileges(db, txn, d)
 }
 
-func resourcePostgreSQLGrantCreate(db *DBConnection, d *schema.ResourceData) error {
+func resourcePostgreSQLGrantImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if err := PGResourceFunc(resourcePostgreSQLGrantImport)(d, meta); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// grantImportQueries return the privileges ($1: role OID) and whether they are grantable,
+// read from the ACL of the imported object like in readGranteePrivileges.
+var grantImportQueries = map[string]string{
+	"database": `
+SELECT p.privilege_type, p.is_grantable
+FROM pg_catalog.pg_database, aclexplode(COALESCE(datacl, acldefault('d', datdba))) p
+WHERE datname = $2 AND p.grantee = $1
+`,
+	"schema": `
+SELECT p.privilege_type, p.is_grantable
+FROM pg_catalog.pg_namespace, aclexplode(COALESCE(nspacl, acldefault('n', nspowner))) p
+WHERE nspname = $2 AND p.grantee = $1
+`,
+	"relation": `
+SELECT p.privilege_type, p.is_grantable
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace,
+aclexplode(COALESCE(c.relacl, CASE WHEN c.relkind = 'S' THEN acldefault('s', c.relowner) END)) p
+WHERE n.nspname = $2 AND c.relname = $3 AND p.grantee = $1
+`,
+}
+
+// resourcePostgreSQLGrantImport imports the privileges of a role on a single object, from an ID of the form
+// database/role (database), database/schema/role (schema) or database/schema/object/role (table or sequence).
+// All the privileges of the role are read from the ACL of the object, so the imported grant does not plan any change.
+func resourcePostgreSQLGrantImport(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featurePrivileges) {
+		return unsupportedVersionError(db, "postgresql_grant resource")
+	}
+
+	database, pgSchema, object, role, err := parseGrantImportID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	roleOID, err := getRoleOID(txn, role)
+	if err != nil {
+		return err
+	}
+
+	var objectType string
+	var rows *sql.Rows
+	switch {
+	case pgSchema == "":
+		objectType = "database"
+		rows, err = txn.Query(grantImportQueries[objectType], roleOID, database)
+	case object == "":
+		objectType = "schema"
+		rows, err = txn.Query(grantImportQueries[objectType], roleOID, pgSchema)
+	default:
+		if objectType, err = getRelationObjectType(txn, pgSchema, object); err != nil {
+			return err
+		}
+		rows, err = txn.Query(grantImportQueries["relation"], roleOID, pgSchema, object)
+	}
+	if err != nil {
+		return fmt.Errorf("could not read privileges of role %s to import grant %s: %w", role, d.Id(), err)
+	}
+	defer rows.Close()
+
+	privileges := []string{}
+	grantable := 0
+	for rows.Next() {
+		var privilege string
+		var isGrantable bool
+		if err := rows.Scan(&privilege, &isGrantable); err != nil {
+			return fmt.Errorf("could not scan privileges of role %s: %w", role, err)
+		}
+		privileges = append(privileges, privilege)
+		if isGrantable {
+			grantable++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// with_grant_option applies to all the privileges of the grant
+	if grantable > 0 && grantable < len(privileges) {
 		return fmt.Errorf(
-			"postgresql_grant resource is not supported for this Postgres version (%s)",
-			db.version,
+			"only some of the privileges %v of role %s are granted WITH GRANT OPTION, they cannot be imported as a single grant",
+			privileges, role,
 		)
 	}
 
+	_ = d.Set("role", role)
+	_ = d.Set("database", database)
+	_ = d.Set("schema", pgSchema)
+	_ = d.Set("object_type", objectType)
+	if object != "" {
+		_ = d.Set("objects", []string{object})
+	}
+	_ = d.Set("privileges", privileges)
+	_ = d.Set("configured_privileges", privileges)
+	_ = d.Set("with_grant_option", len(privileges) > 0 && grantable == len(privileges))
+	// The attributes which are not read are set to their defaults, not to plan a change
+	_ = d.Set("revoke_cascade", false)
+	_ = d.Set("revoke_on_destroy", true)
+	_ = d.Set("reconcile_mode", grantReconcileAdditive)
+	_ = d.Set("apply_to_all_existing", false)
+	_ = d.Set(lockTimeoutAttr, 0)
+	d.SetId(generateGrantID(d))
+
+	return nil
+}
+
+// parseGrantImportID splits the ID of an imported grant, schema and object are empty
+// to import the privileges on the database (resp. on the schema).
+func parseGrantImportID(id string) (database, pgSchema, object, role string, err error) {
+	parts := strings.Split(id, "/")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return parts[0], "", "", parts[1], nil
+	case 3:
+		return parts[0], parts[1], "", parts[2], nil
+	case 4:
+		return parts[0], parts[1], parts[2], parts[3], nil
+	}
+	return "", "", "", "", fmt.Errorf(
+		"grant ID %s has not one of the expected formats 'database/role', 'database/schema/role' or 'database/schema/object/role'", id,
+	)
+}
+
+// getRelationObjectType returns the object type (table or sequence) of the relation in the schema.
+func getRelationObjectType(txn *sql.Tx, pgSchema, relation string) (string, error) {
+	var relkind string
+	err := txn.QueryRow(
+		"SELECT c.relkind FROM pg_catalog.pg_class c "+
+			"JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace "+
+			"WHERE n.nspname = $1 AND c.relname = $2",
+		pgSchema, relation,
+	).Scan(&relkind)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", fmt.Errorf("relation %s.%s does not exist", pgSchema, relation)
+	case err != nil:
+		return "", fmt.Errorf("could not read relation %s.%s: %w", pgSchema, relation, err)
+	}
+
+	for objectType, kind := range objectTypes {
+		if kind == relkind && (objectType == "table" || objectType == "sequence") {
+			return objectType, nil
+		}
+	}
+	return "", fmt.Errorf("relation %s.%s is neither a table nor a sequence (relkind %s), its grant cannot be imported", pgSchema, relation, relkind)
+}
+
+func resourcePostgreSQLGrantCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return unsupportedVersionError(db, "postgresql_grant resource")
+	}
+
 	// Validate parameters.
+	roles := granteeRoles(d)
+	if len(roles) == 0 {
+		return fmt.Errorf("one of `role` or `roles` is required for postgresql_grant resource")
+	}
 	objectType := d.Get("object_type").(string)
-	if d.Get("schema").(string) == "" && objectType != "database" {
+	_, isForeignObject := foreignObjectTypes[objectType]
+	if isForeignObject {
+		if d.Get("schema").(string) != "" {
+			return fmt.Errorf("cannot specify `schema` when `object_type` is `%s`", objectType)
+		}
+		if d.Get("objects").(*schema.Set).Len() == 0 {
+			return fmt.Errorf("at least one object in `objects` is required when `object_type` is `%s`", objectType)
+		}
+		if isApplyToAllExisting(d) {
+			return fmt.Errorf("cannot specify `apply_to_all_existing` when `object_type` is `%s`", objectType)
+		}
+	} else if d.Get("schema").(string) == "" && objectType != "database" {
 		return fmt.Errorf("parameter 'schema' is mandatory for postgresql_grant resource")
 	}
 	if d.Get("objects").(*schema.Set).Len() > 0 && (objectType == "database" || objectType == "schema") {
 		return fmt.Errorf("cannot specify `objects` when `object_type` is `database` or `schema`")
 	}
-	if err := validatePrivileges(d); err != nil {
+	if isApplyToAllExisting(d) && (objectType == "database" || objectType == "schema" || objectType == "column" || objectType == "type") {
+		return fmt.Errorf("cannot specify `apply_to_all_existing` when `object_type` is `database`, `schema`, `column` or `type`")
+	}
+	if objectType == "type" && d.Get("objects").(*schema.Set).Len() == 0 {
+		// There is no GRANT ... ON ALL TYPES IN SCHEMA
+		return fmt.Errorf("at least one type in `objects` is required when `object_type` is `type`")
+	}
+	if objectType == "column" {
+		if d.Get("objects").(*schema.Set).Len() != 1 || d.Get("columns").(*schema.Set).Len() == 0 {
+			return fmt.Errorf("one table in `objects` and at least one column in `columns` are required when `object_type` is `column`")
+		}
+	} else if d.Get("columns").(*schema.Set).Len() > 0 {
+		return fmt.Errorf("cannot specify `columns` when `object_type` is not `column`")
+	}
+	if template := d.Get("copy_from_role").(string); template != "" {
+		if objectType == "column" || isApplyToAllExisting(d) {
+			return fmt.Errorf("cannot specify `copy_from_role` when `object_type` is `column` or with `apply_to_all_existing`")
+		}
+		if sliceContainsStr(roles, template) {
+			return fmt.Errorf("cannot copy the privileges of role %s to itself", template)
+		}
+	}
+	if err := validatePrivileges(db, d); err != nil {
 		return err
 	}
 
-	database := d.Get("database").(string)
+	database := getDatabase(d, db.client.databaseName)
+	d.Set("database", database)
 
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	// The reconcile mode applies to the configured privileges from now on,
+	// the ones applied before are still returned by GetChange (see revokeRemovedRolePrivileges).
+	_ = d.Set("configured_privileges", d.Get("privileges"))
 
-	owners, err := getRolesToGrant(txn, d)
-	if err != nil {
-		return err
-	}
-	if err := withRolesGranted(txn, owners, func() error {
-		// Revoke all privileges before granting otherwise reducing privileges will not work.
-		// We just have to revoke them in the same transaction so the role will not lost its
-		// privileges between the revoke and grant statements.
-		if err := revokeRolePrivileges(txn, d); err != nil {
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
+
+		if err := setCopiedPrivileges(db, txn, d); err != nil {
 			return err
 		}
-		if err := grantRolePrivileges(txn, d); err != nil {
+
+		owners, err := getRolesToGrant(txn, d)
+		if err != nil {
 			return err
 		}
-		return nil
+		return withRolesGranted(txn, owners, func() error {
+			if err := revokeRemovedRoles(txn, d); err != nil {
+				return err
+			}
+
+			if isPartialGrant(d) || (isApplyToAllExisting(d) && !isRevokeAllGrant(d)) {
+				// Only revoke the privileges removed from the configuration.
+				if err := revokeRemovedRolePrivileges(txn, d); err != nil {
+					return err
+				}
+			} else {
+				// Revoke all privileges before granting otherwise reducing privileges will not work.
+				// We just have to revoke them in the same transaction so the role will not lost its
+				// privileges between the revoke and grant statements.
+				if err := revokeRolePrivileges(txn, d, roles); err != nil {
+					return err
+				}
+			}
+			return grantRolePrivileges(txn, d)
+		})
 	}); err != nil {
 		return err
 	}
-
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
-	}
+	invalidateObjectsPrivileges(db.client, d)
 
 	d.SetId(generateGrantID(d))
 
-	txn, err = startTransaction(db.client, database)
+	txn, err := startTransaction(db.client, database)
 	if err != nil {
 		return err
 	}
 	defer deferredRollback(txn)
 
-	return readRolePrivileges(txn, d)
+	return readRolePrivileges(db, txn, d)
 }
 
 func resourcePostgreSQLGrantDelete(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featurePrivileges) {
-		return fmt.Errorf(
-			"postgresql_grant resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+		return unsupportedVersionError(db, "postgresql_grant resource")
 	}
 
-	txn, err := startTransaction(db.client, d.Get("database").(string))
-	if err != nil {
-		return err
+	if !d.Get("revoke_on_destroy").(bool) {
+		log.Printf("[DEBUG] revoke_on_destroy is false, keeping the privileges of role %s", strings.Join(granteeRoles(d), ", "))
+		return nil
 	}
-	defer deferredRollback(txn)
 
-	owners, err := getRolesToGrant(txn, d)
-	if err != nil {
-		return err
+	if len(revokedPrivileges(d)) == 0 {
+		// Nothing was granted by the resource in privileges_managed mode
+		log.Printf("[DEBUG] no privileges of role %s to revoke", strings.Join(granteeRoles(d), ", "))
+		return nil
 	}
 
-	if err := withRolesGranted(txn, owners, func() error {
-		return revokeRolePrivileges(txn, d)
+	if err := withTransaction(db.client, d.Get("database").(string), func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
+
+		owners, err := getRolesToGrant(txn, d)
+		if err != nil {
+			return err
+		}
+
+		return withRolesGranted(txn, owners, func() error {
+			// Owners implicitly have all privileges on their objects so we must not
+			// revoke anything on objects owned by the grantee, otherwise it could lose
+			// access to its own objects after a destroy.
+			for _, role := range granteeRoles(d) {
+				if err := revokeRolePrivilegesExceptOwned(txn, d, role); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	}); err != nil {
 		return err
 	}
-
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
-	}
+	invalidateObjectsPrivileges(db.client, d)
 
 	return nil
 }
 
-func readDatabaseRolePriviges(txn *sql.Tx, d *schema.ResourceData, roleOID int) error {
-	dbName := d.Get("database").(string)
-	query := `
+// databasePrivilegesQuery returns the privileges of the role OID $2 on the database $1.
+const databasePrivilegesQuery = `
+SELECT array_agg(privilege_type)
+FROM (
+	SELECT (aclexplode(COALESCE(datacl, acldefault('d', datdba)))).* FROM pg_database WHERE datname=$1
+) as privileges
+WHERE grantee = $2
+`
+
+// schemaPrivilegesQuery returns the privileges of the role OID $2 on the schema $1.
+const schemaPrivilegesQuery = `
 SELECT array_agg(privilege_type)
 FROM (
-	SELECT (aclexplode(datacl)).* FROM pg_database WHERE datname=$1
+	SELECT (aclexplode(COALESCE(nspacl, acldefault('n', nspowner)))).* FROM pg_namespace WHERE nspname=$1
 ) as privileges
 WHERE grantee = $2
 `
 
+// readDatabaseRolePriviges reads the privileges of the role on the database.
+// A NULL datacl means the default privileges apply (e.g.: CONNECT and TEMPORARY for PUBLIC),
+// so we use acldefault to be able to correctly detect drifts for PUBLIC.
+func readDatabaseRolePriviges(db *DBConnection, txn *sql.Tx, d *schema.ResourceData, roleOID int) error {
+	dbName := d.Get("database").(string)
+
 	var privileges pq.ByteaArray
-	if err := txn.QueryRow(query, dbName, roleOID).Scan(&privileges); err != nil {
+	if err := txn.QueryRow(databasePrivilegesQuery, dbName, roleOID).Scan(&privileges); err != nil {
 		return fmt.Errorf("could not read privileges for database %s: %w", dbName, err)
 	}
 
-	_ = d.Set("privileges", pgArrayToSet(privileges))
+	direct, copied := splitCopiedPrivileges(d, pgArrayToSet(privileges))
+	_ = d.Set("privileges", reconcilePrivileges(db, d, direct))
+	_ = d.Set("copied_privileges", copied)
 	return nil
 }
 
-func readSchemaRolePriviges(txn *sql.Tx, d *schema.ResourceData, roleOID int) error {
+// readSchemaRolePriviges reads the privileges of the role on the schema.
+// Like for databases, a NULL nspacl means the default privileges apply.
+func readSchemaRolePriviges(db *DBConnection, txn *sql.Tx, d *schema.ResourceData, roleOID int) error {
 	dbName := d.Get("schema").(string)
-	query := `
-SELECT array_agg(privilege_type)
-FROM (
-	SELECT (aclexplode(nspacl)).* FROM pg_namespace WHERE nspname=$1
-) as privileges
-WHERE grantee = $2
-`
 
 	var privileges pq.ByteaArray
-	if err := txn.QueryRow(query, dbName, roleOID).Scan(&privileges); err != nil {
+	if err := txn.QueryRow(schemaPrivilegesQuery, dbName, roleOID).Scan(&privileges); err != nil {
 		return fmt.Errorf("could not read privileges for schema %s: %w", dbName, err)
 	}
 
-	_ = d.Set("privileges", pgArrayToSet(privileges))
+	direct, copied := splitCopiedPrivileges(d, pgArrayToSet(privileges))
+	_ = d.Set("privileges", reconcilePrivileges(db, d, direct))
+	_ = d.Set("copied_privileges", copied)
+	return nil
+}
+
+// columnPrivilegesTypes are the privileges which can be granted on columns.
+var columnPrivilegesTypes = []string{"SELECT", "INSERT", "UPDATE", "REFERENCES"}
+
+// readColumnsRolePrivileges reads the privileges of the role on the columns of the table.
+// Only the privileges directly granted on all the columns (in pg_attribute.attacl) are saved in the state,
+// the effective privileges (e.g.: inherited from another role or granted on the whole table)
+// can exceed them but are not managed by the resource so they must not cause any diff.
+func readColumnsRolePrivileges(db *DBConnection, txn *sql.Tx, d *schema.ResourceData, role string, roleOID int) error {
+	pgSchema := d.Get("schema").(string)
+	table := d.Get("objects").(*schema.Set).List()[0].(string)
+	columns := d.Get("columns").(*schema.Set)
+
+	columnNames := make([]string, 0, columns.Len())
+	for _, column := range columns.List() {
+		columnNames = append(columnNames, column.(string))
+	}
+
+	query := `
+SELECT a.attname,
+	ARRAY(SELECT privilege_type FROM aclexplode(a.attacl) WHERE grantee = $4),
+	ARRAY(SELECT p FROM unnest($5::text[]) AS p WHERE has_column_privilege($6, a.attrelid, a.attnum, p))
+FROM pg_catalog.pg_attribute a
+JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND c.relname = $2 AND a.attname = ANY($3) AND NOT a.attisdropped
+`
+	rows, err := txn.Query(
+		query, pgSchema, table, pq.Array(columnNames), roleOID,
+		pq.Array(columnPrivilegesTypes), role,
+	)
+	if err != nil {
+		return fmt.Errorf("could not read privileges of role %s on columns of table %s: %w", role, table, err)
+	}
+	defer rows.Close()
+
+	var direct, effective *schema.Set
+	found := 0
+	for rows.Next() {
+		var column string
+		var columnDirect, columnEffective pq.ByteaArray
+		if err := rows.Scan(&column, &columnDirect, &columnEffective); err != nil {
+			return fmt.Errorf("could not scan privileges of column %s: %w", column, err)
+		}
+		found++
+
+		// A privilege is granted only if it's granted on all the columns.
+		if direct == nil {
+			direct, effective = pgArrayToSet(columnDirect), pgArrayToSet(columnEffective)
+		} else {
+			direct = direct.Intersection(pgArrayToSet(columnDirect))
+			effective = effective.Intersection(pgArrayToSet(columnEffective))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if found < columns.Len() {
+		// Some columns don't exist (anymore), the privileges have to be granted again.
+		log.Printf("[DEBUG] some columns of %v do not exist in table %s.%s", columns.List(), pgSchema, table)
+		direct = schema.NewSet(schema.HashString, nil)
+	} else if effective.Difference(direct).Len() > 0 {
+		log.Printf(
+			"[DEBUG] role %s has the effective privileges %v on columns %v of table %s.%s, "+
+				"which exceed the directly granted ones %v (e.g.: through role inheritance or table privileges)",
+			role, effective.List(), columns.List(), pgSchema, table, direct.List(),
+		)
+	}
+
+	_ = d.Set("privileges", reconcilePrivileges(db, d, direct))
 	return nil
 }
 
-func readRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
-	role := d.Get("role").(string)
+// columnsPrivilegesList builds the privilege list of a GRANT/REVOKE on columns
+// (e.g.: SELECT ("col1","col2"),UPDATE ("col1","col2")).
+func columnsPrivilegesList(privileges []string, columns *schema.Set) string {
+	quotedColumns := make([]string, 0, columns.Len())
+	for _, column := range columns.List() {
+		quotedColumns = append(quotedColumns, pq.QuoteIdentifier(column.(string)))
+	}
+	columnsList := strings.Join(quotedColumns, ",")
+
+	list := make([]string, len(privileges))
+	for i, privilege := range privileges {
+		list[i] = fmt.Sprintf("%s (%s)", privilege, columnsList)
+	}
+	return strings.Join(list, ",")
+}
+
+// readRolePrivileges reads the privileges of each role of the grant.
+// The roles are expected to have the same privileges, so the first role whose privileges differ
+// from the state (e.g.: one role lost a privilege but not the others) sets them, to force an update.
+func readRolePrivileges(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+	if isApplyToAllExisting(d) {
+		// The privileges are granted once on the existing objects,
+		// the objects created afterwards are not expected to have them.
+		log.Printf(
+			"[DEBUG] %s privileges of role %s applied to all existing objects, not reading them",
+			d.Get("object_type"), strings.Join(granteeRoles(d), ", "),
+		)
+		return nil
+	}
+
+	expected := d.Get("privileges").(*schema.Set)
+	expectedCopied := d.Get("copied_privileges").(*schema.Set)
+	for _, role := range granteeRoles(d) {
+		if err := readGranteePrivileges(db, txn, d, role); err != nil {
+			return err
+		}
+		if !sameStringSets(expected, d.Get("privileges").(*schema.Set)) ||
+			!sameStringSets(expectedCopied, d.Get("copied_privileges").(*schema.Set)) {
+			log.Printf("[DEBUG] role %s has not the expected privileges, the grant will be updated", role)
+			break
+		}
+	}
+	return nil
+}
+
+// readGranteePrivileges reads the privileges granted to the role itself, from the ACL of the objects
+// (aclexplode of relacl, proacl, typacl, nspacl, datacl, srvacl or fdwacl) and never from the has_*_privilege functions:
+// the privileges the role inherits from its group roles are not managed by the resource and must not cause any diff.
+func readGranteePrivileges(db *DBConnection, txn *sql.Tx, d *schema.ResourceData, role string) error {
 	objectType := d.Get("object_type").(string)
 	objects := d.Get("objects").(*schema.Set)
 
@@ -257,98 +857,234 @@ func readRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		return err
 	}
 
-	var query string
-	var rows *sql.Rows
-
 	switch objectType {
 	case "database":
-		return readDatabaseRolePriviges(txn, d, roleOID)
+		return readDatabaseRolePriviges(db, txn, d, roleOID)
 
 	case "schema":
-		return readSchemaRolePriviges(txn, d, roleOID)
+		return readSchemaRolePriviges(db, txn, d, roleOID)
+
+	case "column":
+		return readColumnsRolePrivileges(db, txn, d, role, roleOID)
+	}
 
+	// This returns the list of all object of the specified type in the specified schema
+	// with the privileges currently granted to each role.
+	//
+	// Our goal is to check that every object has the same privileges as saved in the state.
+	objectsPrivileges, err := getObjectsPrivileges(
+		db.client, txn, d.Get("database").(string), d.Get("schema").(string), objectType,
+	)
+	if err != nil {
+		return err
+	}
+
+	for objName, rolesPrivileges := range objectsPrivileges {
+		if objects.Len() > 0 && !objects.Contains(objName) {
+			continue
+		}
+
+		privileges := rolesPrivileges[roleOID]
+		direct, copied := splitCopiedPrivileges(d, pgArrayToSet(privileges))
+		privilegesSet := reconcilePrivileges(db, d, direct)
+
+		if !privilegesSet.Equal(d.Get("privileges").(*schema.Set)) || !sameStringSets(copied, d.Get("copied_privileges").(*schema.Set)) {
+			// If any object doesn't have the same privileges as saved in the state,
+			// we return its privileges to force an update.
+			log.Printf(
+				"[DEBUG] %s %s has not the expected privileges %v for role %s",
+				strings.ToTitle(objectType), objName, privileges, role,
+			)
+			_ = d.Set("privileges", privilegesSet)
+			_ = d.Set("copied_privileges", copied)
+			break
+		}
+	}
+
+	return nil
+}
+
+// objectsPrivileges maps the name of each object of a schema
+// to the privileges granted on it for each role OID.
+type objectsPrivileges map[string]map[int]pq.ByteaArray
+
+// objectsPrivilegesKey identifies the objects of a type in a schema, whose privileges are cached.
+type objectsPrivilegesKey struct {
+	database   string
+	schemaName string
+	objectType string
+}
+
+// cachedObjectsPrivileges holds the privileges of the objects of a key, locked while they are read
+// so the resources reading the same objects wait for them without blocking the other keys.
+type cachedObjectsPrivileges struct {
+	sync.Mutex
+	privileges objectsPrivileges
+}
+
+// getObjectsPrivileges returns the privileges of all objects of the specified type in the schema.
+// The result is cached in the client so schemas with a lot of objects are enumerated only once
+// for all the grant resources targeting them, the cache is invalidated when the objects or their
+// privileges are changed by a resource (see invalidateObjectsPrivileges).
+func getObjectsPrivileges(client *Client, txn *sql.Tx, database, schemaName, objectType string) (objectsPrivileges, error) {
+	key := objectsPrivilegesKey{database: database, schemaName: schemaName, objectType: objectType}
+
+	client.objectsPrivilegesLock.Lock()
+	cached, ok := client.objectsPrivilegesCache[key]
+	if !ok {
+		cached = &cachedObjectsPrivileges{}
+		client.objectsPrivilegesCache[key] = cached
+	}
+	client.objectsPrivilegesLock.Unlock()
+
+	cached.Lock()
+	defer cached.Unlock()
+
+	if cached.privileges != nil {
+		log.Printf("[DEBUG] using cached privileges for %ss of schema %s in database %s", objectType, schemaName, database)
+		return cached.privileges, nil
+	}
+
+	var query string
+	var rows *sql.Rows
+	var err error
+
+	switch objectType {
 	case "function":
 		query = `
-SELECT pg_proc.proname, array_remove(array_agg(privilege_type), NULL)
-FROM pg_proc
-JOIN pg_namespace ON pg_namespace.oid = pg_proc.pronamespace
+SELECT pg_proc.proname, privs.grantee, privs.privilege_type
+FROM pg_proc
+JOIN pg_namespace ON pg_namespace.oid = pg_proc.pronamespace
+LEFT JOIN (
+    SELECT oid, (aclexplode(proacl)).* FROM pg_proc
+) privs
+ON privs.oid = pg_proc.oid
+WHERE nspname = $1
+`
+		rows, err = txn.Query(query, schemaName)
+
+	case "type":
+		// The row types of the relations (other than composite types) cannot be granted
+		query = `
+SELECT pg_type.typname, privs.grantee, privs.privilege_type
+FROM pg_type
+JOIN pg_namespace ON pg_namespace.oid = pg_type.typnamespace
+LEFT JOIN pg_class ON pg_class.oid = pg_type.typrelid
 LEFT JOIN (
-    select acls.*
-    from (
-             SELECT proname, pronamespace, (aclexplode(proacl)).* FROM pg_proc
-         ) acls
-    WHERE grantee = $1
+    SELECT oid, (aclexplode(typacl)).* FROM pg_type
 ) privs
-USING (proname, pronamespace)
-      WHERE nspname = $2
-GROUP BY pg_proc.proname
+ON privs.oid = pg_type.oid
+WHERE nspname = $1 AND (pg_type.typrelid = 0 OR pg_class.relkind = 'c')
 `
-		rows, err = txn.Query(
-			query, roleOID, d.Get("schema"),
-		)
+		rows, err = txn.Query(query, schemaName)
+
+	case "sequence":
+		// Until their ACL is first changed (NULL relacl), the owner of a sequence has the implicit
+		// sequence privileges (USAGE, SELECT and UPDATE, not the ones of a table).
+		query = `
+SELECT pg_class.relname, privs.grantee, privs.privilege_type
+FROM pg_class
+JOIN pg_namespace ON pg_namespace.oid = pg_class.relnamespace
+LEFT JOIN LATERAL aclexplode(COALESCE(relacl, acldefault('s', relowner))) privs ON true
+WHERE nspname = $1 AND relkind = 'S'
+`
+		rows, err = txn.Query(query, schemaName)
+
+	case "foreign_server":
+		query = `
+SELECT srvname, privs.grantee, privs.privilege_type
+FROM pg_foreign_server
+LEFT JOIN LATERAL aclexplode(srvacl) privs ON true
+`
+		rows, err = txn.Query(query)
+
+	case "foreign_data_wrapper":
+		query = `
+SELECT fdwname, privs.grantee, privs.privilege_type
+FROM pg_foreign_data_wrapper
+LEFT JOIN LATERAL aclexplode(fdwacl) privs ON true
+`
+		rows, err = txn.Query(query)
 
 	default:
 		query = `
-SELECT pg_class.relname, array_remove(array_agg(privilege_type), NULL)
+SELECT pg_class.relname, privs.grantee, privs.privilege_type
 FROM pg_class
 JOIN pg_namespace ON pg_namespace.oid = pg_class.relnamespace
 LEFT JOIN (
-    SELECT acls.* FROM (
-        SELECT relname, relnamespace, relkind, (aclexplode(relacl)).* FROM pg_class c
-    ) as acls
-    WHERE grantee=$1
+    SELECT oid, (aclexplode(relacl)).* FROM pg_class
 ) privs
-USING (relname, relnamespace, relkind)
-WHERE nspname = $2 AND relkind = $3
-GROUP BY pg_class.relname
+ON privs.oid = pg_class.oid
+WHERE nspname = $1 AND relkind = $2
 `
-		rows, err = txn.Query(
-			query, roleOID, d.Get("schema"), objectTypes[objectType],
-		)
+		rows, err = txn.Query(query, schemaName, objectTypes[objectType])
 	}
-
-	defer rows.Close()
-
-	// This returns, for the specified role (rolname),
-	// the list of all object of the specified type (relkind) in the specified schema (namespace)
-	// with the list of the currently applied privileges (aggregation of privilege_type)
-	//
-	// Our goal is to check that every object has the same privileges as saved in the state.
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("could not read privileges of %ss in schema %s: %w", objectType, schemaName, err)
 	}
+	defer rows.Close()
 
+	result := objectsPrivileges{}
 	for rows.Next() {
 		var objName string
-		var privileges pq.ByteaArray
+		var grantee sql.NullInt64
+		var privilege sql.NullString
 
-		if err := rows.Scan(&objName, &privileges); err != nil {
-			return err
+		if err := rows.Scan(&objName, &grantee, &privilege); err != nil {
+			return nil, err
 		}
 
-		if objects.Len() > 0 && !objects.Contains(objName) {
-			continue
+		if _, ok := result[objName]; !ok {
+			result[objName] = map[int]pq.ByteaArray{}
 		}
-
-		privilegesSet := pgArrayToSet(privileges)
-
-		if !privilegesSet.Equal(d.Get("privileges").(*schema.Set)) {
-			// If any object doesn't have the same privileges as saved in the state,
-			// we return its privileges to force an update.
-			log.Printf(
-				"[DEBUG] %s %s has not the expected privileges %v for role %s",
-				strings.ToTitle(objectType), objName, privileges, d.Get("role"),
+		// Objects without any privilege are still listed (with a NULL grantee)
+		if grantee.Valid && privilege.Valid {
+			result[objName][int(grantee.Int64)] = append(
+				result[objName][int(grantee.Int64)], []byte(privilege.String),
 			)
-			_ = d.Set("privileges", privilegesSet)
-			break
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return nil
+	cached.privileges = result
+	return result, nil
+}
+
+// invalidateObjectsPrivileges removes the cached privileges of the objects targeted by the grant.
+func invalidateObjectsPrivileges(client *Client, d *schema.ResourceData) {
+	invalidateCachedObjectsPrivileges(
+		client, d.Get("database").(string), d.Get("schema").(string), d.Get("object_type").(string),
+	)
+}
+
+// invalidateCachedObjectsPrivileges removes the cached privileges of the objects of the type in the schema
+// of the database. An empty schema or object type matches all of them (e.g.: after statements
+// which may create, drop or change the privileges of any object of the database).
+// Being removed from the cache, the privileges being read meanwhile are not used afterwards.
+func invalidateCachedObjectsPrivileges(client *Client, database, schemaName, objectType string) {
+	client.objectsPrivilegesLock.Lock()
+	defer client.objectsPrivilegesLock.Unlock()
+
+	for key := range client.objectsPrivilegesCache {
+		if key.database == database &&
+			(schemaName == "" || key.schemaName == schemaName) &&
+			(objectType == "" || key.objectType == objectType) {
+			delete(client.objectsPrivilegesCache, key)
+		}
+	}
 }
 
 func createGrantQuery(d *schema.ResourceData, privileges []string) string {
+	return createGrantQueryForObjects(d, granteeRoles(d), privileges, d.Get("objects").(*schema.Set))
+}
+
+// createGrantQueryForObjects builds the grant query to the roles for the specified objects
+// (empty means all objects of the requested type in the schema).
+func createGrantQueryForObjects(d *schema.ResourceData, roles, privileges []string, objects *schema.Set) string {
 	var query string
+	grantees := quotedGrantees(roles)
 
 	switch strings.ToUpper(d.Get("object_type").(string)) {
 	case "DATABASE":
@@ -356,24 +1092,38 @@ func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 			"GRANT %s ON DATABASE %s TO %s",
 			strings.Join(privileges, ","),
 			pq.QuoteIdentifier(d.Get("database").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			grantees,
 		)
 	case "SCHEMA":
 		query = fmt.Sprintf(
 			"GRANT %s ON SCHEMA %s TO %s",
 			strings.Join(privileges, ","),
 			pq.QuoteIdentifier(d.Get("schema").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			grantees,
+		)
+	case "FOREIGN_SERVER", "FOREIGN_DATA_WRAPPER":
+		query = fmt.Sprintf(
+			"GRANT %s ON %s %s TO %s",
+			strings.Join(privileges, ","),
+			foreignObjectTypes[strings.ToLower(d.Get("object_type").(string))],
+			setToQuotedList(objects),
+			grantees,
 		)
-	case "TABLE", "SEQUENCE", "FUNCTION":
-		objects := d.Get("objects").(*schema.Set)
+	case "COLUMN":
+		query = fmt.Sprintf(
+			"GRANT %s ON TABLE %s TO %s",
+			columnsPrivilegesList(privileges, d.Get("columns").(*schema.Set)),
+			setToPgIdentList(d.Get("schema").(string), objects),
+			grantees,
+		)
+	case "TABLE", "SEQUENCE", "FUNCTION", "TYPE":
 		if objects.Len() > 0 {
 			query = fmt.Sprintf(
 				"GRANT %s ON %s %s TO %s",
 				strings.Join(privileges, ","),
 				strings.ToUpper(d.Get("object_type").(string)),
 				setToPgIdentList(d.Get("schema").(string), objects),
-				pq.QuoteIdentifier(d.Get("role").(string)),
+				grantees,
 			)
 		} else {
 			query = fmt.Sprintf(
@@ -381,7 +1131,7 @@ func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 				strings.Join(privileges, ","),
 				strings.ToUpper(d.Get("object_type").(string)),
 				pq.QuoteIdentifier(d.Get("schema").(string)),
-				pq.QuoteIdentifier(d.Get("role").(string)),
+				grantees,
 			)
 		}
 	}
@@ -394,51 +1144,85 @@ func createGrantQuery(d *schema.ResourceData, privileges []string) string {
 }
 
 func createRevokeQuery(d *schema.ResourceData) string {
+	return createRevokeQueryForObjects(d, granteeRoles(d), d.Get("objects").(*schema.Set))
+}
+
+// createRevokeQueryForObjects builds the revoke query from the roles for the specified objects
+// (empty means all objects of the requested type in the schema).
+func createRevokeQueryForObjects(d *schema.ResourceData, roles []string, objects *schema.Set) string {
+	return createRevokeQueryForPrivileges(d, roles, revokedPrivileges(d), objects)
+}
+
+// createRevokeQueryForPrivileges builds the query to revoke the privileges from the roles on the specified objects.
+func createRevokeQueryForPrivileges(d *schema.ResourceData, roles, privileges []string, objects *schema.Set) string {
 	var query string
+	grantees := quotedGrantees(roles)
 
 	switch strings.ToUpper(d.Get("object_type").(string)) {
 	case "DATABASE":
 		query = fmt.Sprintf(
-			"REVOKE ALL PRIVILEGES ON DATABASE %s FROM %s",
+			"REVOKE %s ON DATABASE %s FROM %s",
+			strings.Join(privileges, ","),
 			pq.QuoteIdentifier(d.Get("database").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			grantees,
 		)
 	case "SCHEMA":
 		query = fmt.Sprintf(
-			"REVOKE ALL PRIVILEGES ON SCHEMA %s FROM %s",
+			"REVOKE %s ON SCHEMA %s FROM %s",
+			strings.Join(privileges, ","),
 			pq.QuoteIdentifier(d.Get("schema").(string)),
-			pq.QuoteIdentifier(d.Get("role").(string)),
+			grantees,
 		)
-	case "TABLE", "SEQUENCE", "FUNCTION":
-		objects := d.Get("objects").(*schema.Set)
+	case "FOREIGN_SERVER", "FOREIGN_DATA_WRAPPER":
+		query = fmt.Sprintf(
+			"REVOKE %s ON %s %s FROM %s",
+			strings.Join(privileges, ","),
+			foreignObjectTypes[strings.ToLower(d.Get("object_type").(string))],
+			setToQuotedList(objects),
+			grantees,
+		)
+	case "COLUMN":
+		query = fmt.Sprintf(
+			"REVOKE %s ON TABLE %s FROM %s",
+			columnsPrivilegesList(privileges, d.Get("columns").(*schema.Set)),
+			setToPgIdentList(d.Get("schema").(string), objects),
+			grantees,
+		)
+	case "TABLE", "SEQUENCE", "FUNCTION", "TYPE":
 		if objects.Len() > 0 {
 			query = fmt.Sprintf(
-				"REVOKE ALL PRIVILEGES ON %s %s FROM %s",
+				"REVOKE %s ON %s %s FROM %s",
+				strings.Join(privileges, ","),
 				strings.ToUpper(d.Get("object_type").(string)),
 				setToPgIdentList(d.Get("schema").(string), objects),
-				pq.QuoteIdentifier(d.Get("role").(string)),
+				grantees,
 			)
 		} else {
 			query = fmt.Sprintf(
-				"REVOKE ALL PRIVILEGES ON ALL %sS IN SCHEMA %s FROM %s",
+				"REVOKE %s ON ALL %sS IN SCHEMA %s FROM %s",
+				strings.Join(privileges, ","),
 				strings.ToUpper(d.Get("object_type").(string)),
 				pq.QuoteIdentifier(d.Get("schema").(string)),
-				pq.QuoteIdentifier(d.Get("role").(string)),
+				grantees,
 			)
 		}
 	}
 
+	if d.Get("revoke_cascade").(bool) {
+		query += " CASCADE"
+	}
+
 	return query
 }
 
 func grantRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	privileges := []string{}
-	for _, priv := range d.Get("privileges").(*schema.Set).List() {
+	for _, priv := range grantedPrivileges(d).List() {
 		privileges = append(privileges, priv.(string))
 	}
 
 	if len(privileges) == 0 {
-		log.Printf("[DEBUG] no privileges to grant for role %s in database: %s,", d.Get("role").(string), d.Get("database"))
+		log.Printf("[DEBUG] no privileges to grant for role %s in database: %s,", strings.Join(granteeRoles(d), ", "), d.Get("database"))
 		return nil
 	}
 
@@ -448,10 +1232,412 @@ func grantRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	return err
 }
 
-func revokeRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
-	query := createRevokeQuery(d)
+// isPartialGrant returns true if only the configured privileges are granted and revoked,
+// the other privileges of the role being left untouched: in privileges_managed mode
+// and in additive mode, unless the privileges list is empty.
+func isPartialGrant(d *schema.ResourceData) bool {
+	switch d.Get("reconcile_mode").(string) {
+	case grantReconcileAdditive:
+		return !isRevokeAllGrant(d)
+	case grantReconcilePrivilegesManaged:
+		return true
+	}
+	return false
+}
+
+// isApplyToAllExisting returns true if the privileges are granted once on all the existing objects
+// of the schema, instead of being reconciled per object.
+func isApplyToAllExisting(d *schema.ResourceData) bool {
+	return d.Get("apply_to_all_existing").(bool)
+}
+
+// isRevokeAllGrant returns true if the privileges list is empty (and nothing is copied from a template role),
+// which means the role must not have any privilege on the objects, whatever the reconcile mode.
+func isRevokeAllGrant(d *schema.ResourceData) bool {
+	return grantedPrivileges(d).Len() == 0
+}
+
+// configuredPrivileges returns the privileges of the configuration: once the grant is created,
+// the ones saved in configured_privileges, as the privileges read in the state may only be a part of them
+// (e.g.: in additive mode, when some of them were revoked outside of Terraform).
+func configuredPrivileges(d *schema.ResourceData) *schema.Set {
+	if d.Id() == "" {
+		return d.Get("privileges").(*schema.Set)
+	}
+	return d.Get("configured_privileges").(*schema.Set)
+}
+
+// grantedPrivileges returns the privileges granted to the role:
+// the configured ones and the ones copied from the template role.
+func grantedPrivileges(d *schema.ResourceData) *schema.Set {
+	return configuredPrivileges(d).Union(d.Get("copied_privileges").(*schema.Set))
+}
+
+// revokedPrivileges returns the privileges to revoke when removing the grant:
+// all of them in exclusive mode (or with an empty privileges list in additive mode)
+// but only the granted ones otherwise (which may be none in privileges_managed mode).
+func revokedPrivileges(d *schema.ResourceData) []string {
+	if !isPartialGrant(d) {
+		return []string{"ALL PRIVILEGES"}
+	}
+
+	privileges := []string{}
+	for _, priv := range grantedPrivileges(d).List() {
+		privileges = append(privileges, priv.(string))
+	}
+	return privileges
+}
+
+// reconcilePrivileges returns the privileges to save in the state from the actual ones.
+// In additive and privileges_managed modes, the privileges which are not configured are ignored
+// (unless the privileges list is empty in additive mode, then any privilege is a drift).
+func reconcilePrivileges(db *DBConnection, d *schema.ResourceData, privileges *schema.Set) *schema.Set {
+	desired := configuredPrivileges(d)
+	privileges = normalizeAllPrivileges(db, d, privileges)
+
+	reconciled := privileges
+	if isPartialGrant(d) {
+		reconciled = privileges.Intersection(desired)
+	}
+
+	log.Printf(
+		"[DEBUG] privileges of role %s on %s: actual %v, reconciled %v, desired %v (%s mode)",
+		strings.Join(granteeRoles(d), ", "), d.Get("object_type"), privileges.List(), reconciled.List(), desired.List(), d.Get("reconcile_mode"),
+	)
+	return reconciled
+}
+
+// normalizeAllPrivileges replaces the privileges implied by ALL with ALL if it is configured,
+// so granting ALL does not plan a change to the list of privileges it actually grants.
+func normalizeAllPrivileges(db *DBConnection, d *schema.ResourceData, privileges *schema.Set) *schema.Set {
+	if !configuredPrivileges(d).Contains("ALL") {
+		return privileges
+	}
+
+	all := allPrivileges(db, d.Get("object_type").(string))
+	for _, priv := range all {
+		if !privileges.Contains(priv) {
+			return privileges
+		}
+	}
+
+	normalized := schema.NewSet(schema.HashString, []interface{}{"ALL"})
+	for _, priv := range privileges.List() {
+		if !sliceContainsStr(all, priv.(string)) {
+			normalized.Add(priv)
+		}
+	}
+	return normalized
+}
+
+// splitCopiedPrivileges splits the actual privileges of the role between the ones copied from the template role
+// (as saved in the state) and the other ones, which are compared with the configured privileges.
+// The copied privileges which are configured too are kept in both.
+func splitCopiedPrivileges(d *schema.ResourceData, privileges *schema.Set) (direct, copied *schema.Set) {
+	configured := configuredPrivileges(d)
+	copiedState := d.Get("copied_privileges").(*schema.Set)
+
+	copied = privileges.Intersection(copiedState)
+	if configured.Contains("ALL") {
+		// All the privileges are configured anyway
+		return privileges, copied
+	}
+	return privileges.Difference(copiedState.Difference(configured)), copied
+}
+
+// sameStringSets returns true if both sets have the same elements
+// (Set.Equal also compares the internal maps, which differ for empty sets).
+func sameStringSets(a, b *schema.Set) bool {
+	return a.Len() == b.Len() && a.Difference(b).Len() == 0
+}
+
+// setCopiedPrivileges saves in copied_privileges the current privileges of the template role (copy_from_role)
+// before granting them.
+func setCopiedPrivileges(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+	template := d.Get("copy_from_role").(string)
+	if template == "" {
+		return d.Set("copied_privileges", []interface{}{})
+	}
+
+	privileges, err := readTemplatePrivileges(
+		db.client, txn, d.Get("database").(string), d.Get("schema").(string), d.Get("object_type").(string),
+		d.Get("objects").(*schema.Set), template,
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] copying privileges %v of role %s to role %s", privileges.List(), template, strings.Join(granteeRoles(d), ", "))
+	return d.Set("copied_privileges", privileges)
+}
+
+// readTemplatePrivileges returns the privileges the template role has been granted on the objects.
+// For the objects of a schema, only the privileges it has on all of them are returned.
+func readTemplatePrivileges(
+	client *Client, txn *sql.Tx, database, schemaName, objectType string, objects *schema.Set, template string,
+) (*schema.Set, error) {
+	templateOID, err := getRoleOID(txn, template)
+	if err != nil {
+		return nil, err
+	}
+
+	var privileges pq.ByteaArray
+	switch objectType {
+	case "database":
+		if err := txn.QueryRow(databasePrivilegesQuery, database, templateOID).Scan(&privileges); err != nil {
+			return nil, fmt.Errorf("could not read privileges of role %s for database %s: %w", template, database, err)
+		}
+		return pgArrayToSet(privileges), nil
+
+	case "schema":
+		if err := txn.QueryRow(schemaPrivilegesQuery, schemaName, templateOID).Scan(&privileges); err != nil {
+			return nil, fmt.Errorf("could not read privileges of role %s for schema %s: %w", template, schemaName, err)
+		}
+		return pgArrayToSet(privileges), nil
+
+	case "column":
+		return nil, fmt.Errorf("cannot copy the privileges of role %s on columns", template)
+	}
+
+	objectsPrivileges, err := getObjectsPrivileges(client, txn, database, schemaName, objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *schema.Set
+	for objName, rolesPrivileges := range objectsPrivileges {
+		if objects.Len() > 0 && !objects.Contains(objName) {
+			continue
+		}
+
+		objPrivileges := pgArrayToSet(rolesPrivileges[templateOID])
+		if result == nil {
+			result = objPrivileges
+		} else if !sameStringSets(result, objPrivileges) {
+			log.Printf(
+				"[DEBUG] role %s has not the same privileges on all the %ss of schema %s, only the common ones are copied",
+				template, objectType, schemaName,
+			)
+			result = result.Intersection(objPrivileges)
+		}
+	}
+	if result == nil {
+		return schema.NewSet(schema.HashString, nil), nil
+	}
+
+	return result, nil
+}
+
+// revokeRemovedRolePrivileges revokes the privileges removed from the configuration
+// (in additive and privileges_managed modes).
+// They are compared with the configuration applied before and not with the privileges read in the state,
+// which may contain privileges granted outside of Terraform (e.g.: read while the list was empty).
+func revokeRemovedRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+	oldPrivileges, _ := d.GetChange("configured_privileges")
+	oldCopied, newCopied := d.GetChange("copied_privileges")
+	removed := oldPrivileges.(*schema.Set).Union(oldCopied.(*schema.Set)).Difference(
+		d.Get("privileges").(*schema.Set).Union(newCopied.(*schema.Set)),
+	)
+	if removed.Len() == 0 {
+		return nil
+	}
+
+	privileges := []string{}
+	for _, priv := range removed.List() {
+		privileges = append(privileges, priv.(string))
+	}
+
+	query := createRevokeQueryForPrivileges(d, granteeRoles(d), privileges, d.Get("objects").(*schema.Set))
+	if _, err := txn.Exec(query); err != nil {
+		return revokeQueryError(d, err)
+	}
+	return nil
+}
+
+// revokeQueryError returns the error of a revoke query,
+// explaining how to revoke the privileges the role granted to others (RESTRICT by default).
+func revokeQueryError(d *schema.ResourceData, err error) error {
+	if !d.Get("revoke_cascade").(bool) && isDependentObjects(err) {
+		return fmt.Errorf(
+			"could not revoke the privileges of role %s as it granted them to other roles (with its grant option), "+
+				"revoke them first or set revoke_cascade = true to revoke them too: %w",
+			strings.Join(granteeRoles(d), ", "), err,
+		)
+	}
+	return fmt.Errorf("could not execute revoke query: %w", err)
+}
+
+func revokeRolePrivileges(txn *sql.Tx, d *schema.ResourceData, roles []string) error {
+	query := createRevokeQueryForObjects(d, roles, d.Get("objects").(*schema.Set))
+	if _, err := txn.Exec(query); err != nil {
+		return revokeQueryError(d, err)
+	}
+	return nil
+}
+
+// revokeRolePrivilegesExceptOwned revokes the privileges like revokeRolePrivileges
+// but leaves the objects owned by the grantee alone, as the owner implicitly has all privileges on them.
+func revokeRolePrivilegesExceptOwned(txn *sql.Tx, d *schema.ResourceData, role string) error {
+	if role == publicRole {
+		return revokeRolePrivileges(txn, d, []string{role})
+	}
+
+	var owner string
+	var err error
+
+	switch objectType := d.Get("object_type").(string); objectType {
+	case "database":
+		owner, err = getDatabaseOwner(txn, d.Get("database").(string))
+	case "schema":
+		owner, err = getSchemaOwner(txn, d.Get("schema").(string))
+	case "column":
+		// The privileges of the owner are granted at the table level,
+		// revoking the columns privileges does not remove them.
+		return revokeRolePrivileges(txn, d, []string{role})
+	default:
+		return revokeRoleObjectsPrivilegesExceptOwned(txn, d, role)
+	}
+	if err != nil {
+		return err
+	}
+
+	if owner == role {
+		log.Printf("[DEBUG] role %s owns the %s, not revoking its privileges", role, d.Get("object_type"))
+		return nil
+	}
+	return revokeRolePrivileges(txn, d, []string{role})
+}
+
+// tableRelkinds are the kinds of relations covered by the table grants,
+// like GRANT ... ON ALL TABLES IN SCHEMA (tables, views, materialized views,
+// foreign tables and partitioned tables).
+var tableRelkinds = []string{"r", "v", "m", "f", "p"}
+
+// grantObject is an object targeted by a grant, with its quoted identifier
+// (including the arguments of the functions) and its owner.
+type grantObject struct {
+	name, identifier, owner string
+}
+
+// listGrantObjects lists the objects of the type of the grant in its schema, with their owner.
+func listGrantObjects(txn *sql.Tx, d *schema.ResourceData) ([]grantObject, error) {
+	objectType := d.Get("object_type").(string)
+	pgSchema := d.Get("schema").(string)
+
+	var query string
+	var queryArgs []interface{}
+	switch objectType {
+	case "function":
+		// Procedures (PostgreSQL 11+) are not functions, ON ALL FUNCTIONS does not cover them either
+		var versionNum int
+		if err := txn.QueryRow("SELECT current_setting('server_version_num')::int").Scan(&versionNum); err != nil {
+			return nil, fmt.Errorf("could not get the server version: %w", err)
+		}
+		query = `
+SELECT proname, format('%I.%I(%s)', nspname, proname, pg_get_function_identity_arguments(pg_proc.oid)),
+       pg_get_userbyid(proowner)
+FROM pg_proc
+JOIN pg_namespace ON pg_namespace.oid = pronamespace
+WHERE nspname = $1
+`
+		if versionNum >= 110000 {
+			query += "AND prokind <> 'p'\n"
+		}
+		queryArgs = []interface{}{pgSchema}
+	case "type":
+		// Like when reading the privileges, the row types of the relations
+		// (other than composite types) and the array types cannot be granted
+		query = `
+SELECT typname, format('%I.%I', nspname, typname), pg_get_userbyid(typowner)
+FROM pg_type
+JOIN pg_namespace ON pg_namespace.oid = typnamespace
+LEFT JOIN pg_class ON pg_class.oid = pg_type.typrelid
+WHERE nspname = $1 AND (pg_type.typrelid = 0 OR pg_class.relkind = 'c')
+AND NOT EXISTS (SELECT 1 FROM pg_type elem WHERE elem.typarray = pg_type.oid)
+`
+		queryArgs = []interface{}{pgSchema}
+	case "foreign_server":
+		query = "SELECT srvname, quote_ident(srvname), pg_get_userbyid(srvowner) FROM pg_foreign_server"
+	case "foreign_data_wrapper":
+		query = "SELECT fdwname, quote_ident(fdwname), pg_get_userbyid(fdwowner) FROM pg_foreign_data_wrapper"
+	default:
+		relkinds := []string{objectTypes[objectType]}
+		if objectType == "table" {
+			relkinds = tableRelkinds
+		}
+		query = `
+SELECT relname, format('%I.%I', nspname, relname), pg_get_userbyid(relowner)
+FROM pg_class
+JOIN pg_namespace ON pg_namespace.oid = relnamespace
+WHERE nspname = $1 AND relkind::text = ANY($2)
+`
+		queryArgs = []interface{}{pgSchema, pq.Array(relkinds)}
+	}
+
+	rows, err := txn.Query(query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("could not list %ss in schema %s: %w", objectType, pgSchema, err)
+	}
+	defer rows.Close()
+
+	var objects []grantObject
+	for rows.Next() {
+		var object grantObject
+		if err := rows.Scan(&object.name, &object.identifier, &object.owner); err != nil {
+			return nil, fmt.Errorf("could not scan %s: %w", objectType, err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
+
+func revokeRoleObjectsPrivilegesExceptOwned(txn *sql.Tx, d *schema.ResourceData, role string) error {
+	objectType := d.Get("object_type").(string)
+
+	allObjects, err := listGrantObjects(txn, d)
+	if err != nil {
+		return err
+	}
+
+	// The objects are matched by name, a function name covering all its overloads
+	objects := d.Get("objects").(*schema.Set)
+	var notOwned []string
+	owned := 0
+	for _, object := range allObjects {
+		if objects.Len() > 0 && !objects.Contains(object.name) {
+			continue
+		}
+		if object.owner == role {
+			owned++
+			continue
+		}
+		notOwned = append(notOwned, object.identifier)
+	}
+
+	if owned == 0 {
+		return revokeRolePrivileges(txn, d, []string{role})
+	}
+	log.Printf("[DEBUG] role %s owns %d of the targeted %ss, not revoking its privileges on them", role, owned, objectType)
+
+	if len(notOwned) == 0 {
+		return nil
+	}
+	keyword, ok := foreignObjectTypes[objectType]
+	if !ok {
+		keyword = strings.ToUpper(objectType)
+	}
+	query := fmt.Sprintf(
+		"REVOKE %s ON %s %s FROM %s",
+		strings.Join(revokedPrivileges(d), ","),
+		keyword,
+		strings.Join(notOwned, ","),
+		quotedGrantees([]string{role}),
+	)
+	if d.Get("revoke_cascade").(bool) {
+		query += " CASCADE"
+	}
 	if _, err := txn.Exec(query); err != nil {
-		return fmt.Errorf("could not execute revoke query: %w", err)
+		return revokeQueryError(d, err)
 	}
 	return nil
 }
@@ -463,9 +1649,11 @@ func checkRoleDBSchemaExists(client *Client, d *schema.ResourceData) (bool, erro
 	}
 	defer deferredRollback(txn)
 
-	// Check the role exists
-	role := d.Get("role").(string)
-	if role != publicRole {
+	// Check the roles exist
+	for _, role := range granteeRoles(d) {
+		if role == publicRole {
+			continue
+		}
 		exists, err := roleExists(txn, role)
 		if err != nil {
 			return false, err
@@ -514,10 +1702,10 @@ func checkRoleDBSchemaExists(client *Client, d *schema.ResourceData) (bool, erro
 }
 
 func generateGrantID(d *schema.ResourceData) string {
-	parts := []string{d.Get("role").(string), d.Get("database").(string)}
+	parts := []string{strings.Join(granteeRoles(d), ","), d.Get("database").(string)}
 
 	objectType := d.Get("object_type").(string)
-	if objectType != "database" {
+	if _, ok := foreignObjectTypes[objectType]; !ok && objectType != "database" {
 		parts = append(parts, d.Get("schema").(string))
 	}
 	parts = append(parts, objectType)
@@ -525,6 +1713,9 @@ func generateGrantID(d *schema.ResourceData) string {
 	for _, object := range d.Get("objects").(*schema.Set).List() {
 		parts = append(parts, object.(string))
 	}
+	for _, column := range d.Get("columns").(*schema.Set).List() {
+		parts = append(parts, column.(string))
+	}
 
 	return strings.Join(parts, "_")
 }
@@ -536,13 +1727,23 @@ func getRolesToGrant(txn *sql.Tx, d *schema.ResourceData) ([]string, error) {
 	owners := []string{}
 	objectType := d.Get("object_type")
 
-	if objectType == "database" {
+	// Like for the databases, the connected user must own the foreign objects
+	// (or have been granted their privileges with the grant option).
+	if _, ok := foreignObjectTypes[objectType.(string)]; ok || objectType == "database" {
 		return owners, nil
 	}
 
 	schemaName := d.Get("schema").(string)
 
-	if objectType != "schema" {
+	switch objectType {
+	case "schema":
+	case "type":
+		var err error
+		owners, err = getTypesOwner(txn, schemaName)
+		if err != nil {
+			return nil, err
+		}
+	default:
 		var err error
 		owners, err = getTablesOwner(txn, schemaName)
 		if err != nil {
@@ -560,3 +1761,48 @@ func getRolesToGrant(txn *sql.Tx, d *schema.ResourceData) ([]string, error) {
 
 	return owners, nil
 }
+
+// granteeRoles returns the roles the privileges are granted to, from role or (sorted) roles.
+func granteeRoles(d *schema.ResourceData) []string {
+	if role := d.Get("role").(string); role != "" {
+		return []string{role}
+	}
+	return sortedStrings(d.Get("roles").(*schema.Set))
+}
+
+// quotedGrantees returns the roles as the list of grantees of a GRANT or REVOKE.
+func quotedGrantees(roles []string) string {
+	quoted := make([]string, len(roles))
+	for i, role := range roles {
+		quoted[i] = pq.QuoteIdentifier(role)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// revokeRemovedRoles revokes the privileges of the roles removed from roles,
+// except on the objects they own.
+func revokeRemovedRoles(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange("roles") {
+		return nil
+	}
+
+	oldRoles, newRoles := d.GetChange("roles")
+	for _, role := range sortedStrings(oldRoles.(*schema.Set).Difference(newRoles.(*schema.Set))) {
+		if role != publicRole {
+			exists, err := roleExists(txn, role)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				log.Printf("[DEBUG] role %s removed from the grant does not exist anymore", role)
+				continue
+			}
+		}
+
+		log.Printf("[DEBUG] revoking the privileges of role %s removed from the grant", role)
+		if err := revokeRolePrivilegesExceptOwned(txn, d, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,25 +12,48 @@ import (
 )
 
 const (
-	// This returns the role membership for role, grant_role
+	// This returns the role membership for role, grant_role.
+	// Since PostgreSQL 16, a role can be granted several times by different grantors.
 	getGrantRoleQuery = `
 SELECT
   pg_get_userbyid(member) as role,
   pg_get_userbyid(roleid) as grant_role,
-  admin_option
+  bool_or(admin_option)%s
 FROM
   pg_auth_members
 WHERE
   pg_get_userbyid(member) = $1 AND
-  pg_get_userbyid(roleid) = $2;
+  pg_get_userbyid(roleid) = $2%s
+GROUP BY member, roleid;
+`
+
+	// This returns if role $2 is already a member of role $1, directly or through other roles.
+	// Memberships cannot be circular, so $1 cannot be granted to $2 in this case.
+	isMemberOfRoleQuery = `
+WITH RECURSIVE members(oid) AS (
+  SELECT member FROM pg_catalog.pg_auth_members
+  WHERE roleid = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)
+  UNION
+  SELECT m.member FROM pg_catalog.pg_auth_members m JOIN members ON m.roleid = members.oid
+)
+SELECT EXISTS (
+  SELECT 1 FROM members JOIN pg_catalog.pg_roles r ON r.oid = members.oid WHERE r.rolname = $2
+)
 `
 )
 
+const (
+	grantRoleSetOptionAttr     = "set_option"
+	grantRoleInheritOptionAttr = "inherit_option"
+)
+
 func resourcePostgreSQLGrantRole() *schema.Resource {
 	return &schema.Resource{
 		Create: PGResourceFunc(resourcePostgreSQLGrantRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLGrantRoleRead),
-		Delete: PGResourceFunc(resourcePostgreSQLGrantRoleDelete),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLGrantRoleRead)),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLGrantRoleDelete)),
+
+		CustomizeDiff: resourcePostgreSQLGrantRoleCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"role": {
@@ -52,79 +75,148 @@ func resourcePostgreSQLGrantRole() *schema.Resource {
 				Default:     false,
 				Description: "Permit the grant recipient to grant it to others",
 			},
+			grantRoleSetOptionAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: "Permit the grant recipient to SET ROLE to the granted role (PostgreSQL >= 16)",
+			},
+			grantRoleInheritOptionAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+				Description: "Whether the grant recipient inherits the privileges of the granted role (PostgreSQL >= 16). " +
+					"Defaults to the INHERIT attribute of the grant recipient",
+			},
 		},
 	}
 }
 
-func resourcePostgreSQLGrantRoleRead(db *DBConnection, d *schema.ResourceData) error {
-	if !db.featureSupported(featurePrivileges) {
-		return fmt.Errorf(
-			"postgresql_grant_role resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+// resourcePostgreSQLGrantRoleCustomizeDiff checks during the plan that granting the role
+// would not create a circular membership, which PostgreSQL rejects when applying it.
+func resourcePostgreSQLGrantRoleCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// All the attributes are ForceNew, only the new memberships are checked
+	if d.Id() != "" && !d.HasChange("role") && !d.HasChange("grant_role") {
+		return nil
+	}
+	if !d.NewValueKnown("role") || !d.NewValueKnown("grant_role") {
+		return nil
 	}
 
-	return readGrantRole(db, d)
-}
+	role := d.Get("role").(string)
+	grantRole := d.Get("grant_role").(string)
+	if role == grantRole {
+		return fmt.Errorf("role %s cannot be granted to itself", role)
+	}
 
-func resourcePostgreSQLGrantRoleCreate(db *DBConnection, d *schema.ResourceData) error {
-	if !db.featureSupported(featurePrivileges) {
-		return fmt.Errorf(
-			"postgresql_grant_role resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+	client, ok := meta.(*Client)
+	if !ok || client == nil {
+		return nil
 	}
 
-	txn, err := startTransaction(db.client, "")
+	release := client.acquireSlot()
+	defer release()
+
+	// The server may not be reachable yet during the plan (e.g.: it is created in the same apply),
+	// the membership is then only checked by PostgreSQL when it is granted.
+	db, err := client.Connect()
 	if err != nil {
-		return err
+		log.Printf("[WARN] could not check if granting role %s to %s creates a circular membership: %v", grantRole, role, err)
+		return nil
 	}
-	defer deferredRollback(txn)
 
-	// Revoke the granted roles before granting them again.
-	if err = revokeRole(txn, d); err != nil {
-		return err
+	var isMember bool
+	if err := db.QueryRow(isMemberOfRoleQuery, role, grantRole).Scan(&isMember); err != nil {
+		return fmt.Errorf("could not check the memberships of role %s: %w", role, err)
 	}
-
-	if err = grantRole(txn, d); err != nil {
-		return err
+	if isMember {
+		return fmt.Errorf(
+			"role %s cannot be granted to %s: %s is already a member of %s (directly or through other roles), "+
+				"memberships cannot be circular",
+			grantRole, role, grantRole, role,
+		)
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
-	}
+	return nil
+}
 
-	d.SetId(generateGrantRoleID(d))
+func resourcePostgreSQLGrantRoleRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return unsupportedVersionError(db, "postgresql_grant_role resource")
+	}
 
 	return readGrantRole(db, d)
 }
 
-func resourcePostgreSQLGrantRoleDelete(db *DBConnection, d *schema.ResourceData) error {
+func resourcePostgreSQLGrantRoleCreate(db *DBConnection, d *schema.ResourceData) error {
 	if !db.featureSupported(featurePrivileges) {
-		return fmt.Errorf(
-			"postgresql_grant_role resource is not supported for this Postgres version (%s)",
-			db.version,
-		)
+		return unsupportedVersionError(db, "postgresql_grant_role resource")
 	}
 
-	txn, err := startTransaction(db.client, "")
-	if err != nil {
+	if err := withTransaction(db.client, "", func(txn *sql.Tx) error {
+		missingRoles, err := missingGrantRoles(txn, d)
+		if err != nil {
+			return err
+		}
+		if len(missingRoles) > 0 {
+			return fmt.Errorf("could not grant role %s to %s: role(s) %s do not exist",
+				d.Get("grant_role"), d.Get("role"), strings.Join(missingRoles, ", "))
+		}
+
+		withOptions := db.featureSupported(featureRoleMembershipOptions)
+		if withOptions {
+			if inherit, ok := d.GetOkExists(grantRoleInheritOptionAttr); ok && !inherit.(bool) && !d.Get(grantRoleSetOptionAttr).(bool) {
+				return fmt.Errorf(
+					"could not grant role %s to %s: at least one of %s or %s must be true",
+					d.Get("grant_role"), d.Get("role"), grantRoleSetOptionAttr, grantRoleInheritOptionAttr,
+				)
+			}
+		} else if hasGrantRoleOptions(d) {
+			log.Printf(
+				"[WARN] the membership options (%s, %s) of role %s granted to %s are only supported since PostgreSQL 16, "+
+					"the role is granted without them",
+				grantRoleSetOptionAttr, grantRoleInheritOptionAttr, d.Get("grant_role"), d.Get("role"),
+			)
+		}
+
+		// Revoke the granted roles before granting them again.
+		if err = revokeRole(db, txn, d); err != nil {
+			return err
+		}
+
+		return grantRole(db, txn, d, withOptions)
+	}); err != nil {
 		return err
 	}
-	defer deferredRollback(txn)
 
-	if err = revokeRole(txn, d); err != nil {
-		return err
-	}
+	d.SetId(generateGrantRoleID(d))
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
+	return readGrantRole(db, d)
+}
+
+func resourcePostgreSQLGrantRoleDelete(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featurePrivileges) {
+		return unsupportedVersionError(db, "postgresql_grant_role resource")
 	}
 
-	return nil
+	return withTransaction(db.client, "", func(txn *sql.Tx) error {
+		// The membership was removed with the role if it was dropped outside of Terraform
+		missingRoles, err := missingGrantRoles(txn, d)
+		if err != nil {
+			return err
+		}
+		if len(missingRoles) > 0 {
+			log.Printf("[WARN] role(s) %s of grant role %q do not exist anymore, nothing to revoke", strings.Join(missingRoles, ", "), d.Id())
+			return nil
+		}
+
+		return revokeRole(db, txn, d)
+	})
 }
 
-func readGrantRole(db QueryAble, d *schema.ResourceData) error {
+func readGrantRole(db *DBConnection, d *schema.ResourceData) error {
 	var roleName, grantRoleName string
 	var withAdminOption bool
 
@@ -136,10 +228,32 @@ func readGrantRole(db QueryAble, d *schema.ResourceData) error {
 		&withAdminOption,
 	}
 
-	err := db.QueryRow(getGrantRoleQuery, d.Get("role"), d.Get("grant_role")).Scan(values...)
+	// The ADMIN OPTION a CREATEROLE user gets on the roles it creates is not a membership (PostgreSQL >= 16)
+	optionsColumns, membershipCondition := "", ""
+	var setOption, inheritOption bool
+	withOptions := db.featureSupported(featureRoleMembershipOptions)
+	if withOptions {
+		optionsColumns = ", bool_or(set_option), bool_or(inherit_option)"
+		membershipCondition = " AND " + membershipOptionsCondition
+		values = append(values, &setOption, &inheritOption)
+	}
+
+	err := db.QueryRow(
+		fmt.Sprintf(getGrantRoleQuery, optionsColumns, membershipCondition), d.Get("role"), d.Get("grant_role"),
+	).Scan(values...)
 	switch {
 	case err == sql.ErrNoRows:
-		log.Printf("[WARN] PostgreSQL grant role (%q) not found", grantRoleID)
+		// The membership is removed from the state: it is granted again if the configuration still has it,
+		// which fails while one of the roles does not exist.
+		missingRoles, err := missingGrantRoles(db, d)
+		if err != nil {
+			return err
+		}
+		if len(missingRoles) > 0 {
+			log.Printf("[WARN] PostgreSQL grant role (%q) not found: role(s) %s were dropped", grantRoleID, strings.Join(missingRoles, ", "))
+		} else {
+			log.Printf("[WARN] PostgreSQL grant role (%q) not found", grantRoleID)
+		}
 		d.SetId("")
 		return nil
 	case err != nil:
@@ -149,13 +263,21 @@ func readGrantRole(db QueryAble, d *schema.ResourceData) error {
 	_ = d.Set("role", roleName)
 	_ = d.Set("grant_role", grantRoleName)
 	_ = d.Set("with_admin_option", withAdminOption)
+	// Before PostgreSQL 16, the options are not read: the role is granted without them.
+	if withOptions {
+		_ = d.Set(grantRoleSetOptionAttr, setOption)
+		_ = d.Set(grantRoleInheritOptionAttr, inheritOption)
+	}
 
 	d.SetId(generateGrantRoleID(d))
 
 	return nil
 }
 
-func createGrantRoleQuery(d *schema.ResourceData) string {
+// createGrantRoleQuery returns the GRANT query of the membership, with its SET and INHERIT options
+// if withOptions is true (PostgreSQL >= 16). They are only specified if they are not the defaults
+// (the INHERIT option defaults to the INHERIT attribute of the member).
+func createGrantRoleQuery(d *schema.ResourceData, withOptions bool) string {
 	grantRole, _ := d.Get("grant_role").(string)
 	role, _ := d.Get("role").(string)
 
@@ -164,13 +286,32 @@ func createGrantRoleQuery(d *schema.ResourceData) string {
 		pq.QuoteIdentifier(grantRole),
 		pq.QuoteIdentifier(role),
 	)
+
+	var options []string
 	if wao, _ := d.Get("with_admin_option").(bool); wao {
-		query = query + " WITH ADMIN OPTION"
+		options = append(options, "ADMIN OPTION")
+	}
+	if withOptions {
+		if inherit, ok := d.GetOkExists(grantRoleInheritOptionAttr); ok {
+			options = append(options, fmt.Sprintf("INHERIT %s", strings.ToUpper(strconv.FormatBool(inherit.(bool)))))
+		}
+		if !d.Get(grantRoleSetOptionAttr).(bool) {
+			options = append(options, "SET FALSE")
+		}
+	}
+	if len(options) > 0 {
+		query = query + " WITH " + strings.Join(options, ", ")
 	}
 
 	return query
 }
 
+// hasGrantRoleOptions returns true if the SET or INHERIT options of the membership are configured.
+func hasGrantRoleOptions(d *schema.ResourceData) bool {
+	_, inheritSet := d.GetOkExists(grantRoleInheritOptionAttr)
+	return inheritSet || !d.Get(grantRoleSetOptionAttr).(bool)
+}
+
 func createRevokeRoleQuery(d *schema.ResourceData) string {
 	grantRole, _ := d.Get("grant_role").(string)
 	role, _ := d.Get("role").(string)
@@ -182,22 +323,43 @@ func createRevokeRoleQuery(d *schema.ResourceData) string {
 	)
 }
 
-func grantRole(txn *sql.Tx, d *schema.ResourceData) error {
-	query := createGrantRoleQuery(d)
+func grantRole(db *DBConnection, txn *sql.Tx, d *schema.ResourceData, withOptions bool) error {
+	query := createGrantRoleQuery(d, withOptions)
 	if _, err := txn.Exec(query); err != nil {
+		if isInsufficientPrivilege(err) {
+			return adminOptionRequiredError(db, "grant", d.Get("grant_role").(string), d.Get("role").(string), err)
+		}
 		return fmt.Errorf("could not execute grant query: %w", err)
 	}
 	return nil
 }
 
-func revokeRole(txn *sql.Tx, d *schema.ResourceData) error {
+func revokeRole(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	query := createRevokeRoleQuery(d)
 	if _, err := txn.Exec(query); err != nil {
+		if isInsufficientPrivilege(err) {
+			return adminOptionRequiredError(db, "revoke", d.Get("grant_role").(string), d.Get("role").(string), err)
+		}
 		return fmt.Errorf("could not execute revoke query: %w", err)
 	}
 	return nil
 }
 
+// missingGrantRoles returns the roles of the membership which do not exist (e.g.: dropped outside of Terraform).
+func missingGrantRoles(db QueryAble, d *schema.ResourceData) ([]string, error) {
+	var missing []string
+	for _, role := range []string{d.Get("role").(string), d.Get("grant_role").(string)} {
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)", role).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("could not check if role %s exists: %w", role, err)
+		}
+		if !exists {
+			missing = append(missing, role)
+		}
+	}
+	return missing, nil
+}
+
 func generateGrantRoleID(d *schema.ResourceData) string {
 	return strings.Join([]string{d.Get("role").(string), d.Get("grant_role").(string), strconv.FormatBool(d.Get("with_admin_option").(bool))}, "_")
 }
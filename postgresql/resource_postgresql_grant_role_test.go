@@ -3,9 +3,12 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
@@ -17,8 +20,9 @@ func TestCreateGrantRoleQuery(t *testing.T) {
 	var grantRoleName = "bar"
 
 	cases := []struct {
-		resource map[string]interface{}
-		expected string
+		resource    map[string]interface{}
+		withOptions bool
+		expected    string
 	}{
 		{
 			resource: map[string]interface{}{
@@ -43,10 +47,49 @@ func TestCreateGrantRoleQuery(t *testing.T) {
 			},
 			expected: fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION", pq.QuoteIdentifier(grantRoleName), pq.QuoteIdentifier(roleName)),
 		},
+		{
+			resource: map[string]interface{}{
+				"role":              roleName,
+				"grant_role":        grantRoleName,
+				"with_admin_option": true,
+			},
+			withOptions: true,
+			expected:    fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION", pq.QuoteIdentifier(grantRoleName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: map[string]interface{}{
+				"role":           roleName,
+				"grant_role":     grantRoleName,
+				"set_option":     false,
+				"inherit_option": true,
+			},
+			withOptions: true,
+			expected:    fmt.Sprintf("GRANT %s TO %s WITH INHERIT TRUE, SET FALSE", pq.QuoteIdentifier(grantRoleName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: map[string]interface{}{
+				"role":              roleName,
+				"grant_role":        grantRoleName,
+				"with_admin_option": true,
+				"inherit_option":    false,
+			},
+			withOptions: true,
+			expected:    fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION, INHERIT FALSE", pq.QuoteIdentifier(grantRoleName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			// The options are not supported before PostgreSQL 16
+			resource: map[string]interface{}{
+				"role":           roleName,
+				"grant_role":     grantRoleName,
+				"set_option":     false,
+				"inherit_option": true,
+			},
+			expected: fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(grantRoleName), pq.QuoteIdentifier(roleName)),
+		},
 	}
 
 	for _, c := range cases {
-		out := createGrantRoleQuery(schema.TestResourceDataRaw(t, resourcePostgreSQLGrantRole().Schema, c.resource))
+		out := createGrantRoleQuery(schema.TestResourceDataRaw(t, resourcePostgreSQLGrantRole().Schema, c.resource), c.withOptions)
 		if out != c.expected {
 			t.Fatalf("Error matching output and expected: %#v vs %#v", out, c.expected)
 		}
@@ -92,6 +135,29 @@ func TestRevokeRoleQuery(t *testing.T) {
 	}
 }
 
+func TestAdminOptionRequiredError(t *testing.T) {
+	pqErr := &pq.Error{Code: pgErrInsufficientPrivilege, Message: "permission denied to grant role \"bar\""}
+
+	cases := []struct {
+		version  string
+		contains string
+	}{
+		{"15.0.0", "needs the CREATEROLE privilege or the ADMIN OPTION"},
+		{"16.0.0", `since PostgreSQL 16 the connected user needs the ADMIN OPTION on role bar, CREATEROLE is not enough`},
+	}
+
+	for _, c := range cases {
+		db := &DBConnection{version: semver.MustParse(c.version)}
+		err := adminOptionRequiredError(db, "grant", "bar", "foo", pqErr)
+		if !strings.Contains(err.Error(), c.contains) {
+			t.Errorf("expected error for version %s to contain %q, got: %v", c.version, c.contains, err)
+		}
+		if !isInsufficientPrivilege(err) {
+			t.Errorf("expected the original error to be wrapped, got: %v", err)
+		}
+	}
+}
+
 func TestAccPostgresqlGrantRole(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -139,6 +205,145 @@ func TestAccPostgresqlGrantRole(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlGrantRole_MembershipOptions(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+
+	dbSuffix, teardown := setupTestDatabase(t, false, true)
+	defer teardown()
+
+	_, roleName := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureRoleMembershipOptions)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource postgresql_role "parent" {
+  name = "test_no_set_parent"
+}
+resource postgresql_grant_role "grant_role" {
+  role           = "%s"
+  grant_role     = postgresql_role.parent.name
+  set_option     = false
+  inherit_option = true
+}
+`, roleName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant_role.grant_role", "set_option", "false"),
+					resource.TestCheckResourceAttr("postgresql_grant_role.grant_role", "inherit_option", "true"),
+					checkGrantRoleOptions(t, dsn, roleName, "test_no_set_parent", false, true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantRole_Circular(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+
+	// test_circular_child is a member of test_circular_parent through test_circular_middle
+	dbExecute(t, dsn, "CREATE ROLE test_circular_parent")
+	defer dbExecute(t, dsn, "DROP ROLE test_circular_parent")
+	dbExecute(t, dsn, "CREATE ROLE test_circular_middle IN ROLE test_circular_parent")
+	defer dbExecute(t, dsn, "DROP ROLE test_circular_middle")
+	dbExecute(t, dsn, "CREATE ROLE test_circular_child IN ROLE test_circular_middle")
+	defer dbExecute(t, dsn, "DROP ROLE test_circular_child")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource postgresql_grant_role "circular" {
+  role       = "test_circular_parent"
+  grant_role = "test_circular_child"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("test_circular_child is already a member of test_circular_parent"),
+			},
+			{
+				Config: `
+resource postgresql_grant_role "itself" {
+  role       = "test_circular_parent"
+  grant_role = "test_circular_parent"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("role test_circular_parent cannot be granted to itself"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantRole_DroppedRole(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+
+	dbExecute(t, dsn, "CREATE ROLE test_dropped_member")
+	defer dbExecute(t, dsn, "DROP ROLE IF EXISTS test_dropped_member")
+	dbExecute(t, dsn, "CREATE ROLE test_dropped_parent")
+	defer dbExecute(t, dsn, "DROP ROLE IF EXISTS test_dropped_parent")
+
+	tfConfig := `
+resource postgresql_grant_role "dropped" {
+  role       = "test_dropped_member"
+  grant_role = "test_dropped_parent"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check:  checkGrantRole(t, dsn, "test_dropped_member", "test_dropped_parent", false),
+			},
+			// The membership is gone with the role dropped outside of Terraform and is planned to be granted again
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "DROP ROLE test_dropped_parent")
+				},
+				Config:             tfConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:      tfConfig,
+				ExpectError: regexp.MustCompile("role\\(s\\) test_dropped_parent do not exist"),
+			},
+			// Granted again once the role is recreated
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE ROLE test_dropped_parent")
+				},
+				Config: tfConfig,
+				Check:  checkGrantRole(t, dsn, "test_dropped_member", "test_dropped_parent", false),
+			},
+		},
+	})
+}
+
 func checkGrantRole(t *testing.T, dsn, role string, grantRole string, withAdmin bool) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		db, err := sql.Open("postgres", dsn)
@@ -170,3 +375,35 @@ func checkGrantRole(t *testing.T, dsn, role string, grantRole string, withAdmin
 		return nil
 	}
 }
+
+func checkGrantRoleOptions(t *testing.T, dsn, role, grantRole string, setOption, inheritOption bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("could to create connection pool: %v", err)
+		}
+		defer db.Close()
+
+		var actualSet, actualInherit bool
+		err = db.QueryRow(`
+		SELECT set_option, inherit_option
+		FROM pg_auth_members
+		WHERE pg_get_userbyid(member) = $1
+		AND pg_get_userbyid(roleid) = $2
+		`, role, grantRole).Scan(&actualSet, &actualInherit)
+		switch {
+		case err == sql.ErrNoRows:
+			return fmt.Errorf("Role %s is not a member of %s", role, grantRole)
+		case err != nil:
+			t.Fatalf("could not check granted role: %v", err)
+		}
+
+		if actualSet != setOption || actualInherit != inheritOption {
+			return fmt.Errorf(
+				"role %s is a member of %s with SET %t and INHERIT %t, expected SET %t and INHERIT %t",
+				role, grantRole, actualSet, actualInherit, setOption, inheritOption,
+			)
+		}
+		return nil
+	}
+}
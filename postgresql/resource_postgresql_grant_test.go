@@ -1,10 +1,13 @@
 package postgresql
 
 import (
+	"database/sql"
 	"fmt"
+	"reflect"
 	"regexp"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
@@ -96,6 +99,54 @@ func TestCreateGrantQuery(t *testing.T) {
 			privileges: []string{"SELECT"},
 			expected:   fmt.Sprintf(`GRANT SELECT ON TABLE %[1]s."o2",%[1]s."o1" TO %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
 		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "column",
+				"objects":     []interface{}{"o1"},
+				"columns":     []interface{}{"c1"},
+				"schema":      databaseName,
+				"role":        roleName,
+			}),
+			privileges: []string{"SELECT", "UPDATE"},
+			expected:   fmt.Sprintf(`GRANT SELECT ("c1"),UPDATE ("c1") ON TABLE %s."o1" TO %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "type",
+				"objects":     []interface{}{"o1"},
+				"schema":      databaseName,
+				"role":        roleName,
+			}),
+			privileges: []string{"USAGE"},
+			expected:   fmt.Sprintf(`GRANT USAGE ON TYPE %s."o1" TO %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "schema",
+				"schema":      databaseName,
+				"roles":       []interface{}{"r2", roleName},
+			}),
+			privileges: []string{"USAGE"},
+			expected:   fmt.Sprintf(`GRANT USAGE ON SCHEMA %s TO %s,"r2"`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "foreign_server",
+				"objects":     []interface{}{"o1"},
+				"role":        roleName,
+			}),
+			privileges: []string{"USAGE"},
+			expected:   fmt.Sprintf(`GRANT USAGE ON FOREIGN SERVER "o1" TO %s`, pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "foreign_data_wrapper",
+				"objects":     []interface{}{"o1"},
+				"role":        roleName,
+			}),
+			privileges: []string{"USAGE"},
+			expected:   fmt.Sprintf(`GRANT USAGE ON FOREIGN DATA WRAPPER "o1" TO %s`, pq.QuoteIdentifier(roleName)),
+		},
 	}
 
 	for _, c := range cases {
@@ -131,6 +182,14 @@ func TestCreateRevokeQuery(t *testing.T) {
 			}),
 			expected: fmt.Sprintf("REVOKE ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
 		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "foreign_server",
+				"objects":     []interface{}{"o1"},
+				"role":        roleName,
+			}),
+			expected: fmt.Sprintf(`REVOKE ALL PRIVILEGES ON FOREIGN SERVER "o1" FROM %s`, pq.QuoteIdentifier(roleName)),
+		},
 		{
 			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
 				"object_type": "database",
@@ -156,6 +215,106 @@ func TestCreateRevokeQuery(t *testing.T) {
 			}),
 			expected: fmt.Sprintf(`REVOKE ALL PRIVILEGES ON TABLE %[1]s."o2",%[1]s."o1" FROM %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
 		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "column",
+				"objects":     []interface{}{"o1"},
+				"columns":     []interface{}{"c1"},
+				"schema":      databaseName,
+				"role":        roleName,
+			}),
+			expected: fmt.Sprintf(`REVOKE ALL PRIVILEGES ("c1") ON TABLE %s."o1" FROM %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "type",
+				"objects":     []interface{}{"o1"},
+				"schema":      databaseName,
+				"role":        roleName,
+			}),
+			expected: fmt.Sprintf(`REVOKE ALL PRIVILEGES ON TYPE %s."o1" FROM %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type":    "table",
+				"schema":         databaseName,
+				"role":           roleName,
+				"privileges":     []interface{}{"SELECT"},
+				"reconcile_mode": "additive",
+			}),
+			expected: fmt.Sprintf("REVOKE SELECT ON ALL TABLES IN SCHEMA %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type":    "database",
+				"database":       databaseName,
+				"role":           roleName,
+				"privileges":     []interface{}{"CONNECT"},
+				"reconcile_mode": "additive",
+			}),
+			expected: fmt.Sprintf("REVOKE CONNECT ON DATABASE %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			// An empty privileges list revokes everything, even in additive mode.
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type":    "table",
+				"schema":         databaseName,
+				"role":           roleName,
+				"privileges":     []interface{}{},
+				"reconcile_mode": "additive",
+			}),
+			expected: fmt.Sprintf("REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA %s FROM %s", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type":    "table",
+				"objects":        []interface{}{"o1"},
+				"schema":         databaseName,
+				"role":           roleName,
+				"privileges":     []interface{}{"INSERT"},
+				"reconcile_mode": "additive",
+			}),
+			expected: fmt.Sprintf(`REVOKE INSERT ON TABLE %s."o1" FROM %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type":    "table",
+				"objects":        []interface{}{"o1"},
+				"schema":         databaseName,
+				"role":           roleName,
+				"privileges":     []interface{}{"INSERT"},
+				"reconcile_mode": "privileges_managed",
+			}),
+			expected: fmt.Sprintf(`REVOKE INSERT ON TABLE %s."o1" FROM %s`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type":    "table",
+				"schema":         databaseName,
+				"role":           roleName,
+				"revoke_cascade": true,
+			}),
+			expected: fmt.Sprintf("REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA %s FROM %s CASCADE", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type":    "database",
+				"database":       databaseName,
+				"role":           roleName,
+				"privileges":     []interface{}{"CONNECT"},
+				"reconcile_mode": "additive",
+				"revoke_cascade": true,
+			}),
+			expected: fmt.Sprintf("REVOKE CONNECT ON DATABASE %s FROM %s CASCADE", pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
+		{
+			resource: schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+				"object_type": "table",
+				"schema":      databaseName,
+				"roles":       []interface{}{"r2", roleName},
+			}),
+			expected: fmt.Sprintf(`REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA %s FROM %s,"r2"`, pq.QuoteIdentifier(databaseName), pq.QuoteIdentifier(roleName)),
+		},
 	}
 
 	for _, c := range cases {
@@ -166,6 +325,324 @@ func TestCreateRevokeQuery(t *testing.T) {
 	}
 }
 
+func TestReconcilePrivileges(t *testing.T) {
+	actual := []interface{}{"SELECT", "DELETE"}
+
+	var cases = []struct {
+		mode       string
+		privileges []interface{}
+		expected   []interface{}
+	}{
+		{grantReconcileExclusive, []interface{}{"SELECT"}, actual},
+		{grantReconcileAdditive, []interface{}{"SELECT"}, []interface{}{"SELECT"}},
+		{grantReconcileAdditive, []interface{}{"INSERT"}, []interface{}{}},
+		// An empty privileges list means the role must have no privileges, so all of them are reported.
+		{grantReconcileExclusive, []interface{}{}, actual},
+		{grantReconcileAdditive, []interface{}{}, actual},
+		// DELETE is granted outside of Terraform.
+		{grantReconcileAdditive, []interface{}{"SELECT", "INSERT"}, []interface{}{"SELECT"}},
+		{grantReconcilePrivilegesManaged, []interface{}{"SELECT", "INSERT"}, []interface{}{"SELECT"}},
+		// An empty privileges list manages nothing in privileges_managed mode.
+		{grantReconcilePrivilegesManaged, []interface{}{}, []interface{}{}},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+			"object_type":    "table",
+			"schema":         "test_schema",
+			"role":           "test_role",
+			"privileges":     c.privileges,
+			"reconcile_mode": c.mode,
+		})
+		db := &DBConnection{version: semver.MustParse("16.0.0")}
+		got := reconcilePrivileges(db, d, schema.NewSet(schema.HashString, actual))
+		expected := schema.NewSet(schema.HashString, c.expected)
+		if got.Len() != expected.Len() || got.Difference(expected).Len() > 0 {
+			t.Errorf("reconcilePrivileges(%s, %v) = %v, want %v", c.mode, c.privileges, got.List(), expected.List())
+		}
+	}
+}
+
+func TestReconcilePrivilegesFromConfiguration(t *testing.T) {
+	actual := schema.NewSet(schema.HashString, []interface{}{"SELECT", "DELETE"})
+
+	var cases = []struct {
+		mode       string
+		configured []interface{}
+		state      []interface{}
+		expected   []interface{}
+	}{
+		// The privileges of the state are empty since SELECT was revoked outside of Terraform,
+		// which must not be taken as a revoke all grant.
+		{grantReconcileAdditive, []interface{}{"SELECT"}, []interface{}{}, []interface{}{"SELECT"}},
+		// The privileges of the state were read while the list was empty (revoke all)
+		{grantReconcileAdditive, []interface{}{}, []interface{}{"SELECT", "DELETE"}, []interface{}{"SELECT", "DELETE"}},
+		// Only the privileges applied last are compared in privileges_managed mode
+		{grantReconcilePrivilegesManaged, []interface{}{"SELECT"}, []interface{}{}, []interface{}{"SELECT"}},
+		{grantReconcilePrivilegesManaged, []interface{}{}, []interface{}{"SELECT"}, []interface{}{}},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+			"object_type":    "table",
+			"schema":         "test_schema",
+			"role":           "test_role",
+			"privileges":     c.state,
+			"reconcile_mode": c.mode,
+		})
+		d.SetId("test_role_test_schema_table")
+		_ = d.Set("configured_privileges", c.configured)
+
+		db := &DBConnection{version: semver.MustParse("16.0.0")}
+		got := reconcilePrivileges(db, d, actual)
+		expected := schema.NewSet(schema.HashString, c.expected)
+		if !sameStringSets(got, expected) {
+			t.Errorf("reconcilePrivileges(%s, %v) = %v, want %v", c.mode, c.configured, got.List(), expected.List())
+		}
+	}
+}
+
+func TestRevokedPrivileges(t *testing.T) {
+	var cases = []struct {
+		mode       string
+		configured []interface{}
+		expected   []string
+	}{
+		{grantReconcileExclusive, []interface{}{"SELECT"}, []string{"ALL PRIVILEGES"}},
+		{grantReconcileAdditive, []interface{}{"SELECT"}, []string{"SELECT"}},
+		{grantReconcileAdditive, []interface{}{}, []string{"ALL PRIVILEGES"}},
+		{grantReconcilePrivilegesManaged, []interface{}{"SELECT"}, []string{"SELECT"}},
+		// Nothing was granted by the resource, the privileges granted by others are kept
+		{grantReconcilePrivilegesManaged, []interface{}{}, []string{}},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+			"object_type":    "table",
+			"schema":         "test_schema",
+			"role":           "test_role",
+			"privileges":     c.configured,
+			"reconcile_mode": c.mode,
+		})
+		d.SetId("test_role_test_schema_table")
+		_ = d.Set("configured_privileges", c.configured)
+
+		if got := revokedPrivileges(d); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("revokedPrivileges(%s, %v) = %v, want %v", c.mode, c.configured, got, c.expected)
+		}
+	}
+}
+
+func TestResourcePostgreSQLGrantStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"role":        "test_role",
+		"object_type": "table",
+		"privileges":  []interface{}{"SELECT"},
+	}
+
+	got, err := resourcePostgreSQLGrantStateUpgradeV0(rawState, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got["configured_privileges"], []interface{}{"SELECT"}) {
+		t.Errorf("configured_privileges = %v, want the privileges of the state", got["configured_privileges"])
+	}
+}
+
+func TestSplitCopiedPrivileges(t *testing.T) {
+	var cases = []struct {
+		privileges       []interface{}
+		copiedPrivileges []interface{}
+		actual           []interface{}
+		expectedDirect   []interface{}
+		expectedCopied   []interface{}
+	}{
+		{[]interface{}{"SELECT"}, []interface{}{}, []interface{}{"SELECT"}, []interface{}{"SELECT"}, []interface{}{}},
+		{[]interface{}{"SELECT"}, []interface{}{"INSERT"}, []interface{}{"SELECT", "INSERT"}, []interface{}{"SELECT"}, []interface{}{"INSERT"}},
+		// A copied privilege which is missing is reported in the copied privileges only
+		{[]interface{}{"SELECT"}, []interface{}{"INSERT"}, []interface{}{"SELECT"}, []interface{}{"SELECT"}, []interface{}{}},
+		// A privilege both configured and copied is kept in both
+		{[]interface{}{"SELECT"}, []interface{}{"SELECT", "INSERT"}, []interface{}{"SELECT", "INSERT"}, []interface{}{"SELECT"}, []interface{}{"SELECT", "INSERT"}},
+		{[]interface{}{}, []interface{}{"SELECT"}, []interface{}{"SELECT", "DELETE"}, []interface{}{"DELETE"}, []interface{}{"SELECT"}},
+		{[]interface{}{"ALL"}, []interface{}{"SELECT"}, []interface{}{"SELECT", "DELETE"}, []interface{}{"SELECT", "DELETE"}, []interface{}{"SELECT"}},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+			"object_type":    "table",
+			"schema":         "test_schema",
+			"role":           "test_role",
+			"privileges":     c.privileges,
+			"copy_from_role": "test_template",
+		})
+		if err := d.Set("copied_privileges", c.copiedPrivileges); err != nil {
+			t.Fatal(err)
+		}
+
+		direct, copied := splitCopiedPrivileges(d, schema.NewSet(schema.HashString, c.actual))
+		expectedDirect := schema.NewSet(schema.HashString, c.expectedDirect)
+		expectedCopied := schema.NewSet(schema.HashString, c.expectedCopied)
+		if !sameStringSets(direct, expectedDirect) || !sameStringSets(copied, expectedCopied) {
+			t.Errorf(
+				"splitCopiedPrivileges(%v, %v, %v) = %v, %v, want %v, %v",
+				c.privileges, c.copiedPrivileges, c.actual, direct.List(), copied.List(), c.expectedDirect, c.expectedCopied,
+			)
+		}
+	}
+}
+
+func TestNormalizeAllPrivileges(t *testing.T) {
+	tablePrivileges := []interface{}{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"}
+	withMaintain := append([]interface{}{"MAINTAIN"}, tablePrivileges...)
+
+	var cases = []struct {
+		version    string
+		objectType string
+		privileges []interface{}
+		actual     []interface{}
+		expected   []interface{}
+	}{
+		{"16.0.0", "table", []interface{}{"ALL"}, tablePrivileges, []interface{}{"ALL"}},
+		// MAINTAIN is implied by ALL on tables since PG 17
+		{"17.0.0", "table", []interface{}{"ALL"}, tablePrivileges, tablePrivileges},
+		{"17.0.0", "table", []interface{}{"ALL"}, withMaintain, []interface{}{"ALL"}},
+		{"16.0.0", "table", []interface{}{"ALL"}, []interface{}{"SELECT"}, []interface{}{"SELECT"}},
+		{"16.0.0", "table", []interface{}{"SELECT"}, tablePrivileges, tablePrivileges},
+		{"16.0.0", "database", []interface{}{"ALL"}, []interface{}{"CREATE", "CONNECT", "TEMPORARY"}, []interface{}{"ALL"}},
+		{"16.0.0", "sequence", []interface{}{"ALL"}, []interface{}{"USAGE", "SELECT", "UPDATE"}, []interface{}{"ALL"}},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+			"object_type": c.objectType,
+			"schema":      "test_schema",
+			"role":        "test_role",
+			"privileges":  c.privileges,
+		})
+		db := &DBConnection{version: semver.MustParse(c.version)}
+		got := normalizeAllPrivileges(db, d, schema.NewSet(schema.HashString, c.actual))
+		expected := schema.NewSet(schema.HashString, c.expected)
+		if got.Len() != expected.Len() || got.Difference(expected).Len() > 0 {
+			t.Errorf("normalizeAllPrivileges(%s, %s, %v) = %v, want %v", c.version, c.objectType, c.actual, got.List(), expected.List())
+		}
+	}
+}
+
+func TestValidatePrivilegesMaintain(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+		"object_type": "table",
+		"schema":      "test_schema",
+		"role":        "test_role",
+		"privileges":  []interface{}{"SELECT", "MAINTAIN"},
+	})
+
+	if err := validatePrivileges(&DBConnection{version: semver.MustParse("16.0.0")}, d); err == nil {
+		t.Errorf("MAINTAIN should not be allowed for Postgres 16")
+	}
+	if err := validatePrivileges(&DBConnection{version: semver.MustParse("17.0.0")}, d); err != nil {
+		t.Errorf("MAINTAIN should be allowed for Postgres 17: %v", err)
+	}
+}
+
+func TestValidateObjectPrivilegesUnknown(t *testing.T) {
+	db := &DBConnection{version: semver.MustParse("17.0.0")}
+
+	var tests = []struct {
+		objectType string
+		privilege  string
+		wantErr    bool
+	}{
+		{"table", "SELECT", false},
+		// Privileges not known by the provider (e.g.: added by a newer version) are passed to the server
+		{"table", "NEW_PRIVILEGE", false},
+		{"schema", "FUTURE", false},
+		// Known privileges are only allowed for their object types
+		{"table", "EXECUTE", true},
+		{"function", "SELECT", true},
+		// Only keywords can be passed as is
+		{"table", "select", true},
+		{"table", "SELECT; DROP TABLE foo", true},
+		{"table", "NEW PRIVILEGE", true},
+	}
+
+	for _, test := range tests {
+		err := validateObjectPrivileges(db, test.objectType, []interface{}{test.privilege})
+		if (err != nil) != test.wantErr {
+			t.Errorf("validateObjectPrivileges(%s, %q) error = %v, wantErr %t", test.objectType, test.privilege, err, test.wantErr)
+		}
+	}
+}
+
+func TestGrantDiffInvalidPrivileges(t *testing.T) {
+	var tests = []struct {
+		objectType string
+		privileges []interface{}
+		wantErr    bool
+	}{
+		{"table", []interface{}{"SELECT", "INSERT"}, false},
+		{"table", []interface{}{"SELECT", "EXECUTE"}, true},
+		{"function", []interface{}{"SELECT"}, true},
+		{"foreign_server", []interface{}{"USAGE"}, false},
+		// The server version is not known without provider
+		{"table", []interface{}{"MAINTAIN"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %v", test.objectType, test.privileges), func(t *testing.T) {
+			config := map[string]interface{}{
+				"database":    "test_db",
+				"schema":      "test_schema",
+				"role":        "test_role",
+				"object_type": test.objectType,
+				"privileges":  test.privileges,
+			}
+
+			_, err := resourcePostgreSQLGrant().Diff(nil, terraform.NewResourceConfigRaw(config), nil)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Diff() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestInvalidateObjectsPrivileges(t *testing.T) {
+	newClient := func() *Client {
+		return &Client{
+			objectsPrivilegesCache: map[objectsPrivilegesKey]*cachedObjectsPrivileges{
+				{"foo", "test_schema", "table"}:    {},
+				{"foo", "test_schema", "sequence"}: {},
+				{"foo", "other_schema", "table"}:   {},
+				{"bar", "test_schema", "table"}:    {},
+			},
+		}
+	}
+
+	client := newClient()
+	invalidateObjectsPrivileges(client, schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, map[string]interface{}{
+		"database":    "foo",
+		"object_type": "table",
+		"schema":      "test_schema",
+		"role":        "test_role",
+	}))
+
+	if _, ok := client.objectsPrivilegesCache[objectsPrivilegesKey{"foo", "test_schema", "table"}]; ok {
+		t.Fatalf("privileges of tables in foo.test_schema should have been invalidated")
+	}
+	if len(client.objectsPrivilegesCache) != 3 {
+		t.Fatalf("only privileges of tables in foo.test_schema should have been invalidated: %v", client.objectsPrivilegesCache)
+	}
+
+	// e.g.: after the statements of a postgresql_query
+	client = newClient()
+	invalidateCachedObjectsPrivileges(client, "foo", "", "")
+	if len(client.objectsPrivilegesCache) != 1 {
+		t.Fatalf("only privileges of objects in foo should have been invalidated: %v", client.objectsPrivilegesCache)
+	}
+	if _, ok := client.objectsPrivilegesCache[objectsPrivilegesKey{"bar", "test_schema", "table"}]; !ok {
+		t.Fatalf("privileges of tables in bar.test_schema should not have been invalidated")
+	}
+}
+
 func TestAccPostgresqlGrant(t *testing.T) {
 	skipIfNotAcc(t)
 
@@ -237,8 +714,941 @@ func TestAccPostgresqlGrant(t *testing.T) {
 			},
 			// We test to revoke everything
 			{
-				Config: fmt.Sprintf(testGrant, `[]`),
+				Config: fmt.Sprintf(testGrant, `[]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantAdditive(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table", "test_schema.test_table2"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// Privilege granted outside of Terraform which must be kept in additive mode.
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr(dbName)
+	dbExecute(t, dsn, fmt.Sprintf("GRANT DELETE ON ALL TABLES IN SCHEMA test_schema TO %s", roleName))
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database       = "%s"
+		role           = "%s"
+		schema         = "test_schema"
+		object_type    = "table"
+		privileges     = %%s
+		reconcile_mode = "additive"
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.3138006342", "SELECT"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT", "DELETE"})
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["INSERT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.892623219", "INSERT"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"INSERT", "DELETE"})
+					},
+				),
+			},
+			{
+				// An empty list revokes all the privileges, including the ones granted outside of Terraform.
+				Config: fmt.Sprintf(testGrant, `[]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+					},
+				),
+			},
+			{
+				// And any privilege granted afterwards is detected.
+				PreConfig: func() {
+					dbExecute(t, dsn, fmt.Sprintf("GRANT SELECT ON test_schema.test_table TO %s", roleName))
+				},
+				Config:             fmt.Sprintf(testGrant, `[]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantKeepOnDestroy(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database          = "%s"
+		role              = "%s"
+		schema            = "test_schema"
+		object_type       = "table"
+		privileges        = ["SELECT"]
+		revoke_on_destroy = false
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testGrant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "revoke_on_destroy", "false"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+				),
+			},
+			{
+				// The resource is removed from the configuration but the privileges are kept
+				Config: `data "postgresql_connection_info" "current" {}`,
+				Check: func(*terraform.State) error {
+					return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+				},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantAdditiveSharedObject(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// Privilege granted outside of Terraform which must never be revoked.
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr(dbName)
+	dbExecute(t, dsn, fmt.Sprintf("GRANT DELETE ON test_schema.test_table TO %s", roleName))
+
+	// Two teams managing different privileges of the same role on the same table.
+	var testGrantTeam = `
+	resource "postgresql_grant" "%s" {
+		database       = "%s"
+		role           = "%s"
+		schema         = "test_schema"
+		object_type    = "table"
+		objects        = ["test_table"]
+		privileges     = %s
+		reconcile_mode = "additive"
+	}
+	`
+	teamA := fmt.Sprintf(testGrantTeam, "team_a", dbName, roleName, `["SELECT"]`)
+	teamB := fmt.Sprintf(testGrantTeam, "team_b", dbName, roleName, `["INSERT"]`)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: teamA + teamB,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.team_a", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.team_a", "privileges.3138006342", "SELECT"),
+					resource.TestCheckResourceAttr("postgresql_grant.team_b", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.team_b", "privileges.892623219", "INSERT"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT", "INSERT", "DELETE"})
+					},
+				),
+			},
+			{
+				// The privileges of the other team and the foreign one are not seen as a drift
+				Config:   teamA + teamB,
+				PlanOnly: true,
+			},
+			{
+				// Removing the grant of a team only revokes its privileges
+				Config: teamB,
+				Check: func(*terraform.State) error {
+					return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"INSERT", "DELETE"})
+				},
+			},
+			{
+				// A listed privilege revoked outside of Terraform is a drift
+				PreConfig: func() {
+					dbExecute(t, dsn, fmt.Sprintf("REVOKE INSERT ON test_schema.test_table FROM %s", roleName))
+				},
+				Config:             teamB,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantPrivilegesManaged(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// Privilege granted outside of Terraform which must survive the updates and the destroy.
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr(dbName)
+	dbExecute(t, dsn, fmt.Sprintf("GRANT DELETE ON test_schema.test_table TO %s", roleName))
+
+	var testGrant = `
+	resource "postgresql_grant" "test" {
+		database       = "%s"
+		role           = "%s"
+		schema         = "test_schema"
+		object_type    = "table"
+		objects        = ["test_table"]
+		privileges     = %s
+		reconcile_mode = "privileges_managed"
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, dbName, roleName, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.3138006342", "SELECT"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT", "DELETE"})
+					},
+				),
+			},
+			{
+				// Only the privilege granted by the resource is revoked
+				Config: fmt.Sprintf(testGrant, dbName, roleName, `["INSERT"]`),
+				Check: func(*terraform.State) error {
+					return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"INSERT", "DELETE"})
+				},
+			},
+			{
+				// An empty list does not revoke the foreign privilege
+				Config: fmt.Sprintf(testGrant, dbName, roleName, `[]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"DELETE"})
+					},
+				),
+			},
+			{
+				// The foreign privilege is not seen as a drift
+				Config:   fmt.Sprintf(testGrant, dbName, roleName, `[]`),
+				PlanOnly: true,
+			},
+			{
+				Config: fmt.Sprintf(testGrant, dbName, roleName, `["SELECT"]`),
+				Check: func(*terraform.State) error {
+					return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT", "DELETE"})
+				},
+			},
+			{
+				// Destroying the grant only revokes its own privileges
+				Config: `data "postgresql_connection_info" "current" {}`,
+				Check: func(*terraform.State) error {
+					return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"DELETE"})
+				},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantApplyToAllExisting(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table", "test_schema.test_table2"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database              = "%s"
+		role                  = "%s"
+		schema                = "test_schema"
+		object_type           = "table"
+		privileges            = ["SELECT"]
+		apply_to_all_existing = true
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testGrant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "apply_to_all_existing", "true"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+				),
+			},
+			{
+				// Objects created afterwards are not covered and must not cause a diff.
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dsn, _ := testConfig.connStr(dbName)
+					dbExecute(t, dsn, "CREATE TABLE test_schema.test_table3 (val text)")
+				},
+				Config:   testGrant,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantColumns(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "column"
+		objects     = ["test_table"]
+		columns     = ["val"]
+		privileges  = %%s
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.3138006342", "SELECT"),
+					func(*terraform.State) error {
+						db := connectAsTestRole(t, roleName, dbName)
+						defer db.Close()
+						return testHasGrantForQuery(db, "SELECT val FROM test_schema.test_table", true)
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT", "UPDATE"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.1759376126", "UPDATE"),
+				),
+			},
+			{
+				// Effective privileges exceeding the directly granted ones must not cause a diff.
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dsn, _ := testConfig.connStr(dbName)
+					dbExecute(t, dsn, fmt.Sprintf("GRANT INSERT ON test_schema.test_table TO %s", roleName))
+				},
+				Config:   fmt.Sprintf(testGrant, `["SELECT", "UPDATE"]`),
+				PlanOnly: true,
+			},
+			{
+				// Column privileges revoked outside of Terraform must be detected.
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dsn, _ := testConfig.connStr(dbName)
+					dbExecute(t, dsn, fmt.Sprintf("REVOKE UPDATE (val) ON test_schema.test_table FROM %s", roleName))
+				},
+				Config:             fmt.Sprintf(testGrant, `["SELECT", "UPDATE"]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantObjects(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table", "test_schema.test_table2"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// create a TF config with placeholder for privileges
+	// it will be filled in each step.
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "table"
+		objects     = %%s
+		privileges  = ["SELECT"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["test_table"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"postgresql_grant.test", "id", fmt.Sprintf("%s_%s_test_schema_table_test_table", roleName, dbName),
+					),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.4260833613", "test_table"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[0]}, []string{"SELECT"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[1]}, []string{})
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["test_table", "test_table2"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.4260833613", "test_table"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.306541577", "test_table2"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["test_table"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.4260833613", "test_table"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[0]}, []string{"SELECT"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[1]}, []string{})
+					},
+				),
+			},
+			{
+				// Empty list means that privileges will be applied on all tables.
+				Config: fmt.Sprintf(testGrant, `[]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+				),
+			},
+			{
+				Config:  fmt.Sprintf(testGrant, `[]`),
+				Destroy: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantSequence(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr(dbName)
+	dbExecute(t, dsn, "CREATE SEQUENCE test_schema.test_seq")
+	dbExecute(t, dsn, "CREATE SEQUENCE test_schema.test_seq2")
+	testSequences := []string{"test_schema.test_seq", "test_schema.test_seq2"}
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "sequence"
+		privileges  = %%s
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["USAGE", "SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "2"),
+					func(*terraform.State) error {
+						return testCheckSequencesPrivileges(t, dbName, roleName, testSequences, []string{"USAGE", "SELECT"})
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["UPDATE"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					func(*terraform.State) error {
+						return testCheckSequencesPrivileges(t, dbName, roleName, testSequences, []string{"UPDATE"})
+					},
+				),
+			},
+			{
+				// ALL is granted as USAGE, SELECT and UPDATE, which are not reported as a drift
+				Config: fmt.Sprintf(testGrant, `["ALL"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					func(*terraform.State) error {
+						return testCheckSequencesPrivileges(t, dbName, roleName, testSequences, []string{"USAGE", "SELECT", "UPDATE"})
+					},
+				),
+			},
+			{
+				// A sequence created afterwards is a drift
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE SEQUENCE test_schema.test_seq3")
+				},
+				Config:             fmt.Sprintf(testGrant, `["ALL"]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// The implicit privileges of the owner of a sequence (NULL ACL) are read
+				PreConfig: func() {
+					dbExecute(t, dsn, fmt.Sprintf("ALTER SEQUENCE test_schema.test_seq3 OWNER TO %s", roleName))
+				},
+				Config:   fmt.Sprintf(testGrant, `["ALL"]`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// Test that the privileges on the sequence of a SERIAL column implied by the privileges
+// on its table are not reported as a drift of a grant on all the sequences of the schema.
+func TestAccPostgresqlGrantOwnedSequence(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr(dbName)
+	dbExecute(t, dsn, "CREATE TABLE test_schema.test_serial (id serial, val text)")
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "table" {
+		database    = "%[1]s"
+		role        = "%[2]s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = ["INSERT"]
+	}
+
+	resource "postgresql_grant" "sequence" {
+		database    = "%[1]s"
+		role        = "%[2]s"
+		schema      = "test_schema"
+		object_type = "sequence"
+		privileges  = ["USAGE"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testGrant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.sequence", "privileges.#", "1"),
+				),
+			},
+			{
+				// A new table with a SERIAL column is created and INSERT is granted on it
+				// outside of Terraform: the privileges of its sequence are read from its own ACL,
+				// so the missing USAGE is a drift.
+				PreConfig: func() {
+					dbExecute(t, dsn, "CREATE TABLE test_schema.test_serial2 (id serial)")
+					dbExecute(t, dsn, fmt.Sprintf("GRANT INSERT ON test_schema.test_serial2 TO %s", roleName))
+				},
+				Config:             testGrant,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, fmt.Sprintf("GRANT USAGE ON test_schema.test_serial2_id_seq TO %s", roleName))
+				},
+				Config:   testGrant,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// Test that destroying a grant on tables owned by the grantee
+// does not make it lose the privileges on its own tables.
+func TestAccPostgresqlGrantOwnerDestroy(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	testTables := []string{"test_schema.test_table", "test_schema.test_table2"}
+	createTestTables(t, dbSuffix, testTables[:1], roleName)
+	createTestTables(t, dbSuffix, testTables[1:], "")
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = ["SELECT"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testGrant,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+					},
+				),
+			},
+			{
+				Config:  testGrant,
+				Destroy: true,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables[:1], []string{"SELECT", "INSERT", "UPDATE", "DELETE"})
+					},
+					func(*terraform.State) error {
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables[1:], []string{})
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantObjectsError(t *testing.T) {
+	skipIfNotAcc(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `resource "postgresql_grant" "test" {
+					database    = "test_db"
+					role        = "test_role"
+					object_type = "database"
+					objects     = ["o1", "o2"]
+					privileges  = ["CONNECT"]
+				}`,
+				ExpectError: regexp.MustCompile("cannot specify `objects` when `object_type` is `database` or `schema`"),
+			},
+			{
+				Config: `resource "postgresql_grant" "test" {
+					database    = "test_db"
+					schema      = "test_schema"
+					role        = "test_role"
+					object_type = "schema"
+					objects     = ["o1", "o2"]
+					privileges  = ["CONNECT"]
+				}`,
+				ExpectError: regexp.MustCompile("cannot specify `objects` when `object_type` is `database` or `schema`"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantPublic(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	// We have to create the database outside of resource.Test
+	// because we need to create tables to assert that grant are correctly applied
+	// and we don't have this resource yet
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// create another role (first one is created in setupTestDatabase)
+	// to assert that PUBLIC is applied to everyone
+	role2 := fmt.Sprintf("tf_tests_role2_%s", dbSuffix)
+	createTestRole(t, role2)
+	connStr, _ := config.connStr(dbName)
+	dbExecute(t, connStr, fmt.Sprintf("GRANT usage ON SCHEMA test_schema to %s", role2))
+
+	// create a TF config with placeholder for privileges
+	// it will be filled in each step.
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "public"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges   = %%s
+	}
+	`, dbName)
+
+	// Wrapper to testCheckTablesPrivileges to test for both roles
+	checkTablePrivileges := func(expectedPrivileges []string) error {
+		if err := testCheckTablesPrivileges(t, dbName, roleName, testTables, expectedPrivileges); err != nil {
+			return err
+		}
+		return testCheckTablesPrivileges(t, dbName, role2, testTables, expectedPrivileges)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"postgresql_grant.test", "id", fmt.Sprintf("public_%s_test_schema_table", dbName),
+					),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					func(*terraform.State) error {
+						return checkTablePrivileges([]string{"SELECT"})
+					},
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT", "INSERT", "UPDATE"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "3"),
+					func(*terraform.State) error {
+						return checkTablePrivileges([]string{"SELECT", "INSERT", "UPDATE"})
+					},
+				),
+			},
+			// We reapply the first step to be sure that extra privileges are correctly granted.
+			{
+				Config: fmt.Sprintf(testGrant, `["SELECT"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					func(*terraform.State) error {
+						return checkTablePrivileges([]string{"SELECT"})
+					},
+				),
+			},
+			// We test to revoke everything
+			{
+				Config: fmt.Sprintf(testGrant, `[]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
+					func(*terraform.State) error {
+						return checkTablePrivileges([]string{})
+					},
+				),
+			},
+		},
+	})
+}
+
+// Test the common hardening of the public schema:
+// REVOKE CREATE ON SCHEMA public FROM PUBLIC
+func TestAccPostgresqlGrantPublicSchemaHardening(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testGrant = fmt.Sprintf(`
+	resource "postgresql_grant" "public_schema" {
+		database    = "%s"
+		role        = "public"
+		schema      = "public"
+		object_type = "schema"
+		privileges  = ["USAGE"]
+	}
+	`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testGrant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.public_schema", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.public_schema", "privileges.666868928", "USAGE"),
+					func(*terraform.State) error {
+						db := connectAsTestRole(t, roleName, dbName)
+						defer db.Close()
+						return testHasGrantForQuery(db, "CREATE TABLE public.test_hardening (id serial)", false)
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrantEmptyPrivileges(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table", "test_schema.test_table2"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// Grant some privileges on this table to our role to assert that they will be revoked
+	connStr, _ := config.connStr(dbName)
+	dbExecute(t, connStr, fmt.Sprintf("GRANT SELECT, INSERT ON ALL TABLES IN SCHEMA test_schema TO %s", roleName))
+
+	var tfConfig = fmt.Sprintf(`
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges   = []
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
 				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"postgresql_grant.test", "id", fmt.Sprintf("%s_%s_test_schema_table", roleName, dbName),
+					),
 					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
 					func(*terraform.State) error {
 						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
@@ -249,9 +1659,11 @@ func TestAccPostgresqlGrant(t *testing.T) {
 	})
 }
 
-func TestAccPostgresqlGrantObjects(t *testing.T) {
+func TestAccPostgresqlGrantCopyFromRole(t *testing.T) {
 	skipIfNotAcc(t)
 
+	config := getTestConfig(t)
+
 	dbSuffix, teardown := setupTestDatabase(t, true, true)
 	defer teardown()
 
@@ -259,19 +1671,23 @@ func TestAccPostgresqlGrantObjects(t *testing.T) {
 	createTestTables(t, dbSuffix, testTables, "")
 
 	dbName, roleName := getTestDBNames(dbSuffix)
+	templateRole := roleName + "_template"
 
-	// create a TF config with placeholder for privileges
-	// it will be filled in each step.
-	var testGrant = fmt.Sprintf(`
+	connStr, _ := config.connStr(dbName)
+	dbExecute(t, connStr, fmt.Sprintf("CREATE ROLE %s", templateRole))
+	defer dbExecute(t, connStr, fmt.Sprintf("DROP OWNED BY %[1]s; DROP ROLE %[1]s", templateRole))
+	dbExecute(t, connStr, fmt.Sprintf("GRANT SELECT ON ALL TABLES IN SCHEMA test_schema TO %s", templateRole))
+
+	var tfConfig = fmt.Sprintf(`
 	resource "postgresql_grant" "test" {
-		database    = "%s"
-		role        = "%s"
-		schema      = "test_schema"
-		object_type = "table"
-		objects     = %%s
-		privileges  = ["SELECT"]
+		database       = "%s"
+		role           = "%s"
+		schema         = "test_schema"
+		object_type    = "table"
+		privileges     = []
+		copy_from_role = "%s"
 	}
-	`, dbName, roleName)
+	`, dbName, roleName, templateRole)
 
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -281,62 +1697,38 @@ func TestAccPostgresqlGrantObjects(t *testing.T) {
 		Providers: getTestProvidersForTest(t),
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(testGrant, `["test_table"]`),
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr(
-						"postgresql_grant.test", "id", fmt.Sprintf("%s_%s_test_schema_table_test_table", roleName, dbName),
-					),
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "1"),
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.4260833613", "test_table"),
-					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[0]}, []string{"SELECT"})
-					},
-					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[1]}, []string{})
-					},
-				),
-			},
-			{
-				Config: fmt.Sprintf(testGrant, `["test_table", "test_table2"]`),
+				Config: tfConfig,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "2"),
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.4260833613", "test_table"),
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.306541577", "test_table2"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "copied_privileges.#", "1"),
 					func(*terraform.State) error {
 						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
 					},
 				),
 			},
+			// The privileges added to the template role are granted to the role too
 			{
-				Config: fmt.Sprintf(testGrant, `["test_table"]`),
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "1"),
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.4260833613", "test_table"),
-					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[0]}, []string{"SELECT"})
-					},
-					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, []string{testTables[1]}, []string{})
-					},
-				),
-			},
-			{
-				// Empty list means that privileges will be applied on all tables.
-				Config: fmt.Sprintf(testGrant, `[]`),
+				PreConfig: func() {
+					dbExecute(t, connStr, fmt.Sprintf("GRANT INSERT ON ALL TABLES IN SCHEMA test_schema TO %s", templateRole))
+				},
+				Config: tfConfig,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "copied_privileges.#", "2"),
 					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT"})
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"SELECT", "INSERT"})
 					},
 				),
 			},
+			// The privileges revoked from the template role are revoked from the role too
 			{
-				Config:  fmt.Sprintf(testGrant, `[]`),
-				Destroy: true,
+				PreConfig: func() {
+					dbExecute(t, connStr, fmt.Sprintf("REVOKE SELECT ON ALL TABLES IN SCHEMA test_schema FROM %s", templateRole))
+				},
+				Config: tfConfig,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "0"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "copied_privileges.#", "1"),
 					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
+						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{"INSERT"})
 					},
 				),
 			},
@@ -344,9 +1736,55 @@ func TestAccPostgresqlGrantObjects(t *testing.T) {
 	})
 }
 
-func TestAccPostgresqlGrantObjectsError(t *testing.T) {
+// TestAccPostgresqlGrantInheritedPrivileges checks that only the privileges granted to the role itself
+// are managed, the ones it inherits from its group roles must not be reported as a drift nor revoked.
+func TestAccPostgresqlGrantInheritedPrivileges(t *testing.T) {
 	skipIfNotAcc(t)
 
+	config := getTestConfig(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	testTables := []string{"test_schema.test_table"}
+	createTestTables(t, dbSuffix, testTables, "")
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+	groupRole := roleName + "_group"
+
+	connStr, _ := config.connStr(dbName)
+	dbExecute(t, connStr, fmt.Sprintf("CREATE ROLE %s", groupRole))
+	defer dbExecute(t, connStr, fmt.Sprintf("DROP OWNED BY %[1]s; DROP ROLE %[1]s", groupRole))
+	dbExecute(t, connStr, fmt.Sprintf("GRANT %s TO %s", groupRole, roleName))
+	dbExecute(t, connStr, fmt.Sprintf("GRANT SELECT, UPDATE ON ALL TABLES IN SCHEMA test_schema TO %s", groupRole))
+	dbExecute(t, connStr, fmt.Sprintf("GRANT CREATE ON SCHEMA test_schema TO %s", groupRole))
+	dbExecute(t, connStr, fmt.Sprintf("GRANT CREATE ON DATABASE %s TO %s", dbName, groupRole))
+
+	var tfConfig = fmt.Sprintf(`
+	resource "postgresql_grant" "table" {
+		database    = "%[1]s"
+		role        = "%[2]s"
+		schema      = "test_schema"
+		object_type = "table"
+		privileges  = ["INSERT"]
+	}
+
+	resource "postgresql_grant" "schema" {
+		database    = "%[1]s"
+		role        = "%[2]s"
+		schema      = "test_schema"
+		object_type = "schema"
+		privileges  = ["USAGE"]
+	}
+
+	resource "postgresql_grant" "database" {
+		database    = "%[1]s"
+		role        = "%[2]s"
+		object_type = "database"
+		privileges  = ["CONNECT"]
+	}
+	`, dbName, roleName)
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -355,38 +1793,48 @@ func TestAccPostgresqlGrantObjectsError(t *testing.T) {
 		Providers: getTestProvidersForTest(t),
 		Steps: []resource.TestStep{
 			{
-				Config: `resource "postgresql_grant" "test" {
-					database    = "test_db"
-					role        = "test_role"
-					object_type = "database"
-					objects     = ["o1", "o2"]
-					privileges  = ["CONNECT"]
-				}`,
-				ExpectError: regexp.MustCompile("cannot specify `objects` when `object_type` is `database` or `schema`"),
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.table", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.schema", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.database", "privileges.#", "1"),
+					// The inherited privileges are kept
+					testCheckRoleHasTablePrivilege(t, connStr, groupRole, "test_schema.test_table", "SELECT", true),
+				),
 			},
+			// No drift from the privileges inherited from the group role
 			{
-				Config: `resource "postgresql_grant" "test" {
-					database    = "test_db"
-					schema      = "test_schema"
-					role        = "test_role"
-					object_type = "schema"
-					objects     = ["o1", "o2"]
-					privileges  = ["CONNECT"]
-				}`,
-				ExpectError: regexp.MustCompile("cannot specify `objects` when `object_type` is `database` or `schema`"),
+				Config:   tfConfig,
+				PlanOnly: true,
 			},
 		},
 	})
 }
 
-func TestAccPostgresqlGrantPublic(t *testing.T) {
+func testCheckRoleHasTablePrivilege(t *testing.T, dsn, role, table, privilege string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("could not open connection pool: %v", err)
+		}
+		defer db.Close()
+
+		var granted bool
+		if err := db.QueryRow("SELECT has_table_privilege($1, $2, $3)", role, table, privilege).Scan(&granted); err != nil {
+			return fmt.Errorf("could not check %s privilege of role %s on %s: %w", privilege, role, table, err)
+		}
+		if granted != expected {
+			return fmt.Errorf("role %s has the %s privilege on %s: %t, expected %t", role, privilege, table, granted, expected)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlGrantMultipleRoles(t *testing.T) {
 	skipIfNotAcc(t)
 
 	config := getTestConfig(t)
 
-	// We have to create the database outside of resource.Test
-	// because we need to create tables to assert that grant are correctly applied
-	// and we don't have this resource yet
 	dbSuffix, teardown := setupTestDatabase(t, true, true)
 	defer teardown()
 
@@ -394,33 +1842,22 @@ func TestAccPostgresqlGrantPublic(t *testing.T) {
 	createTestTables(t, dbSuffix, testTables, "")
 
 	dbName, roleName := getTestDBNames(dbSuffix)
+	otherRole := roleName + "_other"
 
-	// create another role (first one is created in setupTestDatabase)
-	// to assert that PUBLIC is applied to everyone
-	role2 := fmt.Sprintf("tf_tests_role2_%s", dbSuffix)
-	createTestRole(t, role2)
 	connStr, _ := config.connStr(dbName)
-	dbExecute(t, connStr, fmt.Sprintf("GRANT usage ON SCHEMA test_schema to %s", role2))
+	dbExecute(t, connStr, fmt.Sprintf("CREATE ROLE %s", otherRole))
+	defer dbExecute(t, connStr, fmt.Sprintf("DROP OWNED BY %[1]s; DROP ROLE %[1]s", otherRole))
 
-	// create a TF config with placeholder for privileges
-	// it will be filled in each step.
-	var testGrant = fmt.Sprintf(`
+	var tfConfig = `
 	resource "postgresql_grant" "test" {
 		database    = "%s"
-		role        = "public"
+		roles       = [%s]
 		schema      = "test_schema"
 		object_type = "table"
-		privileges   = %%s
-	}
-	`, dbName)
-
-	// Wrapper to testCheckTablesPrivileges to test for both roles
-	checkTablePrivileges := func(expectedPrivileges []string) error {
-		if err := testCheckTablesPrivileges(t, dbName, roleName, testTables, expectedPrivileges); err != nil {
-			return err
-		}
-		return testCheckTablesPrivileges(t, dbName, role2, testTables, expectedPrivileges)
+		privileges  = ["SELECT"]
 	}
+	`
+	bothRoles := fmt.Sprintf(tfConfig, dbName, fmt.Sprintf(`"%s", "%s"`, roleName, otherRole))
 
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
@@ -430,51 +1867,72 @@ func TestAccPostgresqlGrantPublic(t *testing.T) {
 		Providers: getTestProvidersForTest(t),
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(testGrant, `["SELECT"]`),
+				Config: bothRoles,
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(
-						"postgresql_grant.test", "id", fmt.Sprintf("public_%s_test_schema_table", dbName),
+						"postgresql_grant.test", "id", fmt.Sprintf("%s,%s_%s_test_schema_table", roleName, otherRole, dbName),
 					),
-					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
-					func(*terraform.State) error {
-						return checkTablePrivileges([]string{"SELECT"})
-					},
-				),
-			},
-			{
-				Config: fmt.Sprintf(testGrant, `["SELECT", "INSERT", "UPDATE"]`),
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "3"),
-					func(*terraform.State) error {
-						return checkTablePrivileges([]string{"SELECT", "INSERT", "UPDATE"})
-					},
+					testCheckRoleHasTablePrivilege(t, connStr, roleName, "test_schema.test_table", "SELECT", true),
+					testCheckRoleHasTablePrivilege(t, connStr, otherRole, "test_schema.test_table", "SELECT", true),
 				),
 			},
-			// We reapply the first step to be sure that extra privileges are correctly granted.
+			// Only one of the roles lost its privilege, it is granted again
 			{
-				Config: fmt.Sprintf(testGrant, `["SELECT"]`),
+				PreConfig: func() {
+					dbExecute(t, connStr, fmt.Sprintf("REVOKE SELECT ON test_schema.test_table FROM %s", otherRole))
+				},
+				Config: bothRoles,
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
-					func(*terraform.State) error {
-						return checkTablePrivileges([]string{"SELECT"})
-					},
+					testCheckRoleHasTablePrivilege(t, connStr, roleName, "test_schema.test_table", "SELECT", true),
+					testCheckRoleHasTablePrivilege(t, connStr, otherRole, "test_schema.test_table", "SELECT", true),
 				),
 			},
-			// We test to revoke everything
+			// The privileges of the role removed from the grant are revoked
 			{
-				Config: fmt.Sprintf(testGrant, `[]`),
+				Config: fmt.Sprintf(tfConfig, dbName, fmt.Sprintf(`"%s"`, roleName)),
 				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
-					func(*terraform.State) error {
-						return checkTablePrivileges([]string{})
-					},
+					resource.TestCheckResourceAttr(
+						"postgresql_grant.test", "id", fmt.Sprintf("%s_%s_test_schema_table", roleName, dbName),
+					),
+					testCheckRoleHasTablePrivilege(t, connStr, roleName, "test_schema.test_table", "SELECT", true),
+					testCheckRoleHasTablePrivilege(t, connStr, otherRole, "test_schema.test_table", "SELECT", false),
 				),
 			},
 		},
 	})
 }
 
-func TestAccPostgresqlGrantEmptyPrivileges(t *testing.T) {
+func TestParseGrantImportID(t *testing.T) {
+	var tests = []struct {
+		id      string
+		want    []string
+		wantErr bool
+	}{
+		{"mydb/test_role", []string{"mydb", "", "", "test_role"}, false},
+		{"mydb/test_schema/test_role", []string{"mydb", "test_schema", "", "test_role"}, false},
+		{"mydb/test_schema/test_table/test_role", []string{"mydb", "test_schema", "test_table", "test_role"}, false},
+		{"mydb", nil, true},
+		{"mydb//test_role", nil, true},
+		{"mydb/test_schema/test_table/test_role/other", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			database, pgSchema, object, role, err := parseGrantImportID(test.id)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseGrantImportID() error = %v, want error %t", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := []string{database, pgSchema, object, role}; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseGrantImportID() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAccPostgresqlGrantImport(t *testing.T) {
 	skipIfNotAcc(t)
 
 	config := getTestConfig(t)
@@ -482,22 +1940,32 @@ func TestAccPostgresqlGrantEmptyPrivileges(t *testing.T) {
 	dbSuffix, teardown := setupTestDatabase(t, true, true)
 	defer teardown()
 
-	testTables := []string{"test_schema.test_table", "test_schema.test_table2"}
+	testTables := []string{"test_schema.test_table"}
 	createTestTables(t, dbSuffix, testTables, "")
 
 	dbName, roleName := getTestDBNames(dbSuffix)
 
-	// Grant some privileges on this table to our role to assert that they will be revoked
 	connStr, _ := config.connStr(dbName)
-	dbExecute(t, connStr, fmt.Sprintf("GRANT SELECT, INSERT ON ALL TABLES IN SCHEMA test_schema TO %s", roleName))
+	dbExecute(t, connStr, fmt.Sprintf("GRANT SELECT, INSERT ON test_schema.test_table TO %s WITH GRANT OPTION", roleName))
+	dbExecute(t, connStr, fmt.Sprintf("GRANT USAGE ON SCHEMA test_schema TO %s", roleName))
 
 	var tfConfig = fmt.Sprintf(`
-	resource "postgresql_grant" "test" {
-		database    = "%s"
-		role        = "%s"
+	resource "postgresql_grant" "table" {
+		database          = "%[1]s"
+		role              = "%[2]s"
+		schema            = "test_schema"
+		object_type       = "table"
+		objects           = ["test_table"]
+		privileges        = ["INSERT", "SELECT"]
+		with_grant_option = true
+	}
+
+	resource "postgresql_grant" "schema" {
+		database    = "%[1]s"
+		role        = "%[2]s"
 		schema      = "test_schema"
-		object_type = "table"
-		privileges   = []
+		object_type = "schema"
+		privileges  = ["USAGE"]
 	}
 	`, dbName, roleName)
 
@@ -509,16 +1977,32 @@ func TestAccPostgresqlGrantEmptyPrivileges(t *testing.T) {
 		Providers: getTestProvidersForTest(t),
 		Steps: []resource.TestStep{
 			{
-				Config: tfConfig,
-				Check: resource.ComposeTestCheckFunc(
-					resource.TestCheckResourceAttr(
-						"postgresql_grant.test", "id", fmt.Sprintf("%s_%s_test_schema_table", roleName, dbName),
-					),
-					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "0"),
-					func(*terraform.State) error {
-						return testCheckTablesPrivileges(t, dbName, roleName, testTables, []string{})
-					},
-				),
+				Config:        tfConfig,
+				ResourceName:  "postgresql_grant.table",
+				ImportState:   true,
+				ImportStateId: fmt.Sprintf("%s/test_schema/test_table/%s", dbName, roleName),
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 imported grant, got %d", len(states))
+					}
+					attrs := states[0].Attributes
+					if attrs["object_type"] != "table" || attrs["with_grant_option"] != "true" || attrs["privileges.#"] != "2" {
+						return fmt.Errorf("unexpected imported grant: %v", attrs)
+					}
+					return nil
+				},
+			},
+			{
+				Config:        tfConfig,
+				ResourceName:  "postgresql_grant.schema",
+				ImportState:   true,
+				ImportStateId: fmt.Sprintf("%s/test_schema/%s", dbName, roleName),
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["object_type"] != "schema" {
+						return fmt.Errorf("unexpected imported grant: %v", states)
+					}
+					return nil
+				},
 			},
 		},
 	})
@@ -584,6 +2068,58 @@ resource postgresql_grant "test" {
 	}
 }
 
+func TestAccPostgresqlGrantType(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+
+	// Create a test role and a schema as public has too wide open privileges
+	dbExecute(t, dsn, fmt.Sprintf("CREATE ROLE test_role LOGIN PASSWORD '%s'", testRolePassword))
+	dbExecute(t, dsn, "CREATE SCHEMA test_schema")
+	dbExecute(t, dsn, "GRANT USAGE ON SCHEMA test_schema TO test_role")
+
+	// Types and domains are usable by public by default
+	dbExecute(t, dsn, "CREATE TYPE test_schema.test_enum AS ENUM ('a', 'b')")
+	dbExecute(t, dsn, "CREATE DOMAIN test_schema.test_domain AS text")
+	dbExecute(t, dsn, "REVOKE ALL ON TYPE test_schema.test_enum, test_schema.test_domain FROM public")
+	defer func() {
+		dbExecute(t, dsn, "DROP SCHEMA test_schema CASCADE")
+		dbExecute(t, dsn, "DROP ROLE test_role")
+	}()
+
+	tfConfig := `
+resource postgresql_grant "test" {
+  database    = "postgres"
+  role        = "test_role"
+  schema      = "test_schema"
+  object_type = "type"
+  objects     = ["test_enum", "test_domain"]
+  privileges  = ["USAGE"]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test", "objects.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "with_grant_option", "false"),
+					testCheckTypeUsable(t, "test_role", "test_schema.test_enum"),
+					testCheckTypeUsable(t, "test_role", "test_schema.test_domain"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccPostgresqlGrantDatabase(t *testing.T) {
 	// create a TF config with placeholder for privileges
 	// it will be filled in each step.
@@ -728,6 +2264,16 @@ func testCheckFunctionExecutable(t *testing.T, role, function string) func(*terr
 	}
 }
 
+func testCheckTypeUsable(t *testing.T, role, typeName string) func(*terraform.State) error {
+	return func(*terraform.State) error {
+		db := connectAsTestRole(t, role, "postgres")
+		defer db.Close()
+
+		// USAGE on a type is not checked for casts, only when it is used in a definition
+		return testHasGrantForQuery(db, fmt.Sprintf("CREATE TEMPORARY TABLE test_type_usage (val %s)", typeName), true)
+	}
+}
+
 func testCheckSchemaPrivileges(t *testing.T, usage, create bool) func(*terraform.State) error {
 	return func(*terraform.State) error {
 		config := getTestConfig(t)
@@ -0,0 +1,170 @@
+package postgresql
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	maintenanceOperationAttr  = "operation"
+	maintenanceDatabaseAttr   = "database"
+	maintenanceSchemaAttr     = "schema"
+	maintenanceObjectAttr     = "object"
+	maintenanceTriggerAttr    = "trigger"
+	maintenanceExecutedAtAttr = "executed_at"
+)
+
+// Maintenance operations
+const (
+	maintenanceAnalyze  = "analyze"
+	maintenanceVacuum   = "vacuum"
+	maintenanceReindex  = "reindex"
+	maintenanceTruncate = "truncate"
+)
+
+func resourcePostgreSQLMaintenance() *schema.Resource {
+	return &schema.Resource{
+		Create: PGResourceFunc(resourcePostgreSQLMaintenanceCreate),
+		Read:   PGResourceFunc(resourcePostgreSQLMaintenanceRead),
+		Delete: PGResourceFunc(resourcePostgreSQLMaintenanceDelete),
+
+		// All the attributes are ForceNew: the operation is executed again when any of them changes
+		Schema: map[string]*schema.Schema{
+			maintenanceOperationAttr: {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					maintenanceAnalyze,
+					maintenanceVacuum,
+					maintenanceReindex,
+					maintenanceTruncate,
+				}, false),
+				Description: "The maintenance operation to execute (analyze, vacuum, reindex or truncate)",
+			},
+			maintenanceDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database to execute the operation in",
+			},
+			maintenanceSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema of the table",
+			},
+			maintenanceObjectAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The table to execute the operation on (empty for the whole database, except for truncate)",
+			},
+			maintenanceTriggerAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Any value whose change executes the operation again",
+			},
+			maintenanceExecutedAtAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the operation was executed (RFC 3339)",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLMaintenanceCreate(db *DBConnection, d *schema.ResourceData) error {
+	if err := db.checkBackendSupported("postgresql_maintenance"); err != nil {
+		return err
+	}
+
+	operation := d.Get(maintenanceOperationAttr).(string)
+	database := getDatabase(d, db.client.databaseName)
+	schemaName := d.Get(maintenanceSchemaAttr).(string)
+	object := d.Get(maintenanceObjectAttr).(string)
+
+	query, err := maintenanceQuery(operation, database, schemaName, object)
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.client.forDatabase(database).Connect()
+	if err != nil {
+		return err
+	}
+
+	// Not in a transaction: VACUUM and REINDEX DATABASE cannot be executed in one
+	log.Printf("[DEBUG] executing maintenance operation in database %s: %s", database, query)
+	if _, err := conn.Exec(query); err != nil {
+		return fmt.Errorf("could not execute %s in database %s: %w", operation, database, err)
+	}
+
+	_ = d.Set(maintenanceDatabaseAttr, database)
+	_ = d.Set(maintenanceExecutedAtAttr, time.Now().UTC().Format(time.RFC3339))
+	d.SetId(strings.Join([]string{database, operation, schemaName, object}, "."))
+
+	return nil
+}
+
+func resourcePostgreSQLMaintenanceRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	// The operation is executed again if the database is recreated
+	exists, err := dbExists(db, database)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Printf("[WARN] database %s of maintenance operation %s not found", database, d.Id())
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLMaintenanceDelete(db *DBConnection, d *schema.ResourceData) error {
+	// Nothing to undo
+	d.SetId("")
+	return nil
+}
+
+// maintenanceQuery returns the statement executing the operation on the table,
+// or on the whole database if no table is specified.
+func maintenanceQuery(operation, database, schemaName, object string) (string, error) {
+	var table string
+	if object != "" {
+		table = fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(object))
+	}
+
+	switch operation {
+	case maintenanceAnalyze, maintenanceVacuum:
+		if table == "" {
+			return strings.ToUpper(operation), nil
+		}
+		return fmt.Sprintf("%s %s", strings.ToUpper(operation), table), nil
+
+	case maintenanceReindex:
+		if table == "" {
+			return fmt.Sprintf("REINDEX DATABASE %s", pq.QuoteIdentifier(database)), nil
+		}
+		return fmt.Sprintf("REINDEX TABLE %s", table), nil
+
+	case maintenanceTruncate:
+		if table == "" {
+			return "", fmt.Errorf("`object` is required to truncate a table")
+		}
+		return fmt.Sprintf("TRUNCATE TABLE %s", table), nil
+	}
+
+	return "", fmt.Errorf("unknown maintenance operation %s", operation)
+}
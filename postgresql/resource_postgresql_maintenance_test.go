@@ -0,0 +1,121 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestMaintenanceQuery(t *testing.T) {
+	var tests = []struct {
+		operation string
+		object    string
+		want      string
+		wantErr   bool
+	}{
+		{maintenanceAnalyze, "", "ANALYZE", false},
+		{maintenanceAnalyze, "my table", `ANALYZE "my_schema"."my table"`, false},
+		{maintenanceVacuum, "", "VACUUM", false},
+		{maintenanceVacuum, "my table", `VACUUM "my_schema"."my table"`, false},
+		{maintenanceReindex, "", `REINDEX DATABASE "my_db"`, false},
+		{maintenanceReindex, "my table", `REINDEX TABLE "my_schema"."my table"`, false},
+		{maintenanceTruncate, "my table", `TRUNCATE TABLE "my_schema"."my table"`, false},
+		// The whole database cannot be truncated
+		{maintenanceTruncate, "", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := maintenanceQuery(test.operation, "my_db", "my_schema", test.object)
+		if (err != nil) != test.wantErr {
+			t.Errorf("maintenanceQuery(%s, %q) error = %v, want error %t", test.operation, test.object, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("maintenanceQuery(%s, %q) = %q, want %q", test.operation, test.object, got, test.want)
+		}
+	}
+}
+
+func TestAccPostgresqlMaintenance(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	createTestTables(t, dbSuffix, []string{"test_schema.test_table"}, "")
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	dsn, _ := config.connStr(dbName)
+	insertRows := func() {
+		dbExecute(t, dsn, "INSERT INTO test_schema.test_table VALUES ('a'), ('b')")
+	}
+	insertRows()
+
+	tfConfig := `
+resource "postgresql_maintenance" "vacuum" {
+	database  = "%[1]s"
+	operation = "vacuum"
+	schema    = "test_schema"
+	object    = "test_table"
+}
+
+resource "postgresql_maintenance" "truncate" {
+	database  = "%[1]s"
+	operation = "truncate"
+	schema    = "test_schema"
+	object    = "test_table"
+	trigger   = "%[2]s"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("postgresql_maintenance.vacuum", "executed_at"),
+					resource.TestCheckResourceAttrSet("postgresql_maintenance.truncate", "executed_at"),
+					testCheckTableRowCount(t, dsn, "test_schema.test_table", 0),
+				),
+			},
+			// Not executed again while the configuration is unchanged
+			{
+				PreConfig: insertRows,
+				Config:    fmt.Sprintf(tfConfig, dbName, "1"),
+				Check:     testCheckTableRowCount(t, dsn, "test_schema.test_table", 2),
+			},
+			// Executed again when the trigger changes
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, "2"),
+				Check:  testCheckTableRowCount(t, dsn, "test_schema.test_table", 0),
+			},
+		},
+	})
+}
+
+func testCheckTableRowCount(t *testing.T, dsn, table string, expected int) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("could not open connection pool: %v", err)
+		}
+		defer db.Close()
+
+		var count int
+		if err := db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s", table)).Scan(&count); err != nil {
+			return fmt.Errorf("could not count the rows of %s: %w", table, err)
+		}
+		if count != expected {
+			return fmt.Errorf("table %s has %d rows, expected %d", table, count, expected)
+		}
+		return nil
+	}
+}
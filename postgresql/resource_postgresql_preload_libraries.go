@@ -0,0 +1,296 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	preloadSettingAttr          = "setting"
+	preloadLibrariesAttr        = "libraries"
+	preloadRunningLibrariesAttr = "running_libraries"
+	preloadRestartRequiredAttr  = "restart_required"
+)
+
+// preloadLibrariesLock serializes the read-modify-write of the preload settings,
+// as several resources can manage libraries of the same setting.
+var preloadLibrariesLock sync.Mutex
+
+func resourcePostgreSQLPreloadLibraries() *schema.Resource {
+	return &schema.Resource{
+		Create: PGResourceFunc(resourcePostgreSQLPreloadLibrariesCreate),
+		Read:   PGResourceFunc(resourcePostgreSQLPreloadLibrariesRead),
+		Update: PGResourceFunc(resourcePostgreSQLPreloadLibrariesUpdate),
+		Delete: PGResourceFunc(resourcePostgreSQLPreloadLibrariesDelete),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			preloadSettingAttr: {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "shared_preload_libraries",
+				ValidateFunc: validation.StringInSlice([]string{
+					"shared_preload_libraries",
+					"session_preload_libraries",
+					"local_preload_libraries",
+				}, false),
+				Description: "The setting listing the libraries to preload",
+			},
+			preloadLibrariesAttr: {
+				Type:        schema.TypeSet,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The libraries to add to the setting (the other libraries of the setting are kept)",
+			},
+			preloadRunningLibrariesAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The libraries of the setting currently used by the server",
+			},
+			preloadRestartRequiredAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "If the server has to be restarted to use the libraries configured with ALTER SYSTEM",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLPreloadLibrariesCreate(db *DBConnection, d *schema.ResourceData) error {
+	if err := checkPreloadLibrariesSupported(db); err != nil {
+		return err
+	}
+
+	setting := d.Get(preloadSettingAttr).(string)
+	libraries := d.Get(preloadLibrariesAttr).(*schema.Set).List()
+	if err := updatePreloadLibraries(db, setting, nil, libraries); err != nil {
+		return err
+	}
+
+	d.SetId(setting)
+
+	return resourcePostgreSQLPreloadLibrariesReadImpl(db, d)
+}
+
+func resourcePostgreSQLPreloadLibrariesRead(db *DBConnection, d *schema.ResourceData) error {
+	if err := checkPreloadLibrariesSupported(db); err != nil {
+		return err
+	}
+
+	return resourcePostgreSQLPreloadLibrariesReadImpl(db, d)
+}
+
+func resourcePostgreSQLPreloadLibrariesReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	setting := d.Id()
+
+	pending, err := readPendingPreloadLibraries(db, setting)
+	if err != nil {
+		return err
+	}
+
+	var runningValue, context string
+	var pendingRestart bool
+	err = db.QueryRow(
+		"SELECT setting, context, pending_restart FROM pg_catalog.pg_settings WHERE name = $1", setting,
+	).Scan(&runningValue, &context, &pendingRestart)
+	if err != nil {
+		return fmt.Errorf("could not read setting %s: %w", setting, err)
+	}
+	running := parsePreloadLibraries(runningValue)
+
+	// Only the libraries managed by the resource are compared with the configuration
+	// (all of them when importing)
+	configured := d.Get(preloadLibrariesAttr).(*schema.Set)
+	libraries := schema.NewSet(schema.HashString, nil)
+	for _, library := range pending {
+		if configured.Len() == 0 || configured.Contains(library) {
+			libraries.Add(library)
+		}
+	}
+
+	// pending_restart is only set once the configuration has been reloaded by the server
+	// (asynchronously after pg_reload_conf), so the running libraries are compared too.
+	restartRequired := pendingRestart || (context == "postmaster" && !sameLibraries(running, pending))
+
+	_ = d.Set(preloadSettingAttr, setting)
+	_ = d.Set(preloadLibrariesAttr, libraries)
+	_ = d.Set(preloadRunningLibrariesAttr, running)
+	_ = d.Set(preloadRestartRequiredAttr, restartRequired)
+
+	return nil
+}
+
+func resourcePostgreSQLPreloadLibrariesUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if err := checkPreloadLibrariesSupported(db); err != nil {
+		return err
+	}
+
+	oldRaw, newRaw := d.GetChange(preloadLibrariesAttr)
+	removed := oldRaw.(*schema.Set).Difference(newRaw.(*schema.Set)).List()
+	if err := updatePreloadLibraries(db, d.Id(), removed, newRaw.(*schema.Set).List()); err != nil {
+		return err
+	}
+
+	return resourcePostgreSQLPreloadLibrariesReadImpl(db, d)
+}
+
+func resourcePostgreSQLPreloadLibrariesDelete(db *DBConnection, d *schema.ResourceData) error {
+	if err := checkPreloadLibrariesSupported(db); err != nil {
+		return err
+	}
+
+	libraries := d.Get(preloadLibrariesAttr).(*schema.Set).List()
+	if err := updatePreloadLibraries(db, d.Id(), libraries, nil); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func checkPreloadLibrariesSupported(db *DBConnection) error {
+	if err := db.checkBackendSupported("postgresql_preload_libraries"); err != nil {
+		return err
+	}
+	if !db.featureSupported(featureAlterSystem) {
+		return unsupportedVersionError(db, "postgresql_preload_libraries resource")
+	}
+	return nil
+}
+
+// updatePreloadLibraries removes and adds libraries to the setting with ALTER SYSTEM,
+// keeping the other libraries of the setting, and reloads the configuration.
+func updatePreloadLibraries(db *DBConnection, setting string, remove, add []interface{}) error {
+	preloadLibrariesLock.Lock()
+	defer preloadLibrariesLock.Unlock()
+
+	pending, err := readPendingPreloadLibraries(db, setting)
+	if err != nil {
+		return err
+	}
+
+	libraries := mergePreloadLibraries(pending, remove, add)
+	if sameLibraries(libraries, pending) {
+		return nil
+	}
+
+	// ALTER SYSTEM cannot be executed in a transaction
+	var query string
+	if len(libraries) == 0 {
+		query = fmt.Sprintf("ALTER SYSTEM RESET %s", pq.QuoteIdentifier(setting))
+	} else {
+		quoted := make([]string, len(libraries))
+		for i, library := range libraries {
+			quoted[i] = fmt.Sprintf("'%s'", pqQuoteLiteral(library))
+		}
+		query = fmt.Sprintf("ALTER SYSTEM SET %s = %s", pq.QuoteIdentifier(setting), strings.Join(quoted, ", "))
+	}
+	if _, err := db.Exec(query); err != nil {
+		if isInsufficientPrivilege(err) {
+			return fmt.Errorf(
+				"could not update %s, the connected user must be a superuser or, since PostgreSQL 15, "+
+					"be granted ALTER SYSTEM on the parameter: %w",
+				setting, err,
+			)
+		}
+		return fmt.Errorf("could not update %s: %w", setting, err)
+	}
+
+	if _, err := db.Exec("SELECT pg_catalog.pg_reload_conf()"); err != nil {
+		return fmt.Errorf("could not reload the configuration: %w", err)
+	}
+	log.Printf("[DEBUG] %s set to %v, the server may need to be restarted", setting, libraries)
+
+	return nil
+}
+
+// readPendingPreloadLibraries returns the libraries the setting will have once the configuration
+// is reloaded (or the server restarted): the last value of the setting in the configuration files
+// (including postgresql.auto.conf written by ALTER SYSTEM), or its current value if it is not set in them.
+// pg_file_settings can only be read by superusers by default, the current value is used otherwise.
+func readPendingPreloadLibraries(db *DBConnection, setting string) ([]string, error) {
+	var value string
+	err := db.QueryRow(
+		"SELECT setting FROM pg_catalog.pg_file_settings WHERE name = $1 ORDER BY seqno DESC LIMIT 1", setting,
+	).Scan(&value)
+	switch {
+	case err == sql.ErrNoRows:
+	case isInsufficientPrivilege(err):
+		log.Printf(
+			"[WARN] could not read %s from pg_file_settings (%v), using its current value: "+
+				"the value set with ALTER SYSTEM is not known until the server is restarted",
+			setting, err,
+		)
+	case err != nil:
+		return nil, fmt.Errorf("could not read setting %s from the configuration files: %w", setting, err)
+	default:
+		return parsePreloadLibraries(value), nil
+	}
+
+	if err := db.QueryRow("SELECT pg_catalog.current_setting($1)", setting).Scan(&value); err != nil {
+		return nil, fmt.Errorf("could not read setting %s: %w", setting, err)
+	}
+	return parsePreloadLibraries(value), nil
+}
+
+// parsePreloadLibraries parses the comma-separated list of libraries of a preload setting.
+func parsePreloadLibraries(value string) []string {
+	libraries := []string{}
+	for _, library := range strings.Split(value, ",") {
+		library = strings.Trim(strings.TrimSpace(library), `"`)
+		if library != "" {
+			libraries = append(libraries, library)
+		}
+	}
+	return libraries
+}
+
+// mergePreloadLibraries removes and adds libraries to the current ones, keeping their order
+// (the libraries are loaded in this order) and appending the added libraries.
+func mergePreloadLibraries(current []string, remove, add []interface{}) []string {
+	removed := map[string]bool{}
+	for _, library := range remove {
+		removed[library.(string)] = true
+	}
+
+	merged := []string{}
+	present := map[string]bool{}
+	for _, library := range current {
+		if !removed[library] && !present[library] {
+			merged = append(merged, library)
+			present[library] = true
+		}
+	}
+	for _, library := range add {
+		if !present[library.(string)] {
+			merged = append(merged, library.(string))
+			present[library.(string)] = true
+		}
+	}
+	return merged
+}
+
+func sameLibraries(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestParsePreloadLibraries(t *testing.T) {
+	var tests = []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{}},
+		{"pg_stat_statements", []string{"pg_stat_statements"}},
+		{"pg_stat_statements,auto_explain", []string{"pg_stat_statements", "auto_explain"}},
+		{` pg_stat_statements , "auto_explain",`, []string{"pg_stat_statements", "auto_explain"}},
+	}
+
+	for _, test := range tests {
+		if got := parsePreloadLibraries(test.value); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parsePreloadLibraries(%q) = %#v, want %#v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestMergePreloadLibraries(t *testing.T) {
+	var tests = []struct {
+		current []string
+		remove  []interface{}
+		add     []interface{}
+		want    []string
+	}{
+		{[]string{}, nil, []interface{}{"pg_stat_statements"}, []string{"pg_stat_statements"}},
+		// The libraries not managed by the resource are kept in their order
+		{[]string{"pg_cron", "auto_explain"}, nil, []interface{}{"pg_stat_statements"}, []string{"pg_cron", "auto_explain", "pg_stat_statements"}},
+		{[]string{"pg_cron", "pg_stat_statements"}, nil, []interface{}{"pg_stat_statements"}, []string{"pg_cron", "pg_stat_statements"}},
+		{[]string{"pg_cron", "pg_stat_statements"}, []interface{}{"pg_stat_statements"}, nil, []string{"pg_cron"}},
+		{[]string{"pg_stat_statements", "auto_explain"}, []interface{}{"auto_explain"}, []interface{}{"pg_stat_statements", "pg_cron"}, []string{"pg_stat_statements", "pg_cron"}},
+		{[]string{"pg_cron", "pg_cron"}, nil, nil, []string{"pg_cron"}},
+	}
+
+	for _, test := range tests {
+		if got := mergePreloadLibraries(test.current, test.remove, test.add); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("mergePreloadLibraries(%v, %v, %v) = %#v, want %#v", test.current, test.remove, test.add, got, test.want)
+		}
+	}
+}
+
+func TestAccPostgresqlPreloadLibraries_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureAlterSystem)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlPreloadLibrariesDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "postgresql_preload_libraries" "preload" {
+					libraries = ["pg_stat_statements"]
+				}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_preload_libraries.preload", "id", "shared_preload_libraries"),
+					resource.TestCheckResourceAttr("postgresql_preload_libraries.preload", "libraries.#", "1"),
+					// The server is not restarted by the tests
+					resource.TestCheckResourceAttr("postgresql_preload_libraries.preload", "restart_required", "true"),
+					testAccCheckPendingPreloadLibrary(t, "pg_stat_statements", true),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlPreloadLibrariesDestroy(t *testing.T) func(s *terraform.State) error {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "postgresql_preload_libraries" {
+				continue
+			}
+			if err := testAccCheckPendingPreloadLibrary(t, "pg_stat_statements", false)(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckPendingPreloadLibrary(t *testing.T, library string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := getTestProvider(t).Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		pending, err := readPendingPreloadLibraries(db, "shared_preload_libraries")
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, pendingLibrary := range pending {
+			found = found || pendingLibrary == library
+		}
+		if found != expected {
+			return fmt.Errorf("library %s in pending shared_preload_libraries %v: %t, want %t", library, pending, found, expected)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,260 @@
+package postgresql
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	queryDatabaseAttr        = "database"
+	queryCreateAttr          = "create"
+	queryUpdateAttr          = "update"
+	queryDeleteAttr          = "delete"
+	queryReadAttr            = "read"
+	querySensitiveAttr       = "sensitive"
+	queryTransactionAttr     = "transaction"
+	queryResultAttr          = "result"
+	querySensitiveResultAttr = "sensitive_result"
+)
+
+// resourcePostgreSQLQuery executes arbitrary statements, for the objects the provider does not manage.
+// It is an escape hatch: the provider cannot check what the statements do.
+func resourcePostgreSQLQuery() *schema.Resource {
+	return &schema.Resource{
+		Create: PGResourceFunc(resourcePostgreSQLQueryCreate),
+		Read:   PGResourceFunc(resourcePostgreSQLQueryRead),
+		Update: PGResourceFunc(resourcePostgreSQLQueryUpdate),
+		Delete: PGResourceFunc(resourcePostgreSQLQueryDelete),
+
+		CustomizeDiff: resourcePostgreSQLQueryCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			queryDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database to execute the statements in",
+			},
+			queryCreateAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The statements executed to create the objects",
+			},
+			queryUpdateAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The statements executed when create or update change (the resource is recreated if empty)",
+			},
+			queryDeleteAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The statements executed to drop the objects",
+			},
+			queryReadAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The query reading the objects, the resource is created again if it returns no rows",
+			},
+			querySensitiveAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, the rows returned by the read query are stored in sensitive_result instead of result",
+			},
+			queryTransactionAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "If false, the statements are not executed in a transaction (e.g.: for CREATE INDEX CONCURRENTLY)",
+			},
+			queryResultAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The rows returned by the read query (column name to value)",
+				Elem: &schema.Schema{
+					Type: schema.TypeMap,
+					Elem: &schema.Schema{Type: schema.TypeString},
+				},
+			},
+			querySensitiveResultAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The rows returned by the read query if sensitive is true",
+				Elem: &schema.Schema{
+					Type: schema.TypeMap,
+					Elem: &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+// resourcePostgreSQLQueryCustomizeDiff plans the recreation of the resource when the create statements change
+// and there are no update statements to apply the change instead.
+func resourcePostgreSQLQueryCustomizeDiff(d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || !d.HasChange(queryCreateAttr) || d.Get(queryUpdateAttr).(string) != "" {
+		return nil
+	}
+	return d.ForceNew(queryCreateAttr)
+}
+
+func resourcePostgreSQLQueryCreate(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	if err := execQueryStatements(db, d, database, d.Get(queryCreateAttr).(string)); err != nil {
+		return fmt.Errorf("could not execute create statements: %w", err)
+	}
+
+	_ = d.Set(queryDatabaseAttr, database)
+	d.SetId(generateQueryID(database, d.Get(queryCreateAttr).(string)))
+
+	return resourcePostgreSQLQueryRead(db, d)
+}
+
+func resourcePostgreSQLQueryRead(db *DBConnection, d *schema.ResourceData) error {
+	database := getDatabase(d, db.client.databaseName)
+
+	exists, err := dbExists(db, database)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Printf("[WARN] database %s of query %s not found", database, d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	readQuery := d.Get(queryReadAttr).(string)
+	if readQuery == "" {
+		return nil
+	}
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	rows, err := readQueryRows(txn, readQuery)
+	if err != nil {
+		return fmt.Errorf("could not execute read query: %w", err)
+	}
+	if len(rows) == 0 {
+		log.Printf("[WARN] read query of %s returned no rows, the objects will be created again", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if d.Get(querySensitiveAttr).(bool) {
+		_ = d.Set(querySensitiveResultAttr, rows)
+		_ = d.Set(queryResultAttr, nil)
+	} else {
+		_ = d.Set(queryResultAttr, rows)
+		_ = d.Set(querySensitiveResultAttr, nil)
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLQueryUpdate(db *DBConnection, d *schema.ResourceData) error {
+	updateStatements := d.Get(queryUpdateAttr).(string)
+	if updateStatements != "" && (d.HasChange(queryCreateAttr) || d.HasChange(queryUpdateAttr)) {
+		if err := execQueryStatements(db, d, getDatabase(d, db.client.databaseName), updateStatements); err != nil {
+			return fmt.Errorf("could not execute update statements: %w", err)
+		}
+	}
+
+	return resourcePostgreSQLQueryRead(db, d)
+}
+
+func resourcePostgreSQLQueryDelete(db *DBConnection, d *schema.ResourceData) error {
+	deleteStatements := d.Get(queryDeleteAttr).(string)
+	if deleteStatements == "" {
+		log.Printf("[WARN] no delete statements for query %s, it is only removed from the state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	if err := execQueryStatements(db, d, getDatabase(d, db.client.databaseName), deleteStatements); err != nil {
+		return fmt.Errorf("could not execute delete statements: %w", err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// execQueryStatements executes the statements in the database, in a transaction unless transaction is false
+// or one of them cannot be executed in a transaction block (see nonTransactionalStatements).
+// They are sent in a single simple query, so several statements can be separated with semicolons.
+// As they may change any object, the cached privileges of the objects of the database are invalidated.
+func execQueryStatements(db *DBConnection, d *schema.ResourceData, database, statements string) error {
+	defer invalidateCachedObjectsPrivileges(db.client, database, "", "")
+
+	transaction := d.Get(queryTransactionAttr).(bool)
+	if transaction && isNonTransactionalStatement(statements) {
+		log.Printf("[WARN] The statements in database %s cannot be executed in a transaction, they are executed without", database)
+		transaction = false
+	}
+
+	if !transaction {
+		// Not retried as the statements may not be safe to replay outside of a transaction
+		conn, err := db.client.forDatabase(database).Connect()
+		if err != nil {
+			return err
+		}
+		_, err = conn.Exec(statements)
+		return err
+	}
+
+	return withTransaction(db.client, database, func(txn *sql.Tx) error {
+		_, err := txn.Exec(statements)
+		return err
+	})
+}
+
+// readQueryRows returns the rows of the query as maps of column name to value (empty for NULL).
+func readQueryRows(db QueryAble, query string) ([]interface{}, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = values[i].String
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// generateQueryID returns the ID of the resource from the statements which created it,
+// which do not change it when they are updated.
+func generateQueryID(database, createStatements string) string {
+	return strings.Join([]string{database, fmt.Sprintf("%x", sha256.Sum256([]byte(createStatements)))[:16]}, ".")
+}
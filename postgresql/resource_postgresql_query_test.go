@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccPostgresqlQuery(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	dsn, _ := config.connStr(dbName)
+
+	tfConfig := `
+resource "postgresql_query" "table" {
+  database = "%s"
+  create   = "CREATE TABLE test_schema.query_table (id int); COMMENT ON TABLE test_schema.query_table IS '%s'"
+  update   = "COMMENT ON TABLE test_schema.query_table IS '%s'"
+  delete   = "DROP TABLE test_schema.query_table"
+  read     = <<-EOT
+    SELECT obj_description('test_schema.query_table'::regclass) AS comment
+    FROM pg_catalog.pg_tables WHERE schemaname = 'test_schema' AND tablename = 'query_table'
+  EOT
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, "v1", "v1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_query.table", "result.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_query.table", "result.0.comment", "v1"),
+					testCheckTableRowCount(t, dsn, "test_schema.query_table", 0),
+				),
+			},
+			// Updated with the update statements instead of being recreated
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "INSERT INTO test_schema.query_table VALUES (1)")
+				},
+				Config: fmt.Sprintf(tfConfig, dbName, "v2", "v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_query.table", "result.0.comment", "v2"),
+					testCheckTableRowCount(t, dsn, "test_schema.query_table", 1),
+				),
+			},
+			// Created again when the read query returns no rows
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "DROP TABLE test_schema.query_table")
+				},
+				Config:             fmt.Sprintf(tfConfig, dbName, "v2", "v2"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: fmt.Sprintf(tfConfig, dbName, "v2", "v2"),
+				Check:  resource.TestCheckResourceAttr("postgresql_query.table", "result.0.comment", "v2"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlQueryWithoutTransaction(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	dsn, _ := config.connStr(dbName)
+	dbExecute(t, dsn, "CREATE TABLE test_schema.query_table (id int)")
+
+	// CREATE INDEX CONCURRENTLY cannot be executed in a transaction block
+	tfConfig := fmt.Sprintf(`
+resource "postgresql_query" "index" {
+  database    = "%s"
+  transaction = false
+  create      = "CREATE INDEX CONCURRENTLY query_index ON test_schema.query_table (id)"
+  delete      = "DROP INDEX CONCURRENTLY test_schema.query_index"
+  read        = "SELECT indexname FROM pg_catalog.pg_indexes WHERE schemaname = 'test_schema' AND indexname = 'query_index'"
+}
+`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: getTestProvidersForTest(t),
+		Steps: []resource.TestStep{
+			{
+				Config: tfConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_query.index", "result.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_query.index", "result.0.indexname", "query_index"),
+				),
+			},
+		},
+	})
+}
@@ -12,8 +12,8 @@ import (
 func resourcePostgreSQLReplicationSlot() *schema.Resource {
 	return &schema.Resource{
 		Create: PGResourceFunc(resourcePostgreSQLReplicationSlotCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLReplicationSlotRead),
-		Delete: PGResourceFunc(resourcePostgreSQLReplicationSlotDelete),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLReplicationSlotRead)),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLReplicationSlotDelete)),
 		Exists: PGResourceExistsFunc(resourcePostgreSQLReplicationSlotExists),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -43,26 +43,24 @@ func resourcePostgreSQLReplicationSlot() *schema.Resource {
 }
 
 func resourcePostgreSQLReplicationSlotCreate(db *DBConnection, d *schema.ResourceData) error {
+	if err := db.checkBackendSupported("postgresql_replication_slot"); err != nil {
+		return err
+	}
 
 	name := d.Get("name").(string)
 	plugin := d.Get("plugin").(string)
-	databaseName := getDatabaseForReplicationSlot(d, db.client.databaseName)
-
-	txn, err := startTransaction(db.client, databaseName)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	databaseName := getDatabase(d, db.client.databaseName)
 
-	sql := "SELECT FROM pg_create_logical_replication_slot($1, $2)"
-	if _, err := txn.Exec(sql, name, plugin); err != nil {
+	if err := withTransaction(db.client, databaseName, func(txn *sql.Tx) error {
+		sql := "SELECT FROM pg_create_logical_replication_slot($1, $2)"
+		if _, err := txn.Exec(sql, name, plugin); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error creating ReplicationSlot: %w", err)
-	}
-
 	d.SetId(generateReplicationSlotID(d, databaseName))
 
 	return resourcePostgreSQLReplicationSlotReadImpl(db, d)
@@ -142,36 +140,23 @@ func resourcePostgreSQLReplicationSlotReadImpl(db *DBConnection, d *schema.Resou
 func resourcePostgreSQLReplicationSlotDelete(db *DBConnection, d *schema.ResourceData) error {
 
 	replicationSlotName := d.Get("name").(string)
-	database := getDatabaseForReplicationSlot(d, db.client.databaseName)
+	database := getDatabase(d, db.client.databaseName)
 
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
-
-	sql := "SELECT pg_drop_replication_slot($1)"
-	if _, err := txn.Exec(sql, replicationSlotName); err != nil {
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		sql := "SELECT pg_drop_replication_slot($1)"
+		if _, err := txn.Exec(sql, replicationSlotName); err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("Error deleting ReplicationSlot: %w", err)
-	}
-
 	d.SetId("")
 
 	return nil
 }
 
-func getDatabaseForReplicationSlot(d *schema.ResourceData, databaseName string) string {
-	if v, ok := d.GetOk("database"); ok {
-		databaseName = v.(string)
-	}
-
-	return databaseName
-}
-
 func generateReplicationSlotID(d *schema.ResourceData, databaseName string) string {
 	return strings.Join([]string{
 		databaseName,
@@ -188,7 +173,7 @@ func getReplicationSlotNameFromID(ID string) string {
 // resource, they will be parsed from the resource ID (it will return an error if parsing failed)
 // otherwise they will be simply get from the state.
 func getDBReplicationSlotName(d *schema.ResourceData, client *Client) (string, string, error) {
-	database := getDatabaseForReplicationSlot(d, client.databaseName)
+	database := getDatabase(d, client.databaseName)
 	replicationSlotName := d.Get("name").(string)
 
 	// When importing, we have to parse the ID to find replication slot and database names.
@@ -1,14 +1,21 @@
 package postgresql
 
 import (
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	// The session TimeZone of valid_until is loaded even where the system has no time zone database
+	_ "time/tzdata"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
@@ -17,39 +24,57 @@ import (
 
 const (
 	roleBypassRLSAttr                       = "bypass_row_level_security"
+	roleCleanupDefaultPrivilegesAttr        = "cleanup_default_privileges"
 	roleConnLimitAttr                       = "connection_limit"
 	roleCreateDBAttr                        = "create_database"
 	roleCreateRoleAttr                      = "create_role"
+	roleDatabaseSearchPathAttr              = "database_search_path"
+	roleDatabaseSettingsAttr                = "database_settings"
 	roleEncryptedPassAttr                   = "encrypted_password"
 	roleIdleInTransactionSessionTimeoutAttr = "idle_in_transaction_session_timeout"
 	roleInheritAttr                         = "inherit"
 	roleLoginAttr                           = "login"
 	roleNameAttr                            = "name"
 	rolePasswordAttr                        = "password"
+	roleScramIterationsAttr                 = "scram_iterations"
 	roleReplicationAttr                     = "replication"
+	roleCheckLogicalReplicationAttr         = "check_logical_replication"
 	roleSkipDropRoleAttr                    = "skip_drop_role"
 	roleSkipReassignOwnedAttr               = "skip_reassign_owned"
+	roleReassignOwnedToAttr                 = "reassign_owned_to"
 	roleSuperuserAttr                       = "superuser"
 	roleValidUntilAttr                      = "valid_until"
 	roleRolesAttr                           = "roles"
+	roleMemberRolesAttr                     = "member_roles"
+	roleAdminRolesAttr                      = "admin_roles"
 	roleSearchPathAttr                      = "search_path"
 	roleStatementTimeoutAttr                = "statement_timeout"
 
 	// Deprecated options
 	roleDepEncryptedAttr = "encrypted"
+
+	// Attributes of database_search_path and database_settings
+	roleDatabaseSearchPathDatabaseAttr = "database"
+	roleDatabaseSettingsSettingsAttr   = "settings"
 )
 
+// rolePasswordNull is the password value to explicitly remove the password of a role
+// (as opposed to an empty password which means the password is not managed).
+const rolePasswordNull = "NULL"
+
 func resourcePostgreSQLRole() *schema.Resource {
 	return &schema.Resource{
 		Create: PGResourceFunc(resourcePostgreSQLRoleCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLRoleRead),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLRoleRead)),
 		Update: PGResourceFunc(resourcePostgreSQLRoleUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLRoleDelete),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLRoleDelete)),
 		Exists: PGResourceExistsFunc(resourcePostgreSQLRoleExists),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourcePostgreSQLRoleCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			roleNameAttr: {
 				Type:        schema.TypeString,
@@ -60,7 +85,7 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Sensitive:   true,
-				Description: "Sets the role's password",
+				Description: "Sets the role's password, `NULL` to remove it",
 			},
 			roleDepEncryptedAttr: {
 				Type:       schema.TypeString,
@@ -75,6 +100,20 @@ func resourcePostgreSQLRole() *schema.Resource {
 				MinItems:    0,
 				Description: "Role(s) to grant to this new role",
 			},
+			roleMemberRolesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Role(s) made members of this role when it is created (CREATE ROLE ... ROLE)",
+			},
+			roleAdminRolesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Role(s) made members of this role with the ADMIN OPTION when it is created (CREATE ROLE ... ADMIN)",
+			},
 			roleSearchPathAttr: {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -82,6 +121,47 @@ func resourcePostgreSQLRole() *schema.Resource {
 				MinItems:    0,
 				Description: "Sets the role's search path",
 			},
+			roleDatabaseSearchPathAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						roleDatabaseSearchPathDatabaseAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The database in which the search path is set",
+						},
+						roleSearchPathAttr: {
+							Type:        schema.TypeList,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							MinItems:    1,
+							Description: "The search path of the role in this database",
+						},
+					},
+				},
+				Description: "Sets the role's search path in specific databases (ALTER ROLE ... IN DATABASE ... SET search_path)",
+			},
+			roleDatabaseSettingsAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						roleDatabaseSearchPathDatabaseAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The database in which the settings are set",
+						},
+						roleDatabaseSettingsSettingsAttr: {
+							Type:        schema.TypeMap,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The settings of the role in this database, by name (search_path excepted)",
+						},
+					},
+				},
+				Description: "Sets the role's settings in specific databases (ALTER ROLE ... IN DATABASE ... SET)",
+			},
 			roleEncryptedPassAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -93,6 +173,9 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Optional:    true,
 				Default:     "infinity",
 				Description: "Sets a date and time after which the role's password is no longer valid",
+				DiffSuppressFunc: func(_, old, new string, _ *schema.ResourceData) bool {
+					return sameValidUntil(old, new)
+				},
 			},
 			roleConnLimitAttr: {
 				Type:         schema.TypeInt,
@@ -125,6 +208,13 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Description:  "Terminate any session with an open transaction that has been idle for longer than the specified duration in milliseconds",
 				ValidateFunc: validation.IntAtLeast(0),
 			},
+			roleScramIterationsAttr: {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The iteration count used to hash the password with SCRAM-SHA-256 (0 uses the server default)",
+			},
 			roleInheritAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -143,6 +233,13 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Default:     false,
 				Description: "Determine whether a role is allowed to initiate streaming replication or put the system in and out of backup mode",
 			},
+			roleCheckLogicalReplicationAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "Check during the plan that the server is configured for logical replication " +
+					"(wal_level, max_replication_slots and max_wal_senders) when replication is enabled, a warning is logged otherwise",
+			},
 			roleBypassRLSAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -161,6 +258,17 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Default:     false,
 				Description: "Skip actually running the REASSIGN OWNED command when removing a role from PostgreSQL",
 			},
+			roleReassignOwnedToAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role the objects owned by the role are reassigned to when removing it (defaults to the connected user)",
+			},
+			roleCleanupDefaultPrivilegesAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Revoke the default privileges defined by or granted to the role in all databases before removing it",
+			},
 			roleStatementTimeoutAttr: {
 				Type:         schema.TypeInt,
 				Optional:     true,
@@ -177,6 +285,75 @@ func resourcePostgreSQLRole() *schema.Resource {
 	}
 }
 
+// resourcePostgreSQLRoleCustomizeDiff reports during the plan the server settings preventing
+// a replication role from using logical replication, if check_logical_replication is enabled.
+// The settings are only reported, as they are not managed by the role and may be changed
+// (with a restart) before the role is used.
+func resourcePostgreSQLRoleCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get(roleReplicationAttr).(bool) || !d.Get(roleCheckLogicalReplicationAttr).(bool) {
+		return nil
+	}
+
+	client, ok := meta.(*Client)
+	if !ok || client == nil {
+		return nil
+	}
+
+	release := client.acquireSlot()
+	defer release()
+
+	db, err := client.Connect()
+	if err != nil {
+		// The server may not exist yet
+		log.Printf("[WARN] could not check the logical replication settings: %v", err)
+		return nil
+	}
+
+	settings, err := readReplicationSettings(db)
+	if err != nil {
+		return err
+	}
+	for _, issue := range logicalReplicationIssues(settings) {
+		log.Printf("[WARN] replication role %s cannot use logical replication: %s", d.Get(roleNameAttr), issue)
+	}
+	return nil
+}
+
+// replicationSettings are the server settings needed for logical replication.
+var replicationSettings = []string{"wal_level", "max_replication_slots", "max_wal_senders"}
+
+func readReplicationSettings(db QueryAble) (map[string]string, error) {
+	rows, err := db.Query("SELECT name, setting FROM pg_catalog.pg_settings WHERE name = ANY($1)", pq.Array(replicationSettings))
+	if err != nil {
+		return nil, fmt.Errorf("could not read the replication settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := map[string]string{}
+	for rows.Next() {
+		var name, setting string
+		if err := rows.Scan(&name, &setting); err != nil {
+			return nil, fmt.Errorf("could not scan the replication settings: %w", err)
+		}
+		settings[name] = setting
+	}
+	return settings, rows.Err()
+}
+
+// logicalReplicationIssues returns the reasons why the settings prevent logical replication.
+func logicalReplicationIssues(settings map[string]string) []string {
+	var issues []string
+	if level := settings["wal_level"]; level != "logical" {
+		issues = append(issues, fmt.Sprintf("wal_level is %q instead of \"logical\" (it requires a restart)", level))
+	}
+	for _, name := range []string{"max_replication_slots", "max_wal_senders"} {
+		if settings[name] == "0" {
+			issues = append(issues, fmt.Sprintf("%s is 0 (it requires a restart)", name))
+		}
+	}
+	return issues
+}
+
 func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) error {
 	roleName := d.Get(roleNameAttr).(string)
 	if v, ok := d.GetOk(createIfNotExistsAttr); ok && v.(bool) {
@@ -196,12 +373,6 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 		}
 	}
 
-	txn, err := startTransaction(db.client, "")
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
-
 	stringOpts := []struct {
 		hclKey string
 		sqlKey string
@@ -251,7 +422,7 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 		if val != "" {
 			switch {
 			case opt.hclKey == rolePasswordAttr:
-				if strings.ToUpper(v.(string)) == "NULL" {
+				if strings.ToUpper(v.(string)) == rolePasswordNull {
 					createOpts = append(createOpts, "PASSWORD NULL")
 				} else {
 					if d.Get(roleEncryptedPassAttr).(bool) {
@@ -302,29 +473,71 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 		}
 	}
 
-	sql := fmt.Sprintf("CREATE ROLE %s%s", pq.QuoteIdentifier(roleName), createStr)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("error creating role %s: %w", roleName, err)
-	}
-
-	if err = grantRoles(txn, d); err != nil {
+	memberRoles := d.Get(roleMemberRolesAttr).(*schema.Set)
+	adminRoles := d.Get(roleAdminRolesAttr).(*schema.Set)
+	if err := checkRoleMembersDisjoint(memberRoles, adminRoles); err != nil {
 		return err
 	}
+	// The members are added in the CREATE statement itself: since PostgreSQL 16, a CREATEROLE user
+	// may not be able to grant the membership of the role afterwards.
+	createStr += roleMembersClause(memberRoles, adminRoles)
 
-	if err = alterSearchPath(txn, d); err != nil {
-		return err
+	var scramQuery string
+	if password := d.Get(rolePasswordAttr).(string); password != "" && strings.ToUpper(password) != rolePasswordNull {
+		var err error
+		if scramQuery, err = scramIterationsQuery(db, d); err != nil {
+			return err
+		}
 	}
 
-	if err = setStatementTimeout(txn, d); err != nil {
-		return err
-	}
+	if err := withTransaction(db.client, "", func(txn *sql.Tx) error {
+		if d.Get(roleSuperuserAttr).(bool) {
+			if err := checkCanSetSuperuser(txn, roleName); err != nil {
+				return err
+			}
+		}
 
-	if err = setIdleInTransactionSessionTimeout(txn, d); err != nil {
-		return err
-	}
+		if scramQuery != "" {
+			if _, err := txn.Exec(scramQuery); err != nil {
+				return fmt.Errorf("could not set the SCRAM iterations of role %s: %w", roleName, err)
+			}
+		}
+
+		sql := fmt.Sprintf("CREATE ROLE %s%s", pq.QuoteIdentifier(roleName), createStr)
+		if _, err := txn.Exec(sql); err != nil {
+			if isInsufficientPrivilege(err) {
+				return fmt.Errorf(
+					"error creating role %s, the connected user needs the CREATEROLE privilege "+
+						"and, on most managed services, cannot set REPLICATION or BYPASSRLS: %w",
+					roleName, err,
+				)
+			}
+			return fmt.Errorf("error creating role %s: %w", roleName, err)
+		}
+
+		if err := grantRoles(db, txn, d); err != nil {
+			return err
+		}
+
+		if err := alterSearchPath(txn, d); err != nil {
+			return err
+		}
+
+		if err := alterDatabaseSearchPaths(txn, d); err != nil {
+			return err
+		}
+
+		if err := alterDatabaseSettings(txn, d); err != nil {
+			return err
+		}
+
+		if err := setStatementTimeout(txn, d); err != nil {
+			return err
+		}
 
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
+		return setIdleInTransactionSessionTimeout(txn, d)
+	}); err != nil {
+		return err
 	}
 
 	d.SetId(roleName)
@@ -335,44 +548,157 @@ func resourcePostgreSQLRoleCreate(db *DBConnection, d *schema.ResourceData) erro
 func resourcePostgreSQLRoleDelete(db *DBConnection, d *schema.ResourceData) error {
 	roleName := d.Get(roleNameAttr).(string)
 
-	txn, err := startTransaction(db.client, "")
-	if err != nil {
-		return err
+	// Default privileges are stored per database, so they are cleaned up
+	// before the role is dropped from the catalog (they are kept with the role otherwise).
+	if d.Get(roleCleanupDefaultPrivilegesAttr).(bool) && !d.Get(roleSkipDropRoleAttr).(bool) {
+		if err := cleanupRoleDefaultPrivileges(db, roleName); err != nil {
+			return err
+		}
 	}
-	defer deferredRollback(txn)
 
-	if err := pgLockRole(txn, roleName); err != nil {
-		return err
-	}
+	if err := withTransaction(db.client, "", func(txn *sql.Tx) error {
+		if err := pgLockRole(txn, roleName); err != nil {
+			return err
+		}
 
-	if !d.Get(roleSkipReassignOwnedAttr).(bool) {
-		if err := withRolesGranted(txn, []string{roleName}, func() error {
-			currentUser := db.client.config.getDatabaseUsername()
-			if _, err := txn.Exec(fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(currentUser))); err != nil {
-				return fmt.Errorf("could not reassign owned by role %s to %s: %w", roleName, currentUser, err)
+		if !d.Get(roleSkipReassignOwnedAttr).(bool) {
+			newOwner := d.Get(roleReassignOwnedToAttr).(string)
+			roles := []string{roleName}
+			if newOwner == "" {
+				newOwner = db.client.config.getDatabaseUsername()
+			} else {
+				// REASSIGN OWNED requires the privileges of the new owner as well
+				roles = append(roles, newOwner)
 			}
+			if err := withRolesGranted(txn, roles, func() error {
+				if _, err := txn.Exec(fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(newOwner))); err != nil {
+					return fmt.Errorf("could not reassign owned by role %s to %s: %w", roleName, newOwner, err)
+				}
 
-			if _, err := txn.Exec(fmt.Sprintf("DROP OWNED BY %s", pq.QuoteIdentifier(roleName))); err != nil {
-				return fmt.Errorf("could not drop owned by role %s: %w", roleName, err)
+				if _, err := txn.Exec(fmt.Sprintf("DROP OWNED BY %s", pq.QuoteIdentifier(roleName))); err != nil {
+					return fmt.Errorf("could not drop owned by role %s: %w", roleName, err)
+				}
+				return nil
+			}); err != nil {
+				return err
 			}
-			return nil
-		}); err != nil {
-			return err
 		}
+		if !d.Get(roleSkipDropRoleAttr).(bool) {
+			if err := dropRole(txn, roleName); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
-	if !d.Get(roleSkipDropRoleAttr).(bool) {
-		if _, err := txn.Exec(fmt.Sprintf("DROP ROLE %s", pq.QuoteIdentifier(roleName))); err != nil {
-			return fmt.Errorf("could not delete role %s: %w", roleName, err)
+
+	d.SetId("")
+
+	return nil
+}
+
+// dropRole drops the role. If objects still depend on it (e.g.: with skip_reassign_owned
+// or in other databases), the error lists them from pg_shdepend.
+func dropRole(txn *sql.Tx, roleName string) error {
+	// The savepoint allows to list the dependencies in the transaction after the failure,
+	// as they may have been changed by the REASSIGN OWNED and DROP OWNED not yet committed.
+	if _, err := txn.Exec("SAVEPOINT drop_role"); err != nil {
+		return fmt.Errorf("could not create savepoint: %w", err)
+	}
+
+	_, err := txn.Exec(fmt.Sprintf("DROP ROLE %s", pq.QuoteIdentifier(roleName)))
+	if err == nil {
+		return nil
+	}
+	if !isDependentObjects(err) {
+		return fmt.Errorf("could not delete role %s: %w", roleName, err)
+	}
+
+	if _, rollbackErr := txn.Exec("ROLLBACK TO SAVEPOINT drop_role"); rollbackErr != nil {
+		return fmt.Errorf("could not delete role %s: %w", roleName, err)
+	}
+	dependencies, listErr := readRoleDependencies(txn, roleName)
+	if listErr != nil || len(dependencies) == 0 {
+		if listErr != nil {
+			log.Printf("[WARN] could not list the objects depending on role %s: %v", roleName, listErr)
 		}
+		return fmt.Errorf("could not delete role %s: %w", roleName, err)
 	}
+	return fmt.Errorf(
+		"could not delete role %s, the following objects depend on it (reassign or drop them first in their database):\n  - %s\n%w",
+		roleName, strings.Join(dependencies, "\n  - "), err,
+	)
+}
+
+// roleDependencyQuery lists the objects depending on the role $1 from pg_shdepend:
+// the database (empty for shared objects), the kind of dependency and the description of the object
+// (NULL for the objects of the other databases, which can only be described in their database).
+const roleDependencyQuery = `
+SELECT COALESCE(d.datname, ''), s.deptype,
+	CASE WHEN s.dbid IN (0, (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database()))
+		THEN pg_catalog.pg_describe_object(s.classid, s.objid, s.objsubid) END
+FROM pg_catalog.pg_shdepend s
+LEFT JOIN pg_catalog.pg_database d ON d.oid = s.dbid
+WHERE s.refclassid = 'pg_catalog.pg_authid'::regclass
+AND s.refobjid = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1)
+AND s.deptype <> 'p'
+ORDER BY 1, 3
+`
+
+// roleDependencyKinds describes the kinds of dependencies of pg_shdepend.
+var roleDependencyKinds = map[string]string{
+	"o": "owner of",
+	"a": "privileges on",
+	"r": "policy on",
+	"i": "initial privileges on",
+}
 
-	if err := txn.Commit(); err != nil {
-		return fmt.Errorf("Error committing schema: %w", err)
+// readRoleDependencies returns the description of the objects depending on the role.
+// The objects of the other databases are counted per database.
+func readRoleDependencies(db QueryAble, roleName string) ([]string, error) {
+	rows, err := db.Query(roleDependencyQuery, roleName)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	d.SetId("")
+	var dependencies []string
+	var otherDatabases []string
+	otherObjects := map[string]int{}
+	for rows.Next() {
+		var database, kind string
+		var object sql.NullString
+		if err := rows.Scan(&database, &kind, &object); err != nil {
+			return nil, err
+		}
 
-	return nil
+		if !object.Valid {
+			if otherObjects[database] == 0 {
+				otherDatabases = append(otherDatabases, database)
+			}
+			otherObjects[database]++
+			continue
+		}
+
+		description, ok := roleDependencyKinds[kind]
+		if !ok {
+			description = fmt.Sprintf("dependency (%s) on", kind)
+		}
+		description += " " + object.String
+		if database != "" {
+			description += fmt.Sprintf(" in database %s", database)
+		}
+		dependencies = append(dependencies, description)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, database := range otherDatabases {
+		dependencies = append(dependencies, fmt.Sprintf("%d object(s) in database %s", otherObjects[database], database))
+	}
+	return dependencies, nil
 }
 
 func resourcePostgreSQLRoleExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
@@ -395,7 +721,7 @@ func resourcePostgreSQLRoleRead(db *DBConnection, d *schema.ResourceData) error
 func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) error {
 	var roleSuperuser, roleInherit, roleCreateRole, roleCreateDB, roleCanLogin, roleReplication, roleBypassRLS bool
 	var roleConnLimit int
-	var roleName, roleValidUntil string
+	var roleName, roleValidUntil, sessionTimeZone string
 	var roleRoles, roleConfig pq.ByteaArray
 
 	roleID := d.Id()
@@ -410,6 +736,7 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 		"rolconnlimit",
 		`COALESCE(rolvaliduntil::TEXT, 'infinity')`,
 		"rolconfig",
+		"current_setting('TimeZone')",
 	}
 
 	values := []interface{}{
@@ -423,6 +750,7 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 		&roleConnLimit,
 		&roleValidUntil,
 		&roleConfig,
+		&sessionTimeZone,
 	}
 
 	if db.featureSupported(featureReplication) {
@@ -435,10 +763,18 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 		values = append(values, &roleBypassRLS)
 	}
 
+	// Since PostgreSQL 16, the ADMIN OPTION a CREATEROLE user gets on the roles it creates
+	// is not a membership to report in its roles.
+	membershipCondition := ""
+	if db.featureSupported(featureRoleMembershipOptions) {
+		membershipCondition = " AND " + membershipOptionsCondition
+	}
+
 	roleSQL := fmt.Sprintf(`SELECT ARRAY(
-			SELECT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members WHERE member = pg_roles.oid
+			SELECT pg_get_userbyid(roleid) FROM pg_catalog.pg_auth_members members WHERE member = pg_roles.oid%s
 		), %s
 		FROM pg_catalog.pg_roles WHERE rolname=$1`,
+		membershipCondition,
 		// select columns
 		strings.Join(columns, ", "),
 	)
@@ -462,13 +798,22 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 	_ = d.Set(roleLoginAttr, roleCanLogin)
 	_ = d.Set(roleSkipDropRoleAttr, d.Get(roleSkipDropRoleAttr).(bool))
 	_ = d.Set(roleSkipReassignOwnedAttr, d.Get(roleSkipReassignOwnedAttr).(bool))
+	_ = d.Set(roleReassignOwnedToAttr, d.Get(roleReassignOwnedToAttr).(string))
+	_ = d.Set(roleCleanupDefaultPrivilegesAttr, d.Get(roleCleanupDefaultPrivilegesAttr).(bool))
 	_ = d.Set(roleSuperuserAttr, roleSuperuser)
-	_ = d.Set(roleValidUntilAttr, roleValidUntil)
+	_ = d.Set(roleValidUntilAttr, readRoleValidUntil(d, roleValidUntil, sessionLocation(sessionTimeZone)))
 	_ = d.Set(roleReplicationAttr, roleReplication)
 	_ = d.Set(roleBypassRLSAttr, roleBypassRLS)
 	_ = d.Set(roleRolesAttr, pgArrayToSet(roleRoles))
 	_ = d.Set(roleSearchPathAttr, readSearchPath(roleConfig))
 
+	databaseSearchPaths, databaseSettings, err := readDatabaseRoleSettings(db, roleName)
+	if err != nil {
+		return err
+	}
+	_ = d.Set(roleDatabaseSearchPathAttr, databaseSearchPaths)
+	_ = d.Set(roleDatabaseSettingsAttr, databaseSettings)
+
 	statementTimeout, err := readStatementTimeout(roleConfig)
 	if err != nil {
 		return err
@@ -491,9 +836,100 @@ func resourcePostgreSQLRoleReadImpl(db *DBConnection, d *schema.ResourceData) er
 	}
 
 	_ = d.Set(rolePasswordAttr, password)
+
+	scramIterations, err := readRoleScramIterations(db, d, roleCanLogin)
+	if err != nil {
+		return err
+	}
+
+	_ = d.Set(roleScramIterationsAttr, scramIterations)
 	return nil
 }
 
+// validUntilLayouts are the timestamp formats accepted to compare the valid_until values,
+// the last ones without time zone.
+var validUntilLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseValidUntil parses the timestamp, in location if it has no time zone
+// (as PostgreSQL does with the TimeZone of the session).
+func parseValidUntil(value string, location *time.Location) (time.Time, bool) {
+	for _, layout := range validUntilLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// sessionLocation returns the location of the TimeZone of the session, nil if unknown to Go
+// (e.g.: a POSIX time zone specification).
+func sessionLocation(timeZone string) *time.Location {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		log.Printf("[DEBUG] Unknown session TimeZone %q, valid_until values without time zone are compared in UTC: %v", timeZone, err)
+		return nil
+	}
+	return location
+}
+
+// parseZonedValidUntil parses the timestamp if it has a time zone, so it is the same whatever the session TimeZone.
+func parseZonedValidUntil(value string) (time.Time, bool) {
+	t, ok := parseValidUntil(value, time.UTC)
+	if !ok {
+		return time.Time{}, false
+	}
+	other, _ := parseValidUntil(value, time.FixedZone("", 3600))
+	return t, t.Equal(other)
+}
+
+// sameValidUntil returns true if the valid_until values of the plan are the same timestamp,
+// or if the default (infinity) is planned to be unset. As the TimeZone of the session is not known
+// during the plan, the values without time zone are only the same if equal (see readRoleValidUntil).
+func sameValidUntil(old, new string) bool {
+	if strings.EqualFold(old, new) || (new == "" && strings.EqualFold(old, "infinity")) {
+		return true
+	}
+
+	oldTime, ok := parseZonedValidUntil(old)
+	if !ok {
+		return false
+	}
+	newTime, ok := parseZonedValidUntil(new)
+	return ok && oldTime.Equal(newTime)
+}
+
+// readRoleValidUntil returns the valid_until value to store in the state.
+// rolvaliduntil is formatted by PostgreSQL according to the session TimeZone,
+// so the value of the state is kept if it is the same timestamp in another format
+// (only an actual change made outside of Terraform is reported).
+// The state values without time zone are read in location, the TimeZone of the session.
+func readRoleValidUntil(d *schema.ResourceData, roleValidUntil string, location *time.Location) string {
+	stateValidUntil := d.Get(roleValidUntilAttr).(string)
+	if strings.EqualFold(stateValidUntil, roleValidUntil) {
+		return stateValidUntil
+	}
+	if location == nil {
+		location = time.UTC
+	}
+
+	stateTime, ok := parseValidUntil(stateValidUntil, location)
+	if !ok {
+		return roleValidUntil
+	}
+	roleTime, ok := parseValidUntil(roleValidUntil, location)
+	if !ok || !stateTime.Equal(roleTime) {
+		return roleValidUntil
+	}
+	return stateValidUntil
+}
+
 // readSearchPath searches for a search_path entry in the rolconfig array.
 // In case no such value is present, it returns nil.
 func readSearchPath(roleConfig pq.ByteaArray) []string {
@@ -510,6 +946,60 @@ func readSearchPath(roleConfig pq.ByteaArray) []string {
 	return nil
 }
 
+// readDatabaseRoleSettings reads the search paths and the other settings set for the role in specific databases
+// (ALTER ROLE ... IN DATABASE ... SET) from pg_db_role_setting.
+func readDatabaseRoleSettings(db *DBConnection, role string) ([]map[string]interface{}, []map[string]interface{}, error) {
+	rows, err := db.Query(
+		`SELECT d.datname, s.setconfig
+		FROM pg_catalog.pg_db_role_setting s
+		JOIN pg_catalog.pg_database d ON d.oid = s.setdatabase
+		JOIN pg_catalog.pg_roles r ON r.oid = s.setrole
+		WHERE r.rolname = $1
+		ORDER BY d.datname`,
+		role,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read the database settings of role %s: %w", role, err)
+	}
+	defer rows.Close()
+
+	databaseSearchPaths := []map[string]interface{}{}
+	databaseSettings := []map[string]interface{}{}
+	for rows.Next() {
+		var database string
+		var setConfig pq.ByteaArray
+		if err := rows.Scan(&database, &setConfig); err != nil {
+			return nil, nil, fmt.Errorf("could not scan the database settings of role %s: %w", role, err)
+		}
+
+		if searchPath := readSearchPath(setConfig); searchPath != nil {
+			databaseSearchPaths = append(databaseSearchPaths, map[string]interface{}{
+				roleDatabaseSearchPathDatabaseAttr: database,
+				roleSearchPathAttr:                 searchPath,
+			})
+		}
+
+		entries := make([]string, len(setConfig))
+		for i, entry := range setConfig {
+			entries[i] = string(entry)
+		}
+		settings := parseSettings(entries)
+		// Managed by database_search_path
+		delete(settings, roleSearchPathAttr)
+		if len(settings) > 0 {
+			databaseSettings = append(databaseSettings, map[string]interface{}{
+				roleDatabaseSearchPathDatabaseAttr: database,
+				roleDatabaseSettingsSettingsAttr:   settings,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("could not read the database settings of role %s: %w", role, err)
+	}
+
+	return databaseSearchPaths, databaseSettings, nil
+}
+
 // readIdleInTransactionSessionTimeout searches for a idle_in_transaction_session_timeout entry in the rolconfig array.
 // In case no such value is present, it returns nil.
 func readIdleInTransactionSessionTimeout(roleConfig pq.ByteaArray) (int, error) {
@@ -546,34 +1036,36 @@ func readStatementTimeout(roleConfig pq.ByteaArray) (int, error) {
 
 // readRolePassword reads password either from Postgres if admin user is a superuser
 // or only from Terraform state.
+// An empty password means it is not managed by Terraform so it is never read from Postgres,
+// while rolePasswordNull means the role must not have any password.
 func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin bool) (string, error) {
 	statePassword := d.Get(rolePasswordAttr).(string)
 
 	// Role which cannot login does not have password in pg_shadow.
 	// Also, if user specifies that admin is not a superuser we don't try to read pg_shadow
-	// (only superuser can read pg_shadow)
-	if !roleCanLogin || !db.client.config.Superuser {
+	// (only superuser can read pg_shadow), nor on the PostgreSQL-compatible engines (which may not have it).
+	if statePassword == "" || !roleCanLogin || !db.client.config.Superuser || !db.isPostgreSQL() {
 		return statePassword, nil
 	}
 
-	// Otherwise we check if connected user is really a superuser
-	// (in order to warn user instead of having a permission denied error)
-	superuser, err := db.isSuperuser()
+	// Otherwise we check if connected user can really read pg_shadow
+	// (in order to fall back on the state instead of having a permission denied error)
+	allowed, err := db.canReadRolePasswords()
 	if err != nil {
 		return "", err
 	}
-	if !superuser {
-		return "", fmt.Errorf(
-			"could not read role password from Postgres as "+
-				"connected user %s is not a SUPERUSER. "+
-				"You can set `superuser = false` in the provider configuration "+
-				"so it will not try to read the password from Postgres",
-			db.client.config.getDatabaseUsername(),
+	if !allowed {
+		log.Printf(
+			"[WARN] connected user %s is not allowed to read role passwords from Postgres, "+
+				"password of role %s is read from the state. "+
+				"You can set `superuser = false` in the provider configuration to skip this check",
+			db.client.config.getDatabaseUsername(), d.Id(),
 		)
+		return statePassword, nil
 	}
 
-	var rolePassword string
-	err = db.QueryRow("SELECT COALESCE(passwd, '') FROM pg_catalog.pg_shadow AS s WHERE s.usename = $1", d.Id()).Scan(&rolePassword)
+	var passwd sql.NullString
+	err = db.QueryRow("SELECT passwd FROM pg_catalog.pg_shadow AS s WHERE s.usename = $1", d.Id()).Scan(&passwd)
 	switch {
 	case err == sql.ErrNoRows:
 		// They don't have a password
@@ -581,19 +1073,26 @@ func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin boo
 	case err != nil:
 		return "", fmt.Errorf("Error reading role: %w", err)
 	}
+
+	isStatePasswordNull := strings.ToUpper(statePassword) == rolePasswordNull
+	if !passwd.Valid {
+		if isStatePasswordNull {
+			return statePassword, nil
+		}
+		return rolePasswordNull, nil
+	}
+	rolePassword := passwd.String
+	if isStatePasswordNull {
+		return rolePassword, nil
+	}
+
 	// If the password isn't already in md5 format, but hashing the input
 	// matches the password in the database for the user, they are the same
 	if statePassword != "" && !strings.HasPrefix(statePassword, "md5") && !strings.HasPrefix(statePassword, "SCRAM-SHA-256") {
-		if strings.HasPrefix(rolePassword, "md5") {
-			hasher := md5.New()
-			hasher.Write([]byte(statePassword + d.Id()))
-			hashedPassword := "md5" + hex.EncodeToString(hasher.Sum(nil))
-
-			if hashedPassword == rolePassword {
-				// The passwords are actually the same
-				// make Terraform think they are the same
-				return statePassword, nil
-			}
+		if strings.HasPrefix(rolePassword, "md5") && passwordMatchesVerifier(statePassword, d.Id(), rolePassword) {
+			// The passwords are actually the same
+			// make Terraform think they are the same
+			return statePassword, nil
 		}
 		if strings.HasPrefix(rolePassword, "SCRAM-SHA-256") {
 			return statePassword, nil
@@ -603,309 +1102,367 @@ func readRolePassword(db *DBConnection, d *schema.ResourceData, roleCanLogin boo
 	return rolePassword, nil
 }
 
-func resourcePostgreSQLRoleUpdate(db *DBConnection, d *schema.ResourceData) error {
-	txn, err := startTransaction(db.client, "")
+// readStoredPasswordVerifier returns the password verifier (MD5 or SCRAM) stored for the role,
+// or an empty string if the role has no password or if it cannot be read
+// (same conditions as in readRolePassword).
+func readStoredPasswordVerifier(db *DBConnection, q QueryAble, role string) (string, error) {
+	if !db.client.config.Superuser || !db.isPostgreSQL() {
+		return "", nil
+	}
+	allowed, err := db.canReadRolePasswords()
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer deferredRollback(txn)
-
-	oldName, _ := d.GetChange(roleNameAttr)
-	if err := pgLockRole(txn, oldName.(string)); err != nil {
-		return err
+	if !allowed {
+		return "", nil
 	}
 
-	if err := setRoleName(txn, d); err != nil {
-		return err
+	var passwd sql.NullString
+	err = q.QueryRow("SELECT passwd FROM pg_catalog.pg_shadow AS s WHERE s.usename = $1", role).Scan(&passwd)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("could not read password of role %s: %w", role, err)
 	}
 
-	if err := setRolePassword(txn, d); err != nil {
-		return err
-	}
+	return passwd.String, nil
+}
 
-	if err := setRoleBypassRLS(db, txn, d); err != nil {
-		return err
+// readRoleScramIterations returns the iteration count of the SCRAM-SHA-256 verifier stored for the role
+// if it differs from the configured one, so the password is hashed again with the configured count.
+// The configured count is returned if it is not set, if the password is not managed or already hashed,
+// or if the stored verifier cannot be read (same conditions as in readRolePassword).
+func readRoleScramIterations(db *DBConnection, d *schema.ResourceData, roleCanLogin bool) (int, error) {
+	iterations := d.Get(roleScramIterationsAttr).(int)
+	password := d.Get(rolePasswordAttr).(string)
+	if iterations == 0 || !roleCanLogin || password == "" || strings.ToUpper(password) == rolePasswordNull ||
+		strings.HasPrefix(password, "md5") || strings.HasPrefix(password, "SCRAM-SHA-256$") {
+		return iterations, nil
 	}
 
-	if err := setRoleConnLimit(txn, d); err != nil {
-		return err
+	verifier, err := readStoredPasswordVerifier(db, db, d.Id())
+	if err != nil {
+		return 0, err
 	}
-
-	if err := setRoleCreateDB(txn, d); err != nil {
-		return err
+	method, storedIterations, err := parsePasswordVerifier(sql.NullString{String: verifier, Valid: verifier != ""})
+	if err != nil {
+		return 0, err
 	}
-
-	if err := setRoleCreateRole(txn, d); err != nil {
-		return err
+	if method != passwordMethodSCRAM || storedIterations == iterations {
+		return iterations, nil
 	}
 
-	if err := setRoleInherit(txn, d); err != nil {
-		return err
-	}
+	log.Printf(
+		"[WARN] password of role %s is hashed with %d SCRAM iterations instead of %d, it will be set again",
+		d.Id(), storedIterations, iterations,
+	)
+	return storedIterations, nil
+}
 
-	if err := setRoleLogin(txn, d); err != nil {
-		return err
+// scramIterationsQuery returns the statement setting the iteration count used by the transaction
+// to hash the passwords with SCRAM-SHA-256, or an empty string to use the server default.
+func scramIterationsQuery(db *DBConnection, d *schema.ResourceData) (string, error) {
+	iterations := d.Get(roleScramIterationsAttr).(int)
+	if iterations == 0 {
+		return "", nil
 	}
-
-	if err := setRoleReplication(txn, d); err != nil {
-		return err
+	if !db.featureSupported(featureScramIterations) {
+		return "", withErrorKind(
+			fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support the scram_iterations setting", db.version.String()),
+			ErrVersionUnsupported,
+		)
 	}
+	return fmt.Sprintf("SET LOCAL scram_iterations TO %d", iterations), nil
+}
 
-	if err := setRoleSuperuser(txn, d); err != nil {
-		return err
+// passwordMatchesVerifier returns true if the password (in clear text or already hashed)
+// produces the MD5 or SCRAM-SHA-256 verifier stored for the role.
+func passwordMatchesVerifier(password, role, verifier string) bool {
+	if password == verifier {
+		return true
 	}
 
-	if err := setRoleValidUntil(txn, d); err != nil {
-		return err
-	}
+	switch {
+	case strings.HasPrefix(verifier, "md5"):
+		hasher := md5.New()
+		hasher.Write([]byte(password + role))
+		return "md5"+hex.EncodeToString(hasher.Sum(nil)) == verifier
+	case strings.HasPrefix(verifier, "SCRAM-SHA-256$"):
+		return scramVerifierMatches(password, verifier)
+	}
+	return false
+}
 
-	// applying roles: let's revoke all / grant the right ones
-	if err = revokeRoles(txn, d); err != nil {
-		return err
+// scramVerifierMatches returns true if the password produces the SCRAM-SHA-256 verifier,
+// stored as SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey> (see RFC 5802).
+// The password is not normalized with SASLprep like PostgreSQL does,
+// so non-ASCII passwords may not match (they are then set again).
+func scramVerifierMatches(password, verifier string) bool {
+	parts := strings.Split(strings.TrimPrefix(verifier, "SCRAM-SHA-256$"), "$")
+	if len(parts) != 2 {
+		return false
 	}
-
-	if err = grantRoles(txn, d); err != nil {
-		return err
+	iterationsSalt := strings.Split(parts[0], ":")
+	keys := strings.Split(parts[1], ":")
+	if len(iterationsSalt) != 2 || len(keys) != 2 {
+		return false
 	}
 
-	if err = alterSearchPath(txn, d); err != nil {
-		return err
+	iterations, err := strconv.Atoi(iterationsSalt[0])
+	if err != nil || iterations <= 0 {
+		return false
 	}
-
-	if err = setStatementTimeout(txn, d); err != nil {
-		return err
+	salt, err := base64.StdEncoding.DecodeString(iterationsSalt[1])
+	if err != nil {
+		return false
 	}
-
-	if err = setIdleInTransactionSessionTimeout(txn, d); err != nil {
-		return err
+	storedKey, err := base64.StdEncoding.DecodeString(keys[0])
+	if err != nil {
+		return false
 	}
-
-	if err = txn.Commit(); err != nil {
-		return fmt.Errorf("could not commit transaction: %w", err)
+	serverKey, err := base64.StdEncoding.DecodeString(keys[1])
+	if err != nil {
+		return false
 	}
 
-	return resourcePostgreSQLRoleReadImpl(db, d)
+	saltedPassword := pbkdf2SHA256([]byte(password), salt, iterations)
+	clientKey := hmacSHA256(saltedPassword, []byte("Client Key"))
+	computedStoredKey := sha256.Sum256(clientKey)
+
+	return hmac.Equal(computedStoredKey[:], storedKey) &&
+		hmac.Equal(hmacSHA256(saltedPassword, []byte("Server Key")), serverKey)
 }
 
-func setRoleName(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleNameAttr) {
-		return nil
+// pbkdf2SHA256 derives a key of the size of a SHA-256 hash (a single PBKDF2 block).
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	u := hmacSHA256(password, append(append([]byte{}, salt...), 0, 0, 0, 1))
+	result := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSHA256(password, u)
+		for j := range result {
+			result[j] ^= u[j]
+		}
 	}
+	return result
+}
 
-	oraw, nraw := d.GetChange(roleNameAttr)
-	o := oraw.(string)
-	n := nraw.(string)
-	if n == "" {
-		return errors.New("Error setting role name to an empty string")
-	}
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
 
-	sql := fmt.Sprintf("ALTER ROLE %s RENAME TO %s", pq.QuoteIdentifier(o), pq.QuoteIdentifier(n))
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role NAME: %w", err)
-	}
+func resourcePostgreSQLRoleUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if err := withTransaction(db.client, "", func(txn *sql.Tx) error {
+		oldName, _ := d.GetChange(roleNameAttr)
+		if err := pgLockRole(txn, oldName.(string)); err != nil {
+			return err
+		}
 
-	d.SetId(n)
+		if err := alterRole(db, txn, d); err != nil {
+			return err
+		}
 
-	return nil
-}
+		// applying roles: let's revoke all / grant the right ones
+		if err := revokeRoles(db, txn, d); err != nil {
+			return err
+		}
 
-func setRolePassword(txn *sql.Tx, d *schema.ResourceData) error {
-	// If role is renamed, password is reset (as the md5 sum is also base on the role name)
-	// so we need to update it
-	if !d.HasChange(rolePasswordAttr) && !d.HasChange(roleNameAttr) {
-		return nil
-	}
+		if err := grantRoles(db, txn, d); err != nil {
+			return err
+		}
 
-	roleName := d.Get(roleNameAttr).(string)
-	password := d.Get(rolePasswordAttr).(string)
+		if err := setRoleMembers(db, txn, d); err != nil {
+			return err
+		}
 
-	sql := fmt.Sprintf("ALTER ROLE %s PASSWORD '%s'", pq.QuoteIdentifier(roleName), pqQuoteLiteral(password))
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role password: %w", err)
-	}
-	return nil
-}
+		if err := alterSearchPath(txn, d); err != nil {
+			return err
+		}
 
-func setRoleBypassRLS(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleBypassRLSAttr) {
-		return nil
-	}
+		if err := alterDatabaseSearchPaths(txn, d); err != nil {
+			return err
+		}
 
-	if !db.featureSupported(featureRLS) {
-		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support PostgreSQL Row-Level Security", db.version.String())
-	}
+		if err := alterDatabaseSettings(txn, d); err != nil {
+			return err
+		}
 
-	bypassRLS := d.Get(roleBypassRLSAttr).(bool)
-	tok := "NOBYPASSRLS"
-	if bypassRLS {
-		tok = "BYPASSRLS"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role BYPASSRLS: %w", err)
+		if err := setStatementTimeout(txn, d); err != nil {
+			return err
+		}
+
+		return setIdleInTransactionSessionTimeout(txn, d)
+	}); err != nil {
+		return err
 	}
 
-	return nil
+	return resourcePostgreSQLRoleReadImpl(db, d)
 }
 
-func setRoleConnLimit(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleConnLimitAttr) {
-		return nil
-	}
-
-	connLimit := d.Get(roleConnLimitAttr).(int)
+// alterRole applies the changes of the role attributes with the statements of roleUpdateQueries.
+func alterRole(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT %d", pq.QuoteIdentifier(roleName), connLimit)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role CONNECTION LIMIT: %w", err)
-	}
 
-	return nil
-}
-
-func setRoleCreateDB(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleCreateDBAttr) {
-		return nil
+	if d.HasChange(roleSuperuserAttr) && d.Get(roleSuperuserAttr).(bool) {
+		if err := checkCanSetSuperuser(txn, roleName); err != nil {
+			return err
+		}
 	}
 
-	createDB := d.Get(roleCreateDBAttr).(bool)
-	tok := "NOCREATEDB"
-	if createDB {
-		tok = "CREATEDB"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role CREATEDB: %w", err)
+	oldName, _ := d.GetChange(roleNameAttr)
+	storedPassword, err := readStoredPasswordVerifier(db, txn, oldName.(string))
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func setRoleCreateRole(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleCreateRoleAttr) {
-		return nil
+	queries, err := roleUpdateQueries(db, d, storedPassword)
+	if err != nil {
+		return err
 	}
 
-	createRole := d.Get(roleCreateRoleAttr).(bool)
-	tok := "NOCREATEROLE"
-	if createRole {
-		tok = "CREATEROLE"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role CREATEROLE: %w", err)
+	for _, query := range queries {
+		if _, err := txn.Exec(query); err != nil {
+			if isInsufficientPrivilege(err) {
+				return fmt.Errorf(
+					"Error updating role %s, the connected user is not allowed to change some of its attributes "+
+						"(e.g.: REPLICATION or BYPASSRLS on managed services): %w",
+					roleName, err,
+				)
+			}
+			return fmt.Errorf("Error updating role %s: %w", roleName, err)
+		}
 	}
 
+	d.SetId(roleName)
+
 	return nil
 }
 
-func setRoleInherit(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleInheritAttr) {
-		return nil
-	}
+// roleUpdateQueries returns the statements applying the changes of the role attributes, in this order:
+//   - the role is renamed first, as the other statements use its new name,
+//   - all the other changed attributes are set in a single ALTER ROLE,
+//   - the password is set last: renaming a role clears its MD5 password,
+//     so it has to be set again after the rename, whatever the other changes.
+//
+// The password is not set if the password verifier stored for the role (if it could be read)
+// already matches it, to avoid changing the password needlessly (e.g.: it is logged by audit tools),
+// unless it has to be hashed again with another SCRAM iteration count.
+func roleUpdateQueries(db *DBConnection, d *schema.ResourceData, storedPassword string) ([]string, error) {
+	var queries []string
 
-	inherit := d.Get(roleInheritAttr).(bool)
-	tok := "NOINHERIT"
-	if inherit {
-		tok = "INHERIT"
-	}
 	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role INHERIT: %w", err)
+	if roleName == "" {
+		return nil, errors.New("Error setting role name to an empty string")
 	}
 
-	return nil
-}
-
-func setRoleLogin(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleLoginAttr) {
-		return nil
+	if d.HasChange(roleNameAttr) {
+		oldName, _ := d.GetChange(roleNameAttr)
+		queries = append(queries, fmt.Sprintf(
+			"ALTER ROLE %s RENAME TO %s", pq.QuoteIdentifier(oldName.(string)), pq.QuoteIdentifier(roleName),
+		))
 	}
 
-	login := d.Get(roleLoginAttr).(bool)
-	tok := "NOLOGIN"
-	if login {
-		tok = "LOGIN"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role LOGIN: %w", err)
+	opts, err := roleUpdateOptions(db, d)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
-}
-
-func setRoleReplication(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleReplicationAttr) {
-		return nil
+	if len(opts) > 0 {
+		queries = append(queries, fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), strings.Join(opts, " ")))
 	}
 
-	replication := d.Get(roleReplicationAttr).(bool)
-	tok := "NOREPLICATION"
-	if replication {
-		tok = "REPLICATION"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role REPLICATION: %w", err)
+	// If role is renamed, password is reset (as the md5 sum is also base on the role name)
+	// so we need to update it
+	rehash := d.HasChange(roleScramIterationsAttr) && d.Get(roleScramIterationsAttr).(int) > 0
+	if d.HasChange(rolePasswordAttr) || d.HasChange(roleNameAttr) || rehash {
+		password := d.Get(rolePasswordAttr).(string)
+		switch {
+		case password == "":
+			// The password is not managed by Terraform
+		case strings.ToUpper(password) == rolePasswordNull:
+			queries = append(queries, fmt.Sprintf("ALTER ROLE %s PASSWORD NULL", pq.QuoteIdentifier(roleName)))
+		case !rehash && storedPassword != "" && passwordMatchesVerifier(password, roleName, storedPassword):
+			log.Printf("[DEBUG] password of role %s is unchanged, not setting it", roleName)
+		default:
+			scramQuery, err := scramIterationsQuery(db, d)
+			if err != nil {
+				return nil, err
+			}
+			if scramQuery != "" {
+				queries = append(queries, scramQuery)
+			}
+			queries = append(queries, fmt.Sprintf("ALTER ROLE %s PASSWORD '%s'", pq.QuoteIdentifier(roleName), pqQuoteLiteral(password)))
+		}
 	}
 
-	return nil
+	return queries, nil
 }
 
-func setRoleSuperuser(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleSuperuserAttr) {
-		return nil
+// roleUpdateOptions returns the ALTER ROLE options of the changed role attributes
+// (other than its name and password), always in the same order.
+func roleUpdateOptions(db *DBConnection, d *schema.ResourceData) ([]string, error) {
+	boolOpts := []struct {
+		hclKey        string
+		sqlKeyEnable  string
+		sqlKeyDisable string
+	}{
+		{roleSuperuserAttr, "SUPERUSER", "NOSUPERUSER"},
+		{roleCreateDBAttr, "CREATEDB", "NOCREATEDB"},
+		{roleCreateRoleAttr, "CREATEROLE", "NOCREATEROLE"},
+		{roleInheritAttr, "INHERIT", "NOINHERIT"},
+		// LOGIN and CONNECTION LIMIT are applied together so a role can be
+		// frozen (NOLOGIN + CONNECTION LIMIT 0) in a single statement.
+		{roleLoginAttr, "LOGIN", "NOLOGIN"},
+		{roleReplicationAttr, "REPLICATION", "NOREPLICATION"},
+		{roleBypassRLSAttr, "BYPASSRLS", "NOBYPASSRLS"},
 	}
 
-	superuser := d.Get(roleSuperuserAttr).(bool)
-	tok := "NOSUPERUSER"
-	if superuser {
-		tok = "SUPERUSER"
-	}
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role SUPERUSER: %w", err)
+	if d.HasChange(roleBypassRLSAttr) && !db.featureSupported(featureRLS) {
+		return nil, withErrorKind(
+			fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support PostgreSQL Row-Level Security", db.version.String()),
+			ErrVersionUnsupported,
+		)
 	}
 
-	return nil
-}
-
-func setRoleValidUntil(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleValidUntilAttr) {
-		return nil
+	var opts []string
+	for _, opt := range boolOpts {
+		if !d.HasChange(opt.hclKey) {
+			continue
+		}
+		if d.Get(opt.hclKey).(bool) {
+			opts = append(opts, opt.sqlKeyEnable)
+		} else {
+			opts = append(opts, opt.sqlKeyDisable)
+		}
 	}
 
-	validUntil := d.Get(roleValidUntilAttr).(string)
-	if validUntil == "" {
-		return nil
-	} else if strings.ToLower(validUntil) == "infinity" {
-		validUntil = "infinity"
+	if d.HasChange(roleConnLimitAttr) {
+		opts = append(opts, fmt.Sprintf("CONNECTION LIMIT %d", d.Get(roleConnLimitAttr).(int)))
 	}
 
-	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s VALID UNTIL '%s'", pq.QuoteIdentifier(roleName), pqQuoteLiteral(validUntil))
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating role VALID UNTIL: %w", err)
+	if d.HasChange(roleValidUntilAttr) {
+		// As on create, an empty value is the default (infinity)
+		validUntil := d.Get(roleValidUntilAttr).(string)
+		if validUntil == "" || strings.ToLower(validUntil) == "infinity" {
+			validUntil = "infinity"
+		}
+		opts = append(opts, fmt.Sprintf("VALID UNTIL '%s'", pqQuoteLiteral(validUntil)))
 	}
 
-	return nil
+	return opts, nil
 }
 
-func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
+func revokeRoles(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 
 	query := `SELECT pg_get_userbyid(roleid)
 		FROM pg_catalog.pg_auth_members members
 		JOIN pg_catalog.pg_roles ON members.member = pg_roles.oid
 		WHERE rolname = $1`
+	if db.featureSupported(featureRoleMembershipOptions) {
+		query += " AND " + membershipOptionsCondition
+	}
 
 	rows, err := txn.Query(query, role)
 	if err != nil {
@@ -938,7 +1495,7 @@ func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func grantRoles(txn *sql.Tx, d *schema.ResourceData) error {
+func grantRoles(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 
 	for _, grantingRole := range d.Get("roles").(*schema.Set).List() {
@@ -946,29 +1503,145 @@ func grantRoles(txn *sql.Tx, d *schema.ResourceData) error {
 			"GRANT %s TO %s", pq.QuoteIdentifier(grantingRole.(string)), pq.QuoteIdentifier(role),
 		)
 		if _, err := txn.Exec(query); err != nil {
+			if isInsufficientPrivilege(err) {
+				return adminOptionRequiredError(db, "grant", grantingRole.(string), role, err)
+			}
 			return fmt.Errorf("could not grant role %s to %s: %w", grantingRole, role, err)
 		}
 	}
 	return nil
 }
 
-func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
+// checkRoleMembersDisjoint returns an error if a role is both in member_roles and admin_roles.
+func checkRoleMembersDisjoint(memberRoles, adminRoles *schema.Set) error {
+	if both := memberRoles.Intersection(adminRoles); both.Len() > 0 {
+		return fmt.Errorf(
+			"role(s) %s cannot be in both %s and %s (the members of %s are members too)",
+			strings.Join(sortedStrings(both), ", "), roleMemberRolesAttr, roleAdminRolesAttr, roleAdminRolesAttr,
+		)
+	}
+	return nil
+}
+
+// roleMembersClause returns the ROLE and ADMIN options of CREATE ROLE adding the members of the new role.
+func roleMembersClause(memberRoles, adminRoles *schema.Set) string {
+	var clause string
+	if memberRoles.Len() > 0 {
+		clause += " ROLE " + quotedRolesList(memberRoles)
+	}
+	if adminRoles.Len() > 0 {
+		clause += " ADMIN " + quotedRolesList(adminRoles)
+	}
+	return clause
+}
+
+// setRoleMembers applies the changes of member_roles and admin_roles after the creation of the role.
+func setRoleMembers(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(roleMemberRolesAttr) && !d.HasChange(roleAdminRolesAttr) {
+		return nil
+	}
+
+	oldMembers, newMembers := d.GetChange(roleMemberRolesAttr)
+	oldAdmins, newAdmins := d.GetChange(roleAdminRolesAttr)
+	if err := checkRoleMembersDisjoint(newMembers.(*schema.Set), newAdmins.(*schema.Set)); err != nil {
+		return err
+	}
+
 	role := d.Get(roleNameAttr).(string)
-	searchPathInterface := d.Get(roleSearchPathAttr).([]interface{})
-
-	var searchPathString []string
-	if len(searchPathInterface) > 0 {
-		searchPathString = make([]string, len(searchPathInterface))
-		for i, searchPathPart := range searchPathInterface {
-			if strings.Contains(searchPathPart.(string), ", ") {
-				return fmt.Errorf("search_path cannot contain `, `: %v", searchPathPart)
+	queries := roleMembersUpdateQueries(
+		role, oldMembers.(*schema.Set), oldAdmins.(*schema.Set), newMembers.(*schema.Set), newAdmins.(*schema.Set),
+	)
+	for _, query := range queries {
+		if _, err := txn.Exec(query); err != nil {
+			if isInsufficientPrivilege(err) {
+				return adminOptionRequiredError(db, "grant or revoke", role, "its members", err)
 			}
-			searchPathString[i] = pq.QuoteIdentifier(searchPathPart.(string))
+			return fmt.Errorf("could not update the members of role %s: %w", role, err)
 		}
-	} else {
-		searchPathString = []string{"DEFAULT"}
 	}
-	searchPath := strings.Join(searchPathString[:], ", ")
+	return nil
+}
+
+// roleMembersUpdateQueries returns the GRANT and REVOKE statements changing the members of the role
+// from the old member_roles and admin_roles to the new ones.
+func roleMembersUpdateQueries(role string, oldMembers, oldAdmins, newMembers, newAdmins *schema.Set) []string {
+	// Whether each member has the admin option
+	adminOption := func(members, admins *schema.Set) map[string]bool {
+		result := map[string]bool{}
+		for _, member := range members.List() {
+			result[member.(string)] = false
+		}
+		for _, admin := range admins.List() {
+			result[admin.(string)] = true
+		}
+		return result
+	}
+	oldOptions := adminOption(oldMembers, oldAdmins)
+	newOptions := adminOption(newMembers, newAdmins)
+
+	quotedRole := pq.QuoteIdentifier(role)
+	queries := []string{}
+	for member, wasAdmin := range oldOptions {
+		isAdmin, found := newOptions[member]
+		switch {
+		case !found:
+			queries = append(queries, fmt.Sprintf("REVOKE %s FROM %s", quotedRole, pq.QuoteIdentifier(member)))
+		case wasAdmin && !isAdmin:
+			queries = append(queries, fmt.Sprintf("REVOKE ADMIN OPTION FOR %s FROM %s", quotedRole, pq.QuoteIdentifier(member)))
+		}
+	}
+	for member, isAdmin := range newOptions {
+		wasAdmin, found := oldOptions[member]
+		switch {
+		case !found && !isAdmin:
+			queries = append(queries, fmt.Sprintf("GRANT %s TO %s", quotedRole, pq.QuoteIdentifier(member)))
+		case isAdmin && (!found || !wasAdmin):
+			queries = append(queries, fmt.Sprintf("GRANT %s TO %s WITH ADMIN OPTION", quotedRole, pq.QuoteIdentifier(member)))
+		}
+	}
+
+	sort.Strings(queries)
+	return queries
+}
+
+// quotedRolesList returns the sorted and quoted list of the roles of the set (e.g.: "a","b").
+func quotedRolesList(roles *schema.Set) string {
+	names := sortedStrings(roles)
+	for i, name := range names {
+		names[i] = pq.QuoteIdentifier(name)
+	}
+	return strings.Join(names, ",")
+}
+
+// checkCanSetSuperuser returns a clear error if the connected user is not allowed to make the role a superuser.
+// Only superusers can do it, which is never the case on managed services (e.g.: AWS RDS, GCP Cloud SQL or Azure).
+func checkCanSetSuperuser(txn *sql.Tx, role string) error {
+	currentUser, err := getCurrentUser(txn)
+	if err != nil {
+		return err
+	}
+
+	superuser, err := isSuperuser(txn, currentUser)
+	if err != nil {
+		return err
+	}
+	if !superuser {
+		return fmt.Errorf(
+			"could not set SUPERUSER on role %s: the connected user %s is not a superuser "+
+				"(superusers cannot be created on managed services like AWS RDS, GCP Cloud SQL or Azure)",
+			role, currentUser,
+		)
+	}
+	return nil
+}
+
+func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
+	role := d.Get(roleNameAttr).(string)
+
+	searchPath, err := searchPathValue(d.Get(roleSearchPathAttr).([]interface{}))
+	if err != nil {
+		return err
+	}
 
 	query := fmt.Sprintf(
 		"ALTER ROLE %s SET search_path TO %s", pq.QuoteIdentifier(role), searchPath,
@@ -979,6 +1652,189 @@ func alterSearchPath(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
+// searchPathValue returns the quoted search path to set, DEFAULT if it is empty.
+func searchPathValue(searchPathInterface []interface{}) (string, error) {
+	if len(searchPathInterface) == 0 {
+		return "DEFAULT", nil
+	}
+
+	searchPathString := make([]string, len(searchPathInterface))
+	for i, searchPathPart := range searchPathInterface {
+		if strings.Contains(searchPathPart.(string), ", ") {
+			return "", fmt.Errorf("search_path cannot contain `, `: %v", searchPathPart)
+		}
+		searchPathString[i] = pq.QuoteIdentifier(searchPathPart.(string))
+	}
+	return strings.Join(searchPathString, ", "), nil
+}
+
+func alterDatabaseSearchPaths(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(roleDatabaseSearchPathAttr) {
+		return nil
+	}
+
+	role := d.Get(roleNameAttr).(string)
+	oldRaw, newRaw := d.GetChange(roleDatabaseSearchPathAttr)
+
+	queries, err := databaseSearchPathQueries(role, oldRaw.(*schema.Set).List(), newRaw.(*schema.Set).List())
+	if err != nil {
+		return err
+	}
+
+	for _, query := range queries {
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("could not set the database search_path of role %s: %w", role, err)
+		}
+	}
+	return nil
+}
+
+// databaseSearchPathQueries returns the statements resetting the search path of the role
+// in the databases which are no more configured and setting it in the configured ones.
+func databaseSearchPathQueries(role string, oldSearchPaths, newSearchPaths []interface{}) ([]string, error) {
+	searchPaths := map[string][]interface{}{}
+	for _, v := range newSearchPaths {
+		searchPath := v.(map[string]interface{})
+		database := searchPath[roleDatabaseSearchPathDatabaseAttr].(string)
+		if _, ok := searchPaths[database]; ok {
+			return nil, fmt.Errorf("search_path of role %s is set several times for database %s", role, database)
+		}
+		searchPaths[database] = searchPath[roleSearchPathAttr].([]interface{})
+	}
+
+	var reset []string
+	for _, v := range oldSearchPaths {
+		database := v.(map[string]interface{})[roleDatabaseSearchPathDatabaseAttr].(string)
+		if _, ok := searchPaths[database]; !ok {
+			reset = append(reset, database)
+		}
+	}
+	sort.Strings(reset)
+
+	databases := make([]string, 0, len(searchPaths))
+	for database := range searchPaths {
+		databases = append(databases, database)
+	}
+	sort.Strings(databases)
+
+	var queries []string
+	for _, database := range reset {
+		queries = append(queries, fmt.Sprintf(
+			"ALTER ROLE %s IN DATABASE %s RESET search_path", pq.QuoteIdentifier(role), pq.QuoteIdentifier(database),
+		))
+	}
+	for _, database := range databases {
+		searchPath, err := searchPathValue(searchPaths[database])
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, fmt.Sprintf(
+			"ALTER ROLE %s IN DATABASE %s SET search_path TO %s",
+			pq.QuoteIdentifier(role), pq.QuoteIdentifier(database), searchPath,
+		))
+	}
+	return queries, nil
+}
+
+func alterDatabaseSettings(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(roleDatabaseSettingsAttr) {
+		return nil
+	}
+
+	role := d.Get(roleNameAttr).(string)
+	oldRaw, newRaw := d.GetChange(roleDatabaseSettingsAttr)
+
+	queries, err := databaseSettingsQueries(role, oldRaw.(*schema.Set).List(), newRaw.(*schema.Set).List())
+	if err != nil {
+		return err
+	}
+
+	for _, query := range queries {
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("could not set the database settings of role %s: %w", role, err)
+		}
+	}
+	return nil
+}
+
+// databaseSettingsQueries returns the statements resetting the settings of the role
+// which are no more configured in a database and setting the configured ones which changed.
+func databaseSettingsQueries(role string, oldSettings, newSettings []interface{}) ([]string, error) {
+	readSettings := func(raw []interface{}) map[string]map[string]interface{} {
+		settings := map[string]map[string]interface{}{}
+		for _, v := range raw {
+			databaseSettings := v.(map[string]interface{})
+			settings[databaseSettings[roleDatabaseSearchPathDatabaseAttr].(string)] = databaseSettings[roleDatabaseSettingsSettingsAttr].(map[string]interface{})
+		}
+		return settings
+	}
+
+	if len(readSettings(newSettings)) != len(newSettings) {
+		return nil, fmt.Errorf("settings of role %s are set several times for the same database", role)
+	}
+	oldByDatabase, newByDatabase := readSettings(oldSettings), readSettings(newSettings)
+
+	databases := make([]string, 0, len(oldByDatabase)+len(newByDatabase))
+	for database := range oldByDatabase {
+		databases = append(databases, database)
+	}
+	for database := range newByDatabase {
+		if _, ok := oldByDatabase[database]; !ok {
+			databases = append(databases, database)
+		}
+	}
+	sort.Strings(databases)
+
+	var resets, sets []string
+	for _, database := range databases {
+		oldValues, newValues := oldByDatabase[database], newByDatabase[database]
+
+		names := make([]string, 0, len(oldValues)+len(newValues))
+		for name := range oldValues {
+			names = append(names, name)
+		}
+		for name := range newValues {
+			if _, ok := oldValues[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			newValue, configured := newValues[name]
+			if configured {
+				if err := validateDatabaseSettingName(name); err != nil {
+					return nil, err
+				}
+			}
+			// The names are validated so they don't need to be quoted (as with session_variables)
+			switch {
+			case !configured:
+				resets = append(resets, fmt.Sprintf(
+					"ALTER ROLE %s IN DATABASE %s RESET %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(database), name,
+				))
+			case oldValues[name] != newValue:
+				sets = append(sets, fmt.Sprintf(
+					"ALTER ROLE %s IN DATABASE %s SET %s TO '%s'",
+					pq.QuoteIdentifier(role), pq.QuoteIdentifier(database), name, pqQuoteLiteral(newValue.(string)),
+				))
+			}
+		}
+	}
+	return append(resets, sets...), nil
+}
+
+// validateDatabaseSettingName returns an error if the setting cannot be set with database_settings.
+func validateDatabaseSettingName(name string) error {
+	if !sessionNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid setting name %q, expected a setting name or a custom variable (prefix.name)", name)
+	}
+	if strings.ToLower(name) == roleSearchPathAttr {
+		return fmt.Errorf("%s cannot be set in %s, use %s", roleSearchPathAttr, roleDatabaseSettingsAttr, roleDatabaseSearchPathAttr)
+	}
+	return nil
+}
+
 func setStatementTimeout(txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleStatementTimeoutAttr) {
 		return nil
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -44,6 +48,7 @@ func TestAccPostgresqlRole_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "valid_until", "infinity"),
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "skip_drop_role", "false"),
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "skip_reassign_owned", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "cleanup_default_privileges", "false"),
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "statement_timeout", "0"),
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "idle_in_transaction_session_timeout", "0"),
 
@@ -192,6 +197,96 @@ resource "postgresql_role" "update_role" {
 	})
 }
 
+// Test removing the password of a role (e.g.: to switch to certificate authentication).
+func TestAccPostgresqlRole_ClearPassword(t *testing.T) {
+	var configPassword = `
+resource "postgresql_role" "clear_password_role" {
+  name     = "clear_password_role"
+  login    = true
+  password = "%s"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(configPassword, "toto"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.clear_password_role", "password", "toto"),
+					testAccCheckRoleCanLogin(t, "clear_password_role", "toto"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(configPassword, "NULL"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.clear_password_role", "password", "NULL"),
+					testAccCheckRoleHasNoPassword(t, "clear_password_role"),
+				),
+			},
+		},
+	})
+}
+
+// Test freezing a role (NOLOGIN + CONNECTION LIMIT 0) without dropping it.
+func TestAccPostgresqlRole_Disable(t *testing.T) {
+	var configEnabled = `
+resource "postgresql_role" "disable_role" {
+  name     = "disable_role"
+  login    = true
+  password = "toto"
+}
+`
+
+	var configDisabled = `
+resource "postgresql_role" "disable_role" {
+  name             = "disable_role"
+  login            = false
+  connection_limit = 0
+  password         = "toto"
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: configEnabled,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.disable_role", "login", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.disable_role", "connection_limit", "-1"),
+					testAccCheckRoleCanLogin(t, "disable_role", "toto"),
+				),
+			},
+			{
+				Config: configDisabled,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists(t, "disable_role", nil, nil),
+					resource.TestCheckResourceAttr("postgresql_role.disable_role", "login", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.disable_role", "connection_limit", "0"),
+				),
+			},
+			{
+				Config: configEnabled,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.disable_role", "login", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.disable_role", "connection_limit", "-1"),
+					testAccCheckRoleCanLogin(t, "disable_role", "toto"),
+				),
+			},
+		},
+	})
+}
+
 // Test to create a role with admin user (usually postgres) granted to it
 // There were a bug on RDS like setup (with a non-superuser postgres role)
 // where it couldn't delete the role in this case.
@@ -228,6 +323,986 @@ resource "postgresql_role" "test_role" {
 	})
 }
 
+func TestAccPostgresqlRole_Members(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+
+	roleConfig := `
+resource "postgresql_role" "member" {
+  name = "test_member"
+}
+
+resource "postgresql_role" "admin" {
+  name = "test_admin"
+}
+
+resource "postgresql_role" "test_role" {
+  name         = "test_role"
+  member_roles = [%s]
+  admin_roles  = [%s]
+}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(roleConfig, "postgresql_role.member.name", "postgresql_role.admin.name"),
+				Check: resource.ComposeTestCheckFunc(
+					checkGrantRole(t, dsn, "test_member", "test_role", false),
+					checkGrantRole(t, dsn, "test_admin", "test_role", true),
+				),
+			},
+			// The member becomes an admin and the admin is removed
+			{
+				Config: fmt.Sprintf(roleConfig, "", "postgresql_role.member.name"),
+				Check: resource.ComposeTestCheckFunc(
+					checkGrantRole(t, dsn, "test_member", "test_role", true),
+					testAccCheckPostgresqlRoleExists(t, "test_admin", []string{}, nil),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_ScramIterations(t *testing.T) {
+	skipIfNotAcc(t)
+
+	config := getTestConfig(t)
+	dsn, _ := config.connStr("postgres")
+
+	roleConfig := `
+resource "postgresql_role" "test_role" {
+  name             = "test_scram_iterations"
+  login            = true
+  password         = "mypass"
+  scram_iterations = %d
+}
+
+data "postgresql_role_password_info" "test" {
+  role = postgresql_role.test_role.name
+}`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureScramIterations)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(roleConfig, 8192),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_role_password_info.test", "method", "scram-sha-256"),
+					resource.TestCheckResourceAttr("data.postgresql_role_password_info.test", "iterations", "8192"),
+					checkRoleScramIterations(t, dsn, "test_scram_iterations", 8192),
+				),
+			},
+			// The password is hashed again with the new count
+			{
+				Config: fmt.Sprintf(roleConfig, 10000),
+				Check: resource.ComposeTestCheckFunc(
+					checkRoleScramIterations(t, dsn, "test_scram_iterations", 10000),
+				),
+			},
+		},
+	})
+}
+
+func checkRoleScramIterations(t *testing.T, dsn, role string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatalf("could to create connection pool: %v", err)
+		}
+		defer db.Close()
+
+		var passwd sql.NullString
+		if err := db.QueryRow("SELECT passwd FROM pg_catalog.pg_shadow WHERE usename = $1", role).Scan(&passwd); err != nil {
+			t.Fatalf("could not read password of role %s: %v", role, err)
+		}
+
+		_, iterations, err := parsePasswordVerifier(passwd)
+		if err != nil {
+			return err
+		}
+		if iterations != expected {
+			return fmt.Errorf("password of role %s is hashed with %d iterations, expected %d", role, iterations, expected)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlRole_CleanupDefaultPrivileges(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, grantee := getTestDBNames(dbSuffix)
+
+	config := `
+resource "postgresql_role" "default_acl_owner" {
+  name                       = "default_acl_owner"
+  skip_reassign_owned        = true
+  cleanup_default_privileges = true
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featurePrivileges)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists(t, "default_acl_owner", nil, nil),
+					resource.TestCheckResourceAttr("postgresql_role.default_acl_owner", "cleanup_default_privileges", "true"),
+					func(*terraform.State) error {
+						// Default privileges defined outside of Terraform,
+						// as schema specific and global entries, and granted to the role.
+						testConfig := getTestConfig(t)
+						dsn, _ := testConfig.connStr(dbName)
+						dbExecute(t, dsn, fmt.Sprintf(
+							"ALTER DEFAULT PRIVILEGES FOR ROLE default_acl_owner IN SCHEMA test_schema GRANT SELECT ON TABLES TO %s", grantee,
+						))
+						dbExecute(t, dsn, "ALTER DEFAULT PRIVILEGES FOR ROLE default_acl_owner REVOKE EXECUTE ON FUNCTIONS FROM PUBLIC")
+						dbExecute(t, dsn, fmt.Sprintf(
+							"ALTER DEFAULT PRIVILEGES FOR ROLE %s GRANT USAGE ON SEQUENCES TO default_acl_owner", grantee,
+						))
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_ReassignOwnedTo(t *testing.T) {
+	skipIfNotAcc(t)
+
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr("postgres")
+	defer dbExecute(t, dsn, "DROP TABLE IF EXISTS test_reassign_owned")
+
+	newOwnerConfig := `
+resource "postgresql_role" "new_owner" {
+  name = "test_reassign_new_owner"
+}
+`
+	config := newOwnerConfig + `
+resource "postgresql_role" "old_owner" {
+  name              = "test_reassign_old_owner"
+  reassign_owned_to = postgresql_role.new_owner.name
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.old_owner", "reassign_owned_to", "test_reassign_new_owner"),
+					func(*terraform.State) error {
+						dbExecute(t, dsn, "CREATE TABLE test_reassign_owned (id int)")
+						dbExecute(t, dsn, "ALTER TABLE test_reassign_owned OWNER TO test_reassign_old_owner")
+						return nil
+					},
+				),
+			},
+			{
+				Config: newOwnerConfig,
+				Check: func(*terraform.State) error {
+					db, err := sql.Open("postgres", dsn)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					var owner string
+					if err := db.QueryRow(
+						"SELECT tableowner FROM pg_tables WHERE tablename = 'test_reassign_owned'",
+					).Scan(&owner); err != nil {
+						return fmt.Errorf("could not read the owner of the table: %w", err)
+					}
+					if owner != "test_reassign_new_owner" {
+						return fmt.Errorf("table owned by %s, want test_reassign_new_owner", owner)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+// Test that the objects preventing the role from being dropped are listed.
+func TestAccPostgresqlRole_DropDependencies(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	testConfig := getTestConfig(t)
+	dsn, _ := testConfig.connStr(dbName)
+
+	config := `
+resource "postgresql_role" "owner" {
+  name                = "test_drop_dependencies"
+  skip_reassign_owned = true
+}
+`
+	noRoleConfig := "# no role\n"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: func(*terraform.State) error {
+					dbExecute(t, dsn, "CREATE TABLE test_drop_dependencies (id int)")
+					dbExecute(t, dsn, "ALTER TABLE test_drop_dependencies OWNER TO test_drop_dependencies")
+					dbExecute(t, dsn, fmt.Sprintf("ALTER DATABASE %s OWNER TO test_drop_dependencies", dbName))
+					return nil
+				},
+			},
+			{
+				// The database is a shared object, the table is only counted as it is in another database
+				Config: noRoleConfig,
+				ExpectError: regexp.MustCompile(fmt.Sprintf(
+					`(?s)could not delete role test_drop_dependencies, the following objects depend on it.*`+
+						`owner of database %s.*1 object\(s\) in database %s`, dbName, dbName,
+				)),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, dsn, "DROP TABLE test_drop_dependencies")
+					dbExecute(t, dsn, fmt.Sprintf("ALTER DATABASE %s OWNER TO CURRENT_USER", dbName))
+				},
+				Config: noRoleConfig,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_SuperuserNotAllowed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testNoSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "postgresql_role" "superuser_role" {
+  name      = "superuser_role"
+  superuser = true
+}
+`,
+				ExpectError: regexp.MustCompile("could not set SUPERUSER on role superuser_role: the connected user .* is not a superuser"),
+			},
+		},
+	})
+}
+
+// Test that the changes of the role attributes made outside of Terraform are detected and reverted.
+func TestAccPostgresqlRole_OutOfBandChanges(t *testing.T) {
+	skipIfNotAcc(t)
+
+	var config = `
+resource "postgresql_role" "drift_role" {
+  name             = "drift_role"
+  login            = true
+  connection_limit = 5
+  valid_until      = "2099-01-01 00:00:00+00"
+}
+`
+
+	alterRole := func(statements ...string) func() {
+		return func() {
+			testConfig := getTestConfig(t)
+			dsn, _ := testConfig.connStr("postgres")
+			for _, statement := range statements {
+				dbExecute(t, dsn, statement)
+			}
+		}
+	}
+
+	var steps = []resource.TestStep{
+		{
+			Config: config,
+			Check: resource.ComposeTestCheckFunc(
+				testAccCheckPostgresqlRoleExists(t, "drift_role", nil, nil),
+				resource.TestCheckResourceAttr("postgresql_role.drift_role", "valid_until", "2099-01-01 00:00:00+00"),
+			),
+		},
+		{
+			// The same timestamp in another timezone is not a change
+			PreConfig: alterRole(`ALTER ROLE drift_role VALID UNTIL '2099-01-01 01:00:00+01'`),
+			Config:    config,
+			PlanOnly:  true,
+		},
+	}
+
+	// Each change made outside of Terraform must be detected, then reverted by the apply.
+	for _, statement := range []string{
+		"ALTER ROLE drift_role SUPERUSER",
+		"ALTER ROLE drift_role CREATEDB",
+		"ALTER ROLE drift_role CREATEROLE",
+		"ALTER ROLE drift_role NOINHERIT",
+		"ALTER ROLE drift_role NOLOGIN",
+		"ALTER ROLE drift_role REPLICATION",
+		"ALTER ROLE drift_role BYPASSRLS",
+		"ALTER ROLE drift_role CONNECTION LIMIT 10",
+		"ALTER ROLE drift_role VALID UNTIL '2098-01-01 00:00:00+00'",
+	} {
+		steps = append(steps,
+			resource.TestStep{
+				PreConfig:          alterRole(statement),
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			resource.TestStep{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "superuser", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "create_database", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "create_role", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "inherit", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "login", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "replication", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "bypass_row_level_security", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "connection_limit", "5"),
+					resource.TestCheckResourceAttr("postgresql_role.drift_role", "valid_until", "2099-01-01 00:00:00+00"),
+				),
+			},
+		)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureRLS)
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps:        steps,
+	})
+}
+
+func TestAccPostgresqlRole_Idempotency(t *testing.T) {
+	skipIfNotAcc(t)
+
+	// The session TimeZone changes how PostgreSQL reads the valid_until values without time zone
+	// and how it formats rolvaliduntil
+	var config = `
+provider "postgresql" {
+  session_variables = {
+    TimeZone = "%s"
+  }
+}
+
+resource "postgresql_role" "idempotent_role" {
+  name                                = "idempotent_role"
+  login                               = true
+  password                            = "secret"
+  create_database                     = true
+  create_role                         = true
+  inherit                             = false
+  search_path                         = ["app", "public"]
+  statement_timeout                   = 60000
+  idle_in_transaction_session_timeout = 30000
+  valid_until                         = "%s"
+  %s
+}
+`
+
+	var steps []resource.TestStep
+	for _, test := range []struct {
+		timeZone        string
+		validUntil      string
+		connectionLimit string
+	}{
+		{"Asia/Kolkata", "2099-06-01", ""},
+		{"Asia/Kolkata", "2099-06-01 12:00:00", "connection_limit = -1"},
+		{"America/New_York", "2099-06-01 12:00:00", "connection_limit = -1"},
+		{"America/New_York", "2099-06-01T12:00:00+02:00", "connection_limit = 0"},
+		{"Asia/Kolkata", "2099-06-01T12:00:00+02:00", "connection_limit = 0"},
+		{"UTC", "", ""},
+		{"UTC", "Infinity", ""},
+	} {
+		stepConfig := fmt.Sprintf(config, test.timeZone, test.validUntil, test.connectionLimit)
+		steps = append(steps,
+			resource.TestStep{
+				Config: stepConfig,
+				Check:  testAccCheckPostgresqlRoleExists(t, "idempotent_role", nil, []string{"app", "public"}),
+			},
+			// Applying the same configuration again must not change anything
+			resource.TestStep{
+				Config:   stepConfig,
+				PlanOnly: true,
+			},
+		)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps:        steps,
+	})
+}
+
+func TestAccPostgresqlRole_DatabaseSearchPath(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	config := fmt.Sprintf(`
+resource "postgresql_role" "db_search_path_role" {
+  name        = "db_search_path_role"
+  search_path = ["global"]
+
+  database_search_path {
+    database    = "%s"
+    search_path = ["reporting", "public"]
+  }
+}
+`, dbName)
+
+	configWithout := `
+resource "postgresql_role" "db_search_path_role" {
+  name        = "db_search_path_role"
+  search_path = ["global"]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					// The global search path is not changed by the database one
+					testAccCheckPostgresqlRoleExists(t, "db_search_path_role", nil, []string{"global"}),
+					resource.TestCheckResourceAttr("postgresql_role.db_search_path_role", "database_search_path.#", "1"),
+				),
+			},
+			{
+				// A change made outside of Terraform is detected
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dsn, _ := testConfig.connStr("postgres")
+					dbExecute(t, dsn, fmt.Sprintf("ALTER ROLE db_search_path_role IN DATABASE %s SET search_path = other", dbName))
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: configWithout,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists(t, "db_search_path_role", nil, []string{"global"}),
+					resource.TestCheckResourceAttr("postgresql_role.db_search_path_role", "database_search_path.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestReadRoleValidUntil(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+
+	var tests = []struct {
+		state    string
+		role     string
+		location *time.Location
+		want     string
+	}{
+		{"infinity", "infinity", time.UTC, "infinity"},
+		{"Infinity", "infinity", time.UTC, "Infinity"},
+		{"infinity", "2099-01-01 00:00:00+00", time.UTC, "2099-01-01 00:00:00+00"},
+		{"2099-01-01 00:00:00+00", "2099-01-01 01:00:00+01", time.UTC, "2099-01-01 00:00:00+00"},
+		{"2099-01-01T00:00:00Z", "2099-01-01 00:00:00+00", time.UTC, "2099-01-01T00:00:00Z"},
+		{"2099-01-01", "2099-01-01 00:00:00+00", time.UTC, "2099-01-01"},
+		{"2099-01-01 00:00:00+00", "2098-01-01 00:00:00+00", time.UTC, "2098-01-01 00:00:00+00"},
+		{"2099-01-01 00:00:00+00", "infinity", time.UTC, "infinity"},
+		// The values without time zone are in the TimeZone of the session
+		{"2099-01-01", "2099-01-01 00:00:00+05:30", kolkata, "2099-01-01"},
+		{"2099-01-01 12:00:00", "2099-01-01 12:00:00+05:30", kolkata, "2099-01-01 12:00:00"},
+		{"2099-01-01", "2099-01-01 00:00:00+00", kolkata, "2099-01-01 00:00:00+00"},
+		{"2099-01-01T12:00:00+02:00", "2099-01-01 15:30:00+05:30", kolkata, "2099-01-01T12:00:00+02:00"},
+		// Unknown TimeZone of the session
+		{"2099-01-01", "2099-01-01 00:00:00+00", nil, "2099-01-01"},
+	}
+
+	for _, test := range tests {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{
+			roleNameAttr:       "role",
+			roleValidUntilAttr: test.state,
+		})
+		if got := readRoleValidUntil(d, test.role, test.location); got != test.want {
+			t.Errorf("readRoleValidUntil(%q, %q, %v) = %q, want %q", test.state, test.role, test.location, got, test.want)
+		}
+	}
+}
+
+func TestSameValidUntil(t *testing.T) {
+	var tests = []struct {
+		old  string
+		new  string
+		want bool
+	}{
+		{"infinity", "Infinity", true},
+		{"infinity", "", true},
+		{"2099-01-01 00:00:00+00", "", false},
+		{"2099-01-01 00:00:00+00", "2099-01-01T01:00:00+01:00", true},
+		{"2099-01-01 00:00:00+00", "2099-01-01 00:00:01+00", false},
+		// Depends on the TimeZone of the session, which is not known during the plan
+		{"2099-01-01 00:00:00+00", "2099-01-01", false},
+		{"2099-01-01", "2099-01-01", true},
+		{"infinity", "2099-01-01 00:00:00+00", false},
+	}
+
+	for _, test := range tests {
+		if got := sameValidUntil(test.old, test.new); got != test.want {
+			t.Errorf("sameValidUntil(%q, %q) = %t, want %t", test.old, test.new, got, test.want)
+		}
+	}
+}
+
+func TestRoleUpdateQueries(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "role",
+		Attributes: map[string]string{
+			"id":                      "role",
+			roleNameAttr:              "role",
+			rolePasswordAttr:          "secret",
+			roleScramIterationsAttr:   "0",
+			roleEncryptedPassAttr:     "true",
+			roleValidUntilAttr:        "infinity",
+			roleConnLimitAttr:         "-1",
+			roleSuperuserAttr:         "false",
+			roleCreateDBAttr:          "false",
+			roleCreateRoleAttr:        "false",
+			roleInheritAttr:           "true",
+			roleLoginAttr:             "true",
+			roleReplicationAttr:       "false",
+			roleBypassRLSAttr:         "false",
+			roleSkipDropRoleAttr:      "false",
+			roleSkipReassignOwnedAttr: "false",
+			createIfNotExistsAttr:     "false",
+		},
+	}
+
+	var tests = []struct {
+		name           string
+		config         map[string]interface{}
+		storedPassword string
+		want           []string
+	}{
+		{
+			name:   "no change",
+			config: map[string]interface{}{},
+			want:   nil,
+		},
+		{
+			name:   "password only",
+			config: map[string]interface{}{rolePasswordAttr: "other"},
+			want:   []string{`ALTER ROLE "role" PASSWORD 'other'`},
+		},
+		{
+			name:           "password matching the stored MD5 password",
+			config:         map[string]interface{}{rolePasswordAttr: "other"},
+			storedPassword: "md535989a4f323c26b86ce666ea45580774",
+			want:           nil,
+		},
+		{
+			name:   "password matching the stored SCRAM password",
+			config: map[string]interface{}{rolePasswordAttr: "other"},
+			storedPassword: "SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$" +
+				"lbAtcC61cf6nSxImhkbXEvlcRlbJ5qVSP9xomeePPQo=:3daETpC6DiYs5XcN+naHDKqIKkQ2lMHphk25pYTch4U=",
+			want: nil,
+		},
+		{
+			name:           "password not matching the stored password",
+			config:         map[string]interface{}{rolePasswordAttr: "other"},
+			storedPassword: "md5567b14873ac6387655ab1360847bd421",
+			want:           []string{`ALTER ROLE "role" PASSWORD 'other'`},
+		},
+		{
+			// The password is hashed again even if it matches the stored one
+			name: "scram iterations",
+			config: map[string]interface{}{
+				roleScramIterationsAttr: 8192,
+			},
+			storedPassword: "SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$" +
+				"lbAtcC61cf6nSxImhkbXEvlcRlbJ5qVSP9xomeePPQo=:3daETpC6DiYs5XcN+naHDKqIKkQ2lMHphk25pYTch4U=",
+			want: []string{
+				`SET LOCAL scram_iterations TO 8192`,
+				`ALTER ROLE "role" PASSWORD 'secret'`,
+			},
+		},
+		{
+			// Neither the default connection limit nor an unset or differently cased valid_until is a change
+			name: "defaults",
+			config: map[string]interface{}{
+				roleConnLimitAttr:  -1,
+				roleValidUntilAttr: "",
+			},
+			want: nil,
+		},
+		{
+			name:   "valid_until case",
+			config: map[string]interface{}{roleValidUntilAttr: "Infinity"},
+			want:   nil,
+		},
+		{
+			name: "several attributes in a single statement",
+			config: map[string]interface{}{
+				roleCreateDBAttr:   true,
+				roleLoginAttr:      false,
+				roleConnLimitAttr:  0,
+				roleValidUntilAttr: "2099-01-01",
+			},
+			want: []string{`ALTER ROLE "role" WITH CREATEDB NOLOGIN CONNECTION LIMIT 0 VALID UNTIL '2099-01-01'`},
+		},
+		{
+			name: "attributes and password",
+			config: map[string]interface{}{
+				rolePasswordAttr: "other",
+				roleInheritAttr:  false,
+			},
+			want: []string{
+				`ALTER ROLE "role" WITH NOINHERIT`,
+				`ALTER ROLE "role" PASSWORD 'other'`,
+			},
+		},
+		{
+			// The password is cleared by the rename so it must be set again after it
+			name: "rename",
+			config: map[string]interface{}{
+				roleNameAttr:       "renamed",
+				roleCreateRoleAttr: true,
+			},
+			want: []string{
+				`ALTER ROLE "role" RENAME TO "renamed"`,
+				`ALTER ROLE "renamed" WITH CREATEROLE`,
+				`ALTER ROLE "renamed" PASSWORD 'secret'`,
+			},
+		},
+		{
+			// The MD5 password depends on the role name so it does not match anymore
+			name: "rename with the stored MD5 password",
+			config: map[string]interface{}{
+				roleNameAttr: "renamed",
+			},
+			storedPassword: "md5c73f3ee71d73bd55c2c77c3185b0ed1d",
+			want: []string{
+				`ALTER ROLE "role" RENAME TO "renamed"`,
+				`ALTER ROLE "renamed" PASSWORD 'secret'`,
+			},
+		},
+	}
+
+	db := &DBConnection{version: semver.MustParse("16.0.0")}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				roleNameAttr:     "role",
+				rolePasswordAttr: "secret",
+				roleLoginAttr:    true,
+			}
+			for k, v := range test.config {
+				config[k] = v
+			}
+
+			r := resourcePostgreSQLRole()
+			diff, err := r.Diff(state, terraform.NewResourceConfigRaw(config), nil)
+			if err != nil {
+				t.Fatalf("could not compute diff: %v", err)
+			}
+			d, err := schema.InternalMap(r.Schema).Data(state, diff)
+			if err != nil {
+				t.Fatalf("could not build resource data: %v", err)
+			}
+
+			got, err := roleUpdateQueries(db, d, test.storedPassword)
+			if err != nil {
+				t.Fatalf("roleUpdateQueries() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("roleUpdateQueries() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDatabaseSearchPathQueries(t *testing.T) {
+	searchPath := func(database string, schemas ...interface{}) interface{} {
+		return map[string]interface{}{
+			roleDatabaseSearchPathDatabaseAttr: database,
+			roleSearchPathAttr:                 schemas,
+		}
+	}
+
+	var tests = []struct {
+		name    string
+		old     []interface{}
+		new     []interface{}
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "create",
+			new:  []interface{}{searchPath("reporting", "reporting", "public"), searchPath("app", "app")},
+			want: []string{
+				`ALTER ROLE "role" IN DATABASE "app" SET search_path TO "app"`,
+				`ALTER ROLE "role" IN DATABASE "reporting" SET search_path TO "reporting", "public"`,
+			},
+		},
+		{
+			name: "database removed",
+			old:  []interface{}{searchPath("reporting", "reporting"), searchPath("app", "app")},
+			new:  []interface{}{searchPath("app", "app", "public")},
+			want: []string{
+				`ALTER ROLE "role" IN DATABASE "reporting" RESET search_path`,
+				`ALTER ROLE "role" IN DATABASE "app" SET search_path TO "app", "public"`,
+			},
+		},
+		{
+			name:    "database set twice",
+			new:     []interface{}{searchPath("app", "app"), searchPath("app", "public")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid search path",
+			new:     []interface{}{searchPath("app", "app, public")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := databaseSearchPathQueries("role", test.old, test.new)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("databaseSearchPathQueries() expected an error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("databaseSearchPathQueries() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("databaseSearchPathQueries() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDatabaseSettingsQueries(t *testing.T) {
+	databaseSettings := func(database string, settings map[string]interface{}) interface{} {
+		return map[string]interface{}{
+			roleDatabaseSearchPathDatabaseAttr: database,
+			roleDatabaseSettingsSettingsAttr:   settings,
+		}
+	}
+
+	var tests = []struct {
+		name    string
+		old     []interface{}
+		new     []interface{}
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "create",
+			new: []interface{}{
+				databaseSettings("reporting", map[string]interface{}{"work_mem": "64MB", "app.tenant": "it's"}),
+				databaseSettings("app", map[string]interface{}{"statement_timeout": "30s"}),
+			},
+			want: []string{
+				`ALTER ROLE "role" IN DATABASE "app" SET statement_timeout TO '30s'`,
+				`ALTER ROLE "role" IN DATABASE "reporting" SET app.tenant TO 'it''s'`,
+				`ALTER ROLE "role" IN DATABASE "reporting" SET work_mem TO '64MB'`,
+			},
+		},
+		{
+			name: "settings changed",
+			old: []interface{}{
+				databaseSettings("reporting", map[string]interface{}{"work_mem": "64MB"}),
+				databaseSettings("app", map[string]interface{}{"statement_timeout": "30s", "work_mem": "4MB"}),
+			},
+			new: []interface{}{
+				databaseSettings("app", map[string]interface{}{"statement_timeout": "1min", "work_mem": "4MB"}),
+			},
+			want: []string{
+				`ALTER ROLE "role" IN DATABASE "reporting" RESET work_mem`,
+				`ALTER ROLE "role" IN DATABASE "app" SET statement_timeout TO '1min'`,
+			},
+		},
+		{
+			name: "database set twice",
+			new: []interface{}{
+				databaseSettings("app", map[string]interface{}{"work_mem": "4MB"}),
+				databaseSettings("app", map[string]interface{}{"statement_timeout": "30s"}),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid setting name",
+			new:     []interface{}{databaseSettings("app", map[string]interface{}{"work_mem; DROP": "4MB"})},
+			wantErr: true,
+		},
+		{
+			name:    "search path",
+			new:     []interface{}{databaseSettings("app", map[string]interface{}{"search_path": "app"})},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := databaseSettingsQueries("role", test.old, test.new)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("databaseSettingsQueries() expected an error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("databaseSettingsQueries() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("databaseSettingsQueries() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRoleMembersClause(t *testing.T) {
+	roles := func(names ...interface{}) *schema.Set {
+		return schema.NewSet(schema.HashString, names)
+	}
+
+	var tests = []struct {
+		name    string
+		members *schema.Set
+		admins  *schema.Set
+		want    string
+	}{
+		{"no members", roles(), roles(), ""},
+		{"members", roles("b", "a"), roles(), ` ROLE "a","b"`},
+		{"admins", roles(), roles("c"), ` ADMIN "c"`},
+		{"members and admins", roles("a"), roles("my admin"), ` ROLE "a" ADMIN "my admin"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := roleMembersClause(test.members, test.admins); got != test.want {
+				t.Errorf("roleMembersClause() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRoleMembersUpdateQueries(t *testing.T) {
+	roles := func(names ...interface{}) *schema.Set {
+		return schema.NewSet(schema.HashString, names)
+	}
+
+	var tests = []struct {
+		name       string
+		oldMembers *schema.Set
+		oldAdmins  *schema.Set
+		newMembers *schema.Set
+		newAdmins  *schema.Set
+		want       []string
+	}{
+		{
+			name:       "unchanged",
+			oldMembers: roles("a"),
+			oldAdmins:  roles("b"),
+			newMembers: roles("a"),
+			newAdmins:  roles("b"),
+			want:       []string{},
+		},
+		{
+			name:       "members added and removed",
+			oldMembers: roles("a"),
+			oldAdmins:  roles("b"),
+			newMembers: roles("c"),
+			newAdmins:  roles("d"),
+			want: []string{
+				`GRANT "role" TO "c"`,
+				`GRANT "role" TO "d" WITH ADMIN OPTION`,
+				`REVOKE "role" FROM "a"`,
+				`REVOKE "role" FROM "b"`,
+			},
+		},
+		{
+			name:       "member promoted to admin",
+			oldMembers: roles("a"),
+			oldAdmins:  roles(),
+			newMembers: roles(),
+			newAdmins:  roles("a"),
+			want:       []string{`GRANT "role" TO "a" WITH ADMIN OPTION`},
+		},
+		{
+			name:       "admin demoted to member",
+			oldMembers: roles(),
+			oldAdmins:  roles("a"),
+			newMembers: roles("a"),
+			newAdmins:  roles(),
+			want:       []string{`REVOKE ADMIN OPTION FOR "role" FROM "a"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := roleMembersUpdateQueries("role", test.oldMembers, test.oldAdmins, test.newMembers, test.newAdmins)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("roleMembersUpdateQueries() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
 func testAccCheckPostgresqlRoleDestroy(t *testing.T) func(s *terraform.State) error {
 	return func(s *terraform.State) error {
 		client := getTestProvider(t).Meta().(*Client)
@@ -314,6 +1389,27 @@ func testAccCheckRoleCanLogin(t *testing.T, role, password string) resource.Test
 	}
 }
 
+func testAccCheckRoleHasNoPassword(t *testing.T, role string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := getTestProvider(t).Meta().(*Client)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var hasPassword bool
+		if err := db.QueryRow(
+			"SELECT passwd IS NOT NULL FROM pg_catalog.pg_shadow WHERE usename = $1", role,
+		).Scan(&hasPassword); err != nil {
+			return fmt.Errorf("could not read password of role %s: %v", role, err)
+		}
+		if hasPassword {
+			return fmt.Errorf("role %s should not have a password", role)
+		}
+		return nil
+	}
+}
+
 func checkGrantedRoles(client *Client, roleName string, expectedRoles []string) error {
 	db, err := client.Connect()
 	if err != nil {
@@ -440,3 +1536,35 @@ resource "postgresql_role" "role_with_search_path" {
   search_path = ["bar", "foo-with-hyphen"]
 }
 `
+
+func TestLogicalReplicationIssues(t *testing.T) {
+	var tests = []struct {
+		name     string
+		settings map[string]string
+		want     []string
+	}{
+		{
+			name:     "configured",
+			settings: map[string]string{"wal_level": "logical", "max_replication_slots": "10", "max_wal_senders": "10"},
+			want:     nil,
+		},
+		{
+			name:     "replica",
+			settings: map[string]string{"wal_level": "replica", "max_replication_slots": "10", "max_wal_senders": "10"},
+			want:     []string{`wal_level is "replica" instead of "logical" (it requires a restart)`},
+		},
+		{
+			name:     "no slots",
+			settings: map[string]string{"wal_level": "logical", "max_replication_slots": "0", "max_wal_senders": "0"},
+			want:     []string{"max_replication_slots is 0 (it requires a restart)", "max_wal_senders is 0 (it requires a restart)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := logicalReplicationIssues(test.settings); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("logicalReplicationIssues() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
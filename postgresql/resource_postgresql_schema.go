@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/lib/pq"
 	acl "github.com/sean-/postgresql-acl"
 )
@@ -22,19 +24,28 @@ const (
 	schemaIfNotExists  = "if_not_exists"
 	schemaDropCascade  = "drop_cascade"
 
+	schemaGrantOwnerMembershipAttr = "grant_owner_membership"
+	schemaCleanupDefaultPrivileges = "cleanup_default_privileges"
+
 	schemaPolicyCreateAttr          = "create"
 	schemaPolicyCreateWithGrantAttr = "create_with_grant"
 	schemaPolicyRoleAttr            = "role"
 	schemaPolicyUsageAttr           = "usage"
 	schemaPolicyUsageWithGrantAttr  = "usage_with_grant"
+
+	schemaDefaultPrivilegesAttr                = "default_privileges"
+	schemaDefaultPrivilegesRoleAttr            = "role"
+	schemaDefaultPrivilegesObjectTypeAttr      = "object_type"
+	schemaDefaultPrivilegesPrivilegesAttr      = "privileges"
+	schemaDefaultPrivilegesWithGrantOptionAttr = "with_grant_option"
 )
 
 func resourcePostgreSQLSchema() *schema.Resource {
 	return &schema.Resource{
 		Create: PGResourceFunc(resourcePostgreSQLSchemaCreate),
-		Read:   PGResourceFunc(resourcePostgreSQLSchemaRead),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLSchemaRead)),
 		Update: PGResourceFunc(resourcePostgreSQLSchemaUpdate),
-		Delete: PGResourceFunc(resourcePostgreSQLSchemaDelete),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLSchemaDelete)),
 		Exists: PGResourceExistsFunc(resourcePostgreSQLSchemaExists),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -71,6 +82,20 @@ func resourcePostgreSQLSchema() *schema.Resource {
 				Default:     false,
 				Description: "When true, will also drop all the objects that are contained in the schema",
 			},
+			schemaCleanupDefaultPrivileges: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+				Description: "When true, the default privileges defined in the schema (ALTER DEFAULT PRIVILEGES ... IN SCHEMA) " +
+					"are revoked before dropping it, whatever their owner",
+			},
+			schemaGrantOwnerMembershipAttr: {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+				Description: "When true, the connected user is temporarily granted the membership of the owners if needed, " +
+					"otherwise it must already be a member of them",
+			},
 			schemaPolicyAttr: {
 				Type:       schema.TypeSet,
 				Optional:   true,
@@ -116,46 +141,97 @@ func resourcePostgreSQLSchema() *schema.Resource {
 					},
 				},
 			},
+			schemaDefaultPrivilegesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Default privileges of the objects created by the schema owner in the schema",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						schemaDefaultPrivilegesRoleAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The role to which grant the default privileges",
+						},
+						schemaDefaultPrivilegesObjectTypeAttr: {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								"table",
+								"sequence",
+								"function",
+								"type",
+							}, false),
+							Description: "The PostgreSQL object type to set the default privileges on (one of: table, sequence, function, type)",
+						},
+						schemaDefaultPrivilegesPrivilegesAttr: {
+							Type:        schema.TypeSet,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Set:         schema.HashString,
+							MinItems:    1,
+							Description: "The list of privileges to apply as default privileges",
+						},
+						schemaDefaultPrivilegesWithGrantOptionAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Permit the grant recipient to grant it to others",
+						},
+					},
+				},
+			},
+			lockTimeoutAttr: lockTimeoutSchema(),
 		},
 	}
 }
 
 func resourcePostgreSQLSchemaCreate(db *DBConnection, d *schema.ResourceData) error {
-	database := getDatabase(d, db.client.databaseName)
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
+	if err := validateSchemaDefaultPrivileges(db, d); err != nil {
 		return err
 	}
-	defer deferredRollback(txn)
 
-	// If the authenticated user is not a superuser (e.g. on AWS RDS)
-	// we'll need to temporarily grant it membership in the following roles:
-	//  * the owner of the db (to have the permissions to create the schema)
-	//  * the owner of the schema, if it has one (in order to change its owner)
-	var rolesToGrant []string
+	database := getDatabase(d, db.client.databaseName)
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
 
-	dbOwner, err := getDatabaseOwner(txn, database)
-	if err != nil {
-		return err
-	}
-	rolesToGrant = append(rolesToGrant, dbOwner)
+		// If the authenticated user is not a superuser (e.g. on AWS RDS)
+		// we'll need to temporarily grant it membership in the following roles:
+		//  * the owner of the db (to have the permissions to create the schema)
+		//  * the owner of the schema, if it has one (in order to change its owner)
+		var rolesToGrant []string
 
-	schemaOwner := d.Get("owner").(string)
-	if schemaOwner != "" && schemaOwner != dbOwner {
-		rolesToGrant = append(rolesToGrant, schemaOwner)
+		schemaOwner := d.Get("owner").(string)
+		if d.Get(schemaGrantOwnerMembershipAttr).(bool) {
+			dbOwner, err := getDatabaseOwner(txn, database)
+			if err != nil {
+				return err
+			}
+			rolesToGrant = append(rolesToGrant, dbOwner)
+		}
+		if schemaOwner != "" && !sliceContainsStr(rolesToGrant, schemaOwner) {
+			rolesToGrant = append(rolesToGrant, schemaOwner)
+		}
 
-	}
+		return withSchemaOwnersGranted(txn, d, rolesToGrant, func() error {
+			if err := createSchema(db, txn, d); err != nil {
+				return err
+			}
 
-	if err := withRolesGranted(txn, rolesToGrant, func() error {
-		return createSchema(db, txn, d)
+			// The default privileges are defined for the actual owner of the schema,
+			// which is the connected user if no owner is configured.
+			schemaName := d.Get(schemaNameAttr).(string)
+			owner, err := getSchemaOwner(txn, schemaName)
+			if err != nil {
+				return err
+			}
+			return applySchemaDefaultPrivileges(txn, schemaName, "", owner, nil, d.Get(schemaDefaultPrivilegesAttr).(*schema.Set).List())
+		})
 	}); err != nil {
 		return err
 	}
 
-	if err := txn.Commit(); err != nil {
-		return fmt.Errorf("Error committing schema: %w", err)
-	}
-
 	d.SetId(generateSchemaID(d, database))
 
 	return resourcePostgreSQLSchemaReadImpl(db, d)
@@ -171,19 +247,12 @@ func createSchema(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 	queries := []string{}
 	switch {
 	case err == sql.ErrNoRows:
-		b := bytes.NewBufferString("CREATE SCHEMA ")
-		if db.featureSupported(featureSchemaCreateIfNotExist) {
-			if v := d.Get(schemaIfNotExists); v.(bool) {
-				fmt.Fprint(b, "IF NOT EXISTS ")
-			}
-		}
-		fmt.Fprint(b, pq.QuoteIdentifier(schemaName))
-
-		switch v, ok := d.GetOk(schemaOwnerAttr); {
-		case ok:
-			fmt.Fprint(b, " AUTHORIZATION ", pq.QuoteIdentifier(v.(string)))
+		currentUser, err := getCurrentUser(txn)
+		if err != nil {
+			return err
 		}
-		queries = append(queries, b.String())
+		ifNotExists := db.featureSupported(featureSchemaCreateIfNotExist) && d.Get(schemaIfNotExists).(bool)
+		queries = append(queries, createSchemaQuery(schemaName, d.Get(schemaOwnerAttr).(string), currentUser, ifNotExists))
 
 	case err != nil:
 		return fmt.Errorf("Error looking for schema: %w", err)
@@ -225,6 +294,13 @@ func createSchema(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 
 	for _, query := range queries {
 		if _, err = txn.Exec(query); err != nil {
+			if owner, ok := d.GetOk(schemaOwnerAttr); ok && isInsufficientPrivilege(err) {
+				return fmt.Errorf(
+					"Error creating schema %s, the connected user needs the CREATE privilege on the database "+
+						"and to be a member of role %s: %w",
+					schemaName, owner, err,
+				)
+			}
 			return fmt.Errorf("Error creating schema %s: %w", schemaName, err)
 		}
 	}
@@ -235,45 +311,59 @@ func createSchema(db *DBConnection, txn *sql.Tx, d *schema.ResourceData) error {
 func resourcePostgreSQLSchemaDelete(db *DBConnection, d *schema.ResourceData) error {
 	database := getDatabase(d, db.client.databaseName)
 
-	txn, err := startTransaction(db.client, database)
-	if err != nil {
-		return err
-	}
-	defer deferredRollback(txn)
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
 
-	schemaName := d.Get(schemaNameAttr).(string)
+		schemaName := d.Get(schemaNameAttr).(string)
 
-	exists, err := schemaExists(txn, schemaName)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		d.SetId("")
-		return nil
-	}
+		exists, err := schemaExists(txn, schemaName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			d.SetId("")
+			return nil
+		}
 
-	owner := d.Get("owner").(string)
+		owners := []string{d.Get("owner").(string)}
 
-	if err = withRolesGranted(txn, []string{owner}, func() error {
-		dropMode := "RESTRICT"
-		if d.Get(schemaDropCascade).(bool) {
-			dropMode = "CASCADE"
+		var cleanupQueries []string
+		if d.Get(schemaCleanupDefaultPrivileges).(bool) {
+			entries, err := readSchemaDefaultACLEntries(txn, schemaName)
+			if err != nil {
+				return fmt.Errorf("could not read default privileges of schema %s: %w", schemaName, err)
+			}
+			cleanupQueries = schemaDefaultACLCleanupQueries(entries)
+			// Needed in order to alter the default privileges of their owners if the connection user is not a superuser
+			owners = append(owners, defaultACLEntriesOwners(entries)...)
 		}
 
-		sql := fmt.Sprintf("DROP SCHEMA %s %s", pq.QuoteIdentifier(schemaName), dropMode)
-		if _, err = txn.Exec(sql); err != nil {
-			return fmt.Errorf("Error deleting schema: %w", err)
-		}
+		return withSchemaOwnersGranted(txn, d, owners, func() error {
+			for _, query := range cleanupQueries {
+				log.Printf("[DEBUG] cleaning up default privileges of schema %s: %s", schemaName, query)
+				if _, err := txn.Exec(query); err != nil {
+					return fmt.Errorf("could not cleanup default privileges of schema %s: %w", schemaName, err)
+				}
+			}
 
-		return nil
+			dropMode := "RESTRICT"
+			if d.Get(schemaDropCascade).(bool) {
+				dropMode = "CASCADE"
+			}
+
+			sql := fmt.Sprintf("DROP SCHEMA %s %s", pq.QuoteIdentifier(schemaName), dropMode)
+			if _, err = txn.Exec(sql); err != nil {
+				return fmt.Errorf("Error deleting schema: %w", err)
+			}
+
+			return nil
+		})
 	}); err != nil {
 		return err
 	}
 
-	if err := txn.Commit(); err != nil {
-		return fmt.Errorf("Error committing schema: %w", err)
-	}
-
 	d.SetId("")
 
 	return nil
@@ -358,6 +448,10 @@ func resourcePostgreSQLSchemaReadImpl(db *DBConnection, d *schema.ResourceData)
 			schemaPolicies[roleKey] = mergedPolicy
 		}
 
+		if err := readSchemaDefaultPrivileges(txn, d, schemaName, schemaOwner); err != nil {
+			return err
+		}
+
 		d.Set(schemaNameAttr, schemaName)
 		d.Set(schemaOwnerAttr, schemaOwner)
 		d.Set(schemaDatabaseAttr, database)
@@ -368,31 +462,85 @@ func resourcePostgreSQLSchemaReadImpl(db *DBConnection, d *schema.ResourceData)
 }
 
 func resourcePostgreSQLSchemaUpdate(db *DBConnection, d *schema.ResourceData) error {
-	databaseName := getDatabase(d, db.client.databaseName)
-
-	txn, err := startTransaction(db.client, databaseName)
-	if err != nil {
+	if err := validateSchemaDefaultPrivileges(db, d); err != nil {
 		return err
 	}
-	defer deferredRollback(txn)
 
-	if err := setSchemaName(txn, d, databaseName); err != nil {
+	databaseName := getDatabase(d, db.client.databaseName)
+
+	if err := withTransaction(db.client, databaseName, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
+
+		if err := setSchemaName(txn, d, databaseName); err != nil {
+			return err
+		}
+
+		// If the authenticated user is not a superuser (e.g. on AWS RDS)
+		// it needs to be a member of the old and the new owners to change it.
+		if d.HasChange(schemaOwnerAttr) {
+			oldOwner, newOwner := d.GetChange(schemaOwnerAttr)
+			rolesToGrant := []string{}
+			for _, owner := range []string{oldOwner.(string), newOwner.(string)} {
+				if owner != "" && !sliceContainsStr(rolesToGrant, owner) {
+					rolesToGrant = append(rolesToGrant, owner)
+				}
+			}
+			if err := withSchemaOwnersGranted(txn, d, rolesToGrant, func() error {
+				return setSchemaOwner(txn, d)
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := setSchemaPolicy(txn, d); err != nil {
+			return err
+		}
+
+		return setSchemaDefaultPrivileges(txn, d)
+	}); err != nil {
 		return err
 	}
 
-	if err := setSchemaOwner(txn, d); err != nil {
-		return err
+	return resourcePostgreSQLSchemaReadImpl(db, d)
+}
+
+// withSchemaOwnersGranted executes fn with the membership of the owners granted temporarily if needed
+// or, if grant_owner_membership is false, after checking the connected user is already a member of them.
+func withSchemaOwnersGranted(txn *sql.Tx, d *schema.ResourceData, owners []string, fn func() error) error {
+	if d.Get(schemaGrantOwnerMembershipAttr).(bool) {
+		return withRolesGranted(txn, owners, fn)
 	}
 
-	if err := setSchemaPolicy(txn, d); err != nil {
-		return err
+	for _, owner := range owners {
+		if owner == "" {
+			continue
+		}
+		if err := checkRoleMembership(txn, owner, fmt.Sprintf(
+			"manage the schema %s it owns (or set %s to true)", d.Get(schemaNameAttr), schemaGrantOwnerMembershipAttr,
+		)); err != nil {
+			return err
+		}
 	}
+	return fn()
+}
 
-	if err := txn.Commit(); err != nil {
-		return fmt.Errorf("Error committing schema: %w", err)
+// createSchemaQuery returns the query creating the schema.
+// The owner is set in the same statement with AUTHORIZATION, as a role which is not a superuser
+// can create a schema owned by a role it is a member of but may not be able to change its owner afterwards.
+// It is omitted if the owner is the current user, who owns the schema anyway.
+func createSchemaQuery(schemaName, owner, currentUser string, ifNotExists bool) string {
+	b := bytes.NewBufferString("CREATE SCHEMA ")
+	if ifNotExists {
+		fmt.Fprint(b, "IF NOT EXISTS ")
 	}
+	fmt.Fprint(b, pq.QuoteIdentifier(schemaName))
 
-	return resourcePostgreSQLSchemaReadImpl(db, d)
+	if owner != "" && owner != currentUser {
+		fmt.Fprint(b, " AUTHORIZATION ", pq.QuoteIdentifier(owner))
+	}
+	return b.String()
 }
 
 func setSchemaName(txn *sql.Tx, d *schema.ResourceData, databaseName string) error {
@@ -428,12 +576,7 @@ func setSchemaOwner(txn *sql.Tx, d *schema.ResourceData) error {
 		return errors.New("Error setting schema owner to an empty string")
 	}
 
-	sql := fmt.Sprintf("ALTER SCHEMA %s OWNER TO %s", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(schemaOwner))
-	if _, err := txn.Exec(sql); err != nil {
-		return fmt.Errorf("Error updating schema OWNER: %w", err)
-	}
-
-	return nil
+	return setObjectOwner(txn, "SCHEMA", pq.QuoteIdentifier(schemaName), schemaOwner)
 }
 
 func setSchemaPolicy(txn *sql.Tx, d *schema.ResourceData) error {
@@ -511,6 +654,139 @@ func setSchemaPolicy(txn *sql.Tx, d *schema.ResourceData) error {
 	})
 }
 
+// validateSchemaDefaultPrivileges checks the privileges of the default_privileges blocks.
+func validateSchemaDefaultPrivileges(db *DBConnection, d *schema.ResourceData) error {
+	for _, raw := range d.Get(schemaDefaultPrivilegesAttr).(*schema.Set).List() {
+		entry := raw.(map[string]interface{})
+		if err := validateObjectPrivileges(
+			db,
+			entry[schemaDefaultPrivilegesObjectTypeAttr].(string),
+			entry[schemaDefaultPrivilegesPrivilegesAttr].(*schema.Set).List(),
+		); err != nil {
+			return fmt.Errorf("invalid default privileges for role %s: %w", entry[schemaDefaultPrivilegesRoleAttr], err)
+		}
+	}
+	return nil
+}
+
+// setSchemaDefaultPrivileges updates the default privileges if they changed,
+// or moves them to the new owner if the schema owner changed.
+func setSchemaDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(schemaDefaultPrivilegesAttr) && !d.HasChange(schemaOwnerAttr) {
+		return nil
+	}
+
+	schemaName := d.Get(schemaNameAttr).(string)
+	oldOwner, newOwner := d.GetChange(schemaOwnerAttr)
+	oldPrivileges, newPrivileges := d.GetChange(schemaDefaultPrivilegesAttr)
+	if oldPrivileges.(*schema.Set).Len() == 0 && newPrivileges.(*schema.Set).Len() == 0 {
+		return nil
+	}
+
+	// The owner may not be set in the configuration, in this case it is the one of the state.
+	owner := newOwner.(string)
+	if owner == "" {
+		var err error
+		if owner, err = getSchemaOwner(txn, schemaName); err != nil {
+			return err
+		}
+	}
+
+	rolesToGrant := []string{owner}
+	if oldOwner.(string) != "" && oldOwner.(string) != owner {
+		rolesToGrant = append(rolesToGrant, oldOwner.(string))
+	}
+
+	return withSchemaOwnersGranted(txn, d, rolesToGrant, func() error {
+		return applySchemaDefaultPrivileges(
+			txn, schemaName, oldOwner.(string), owner,
+			oldPrivileges.(*schema.Set).List(), newPrivileges.(*schema.Set).List(),
+		)
+	})
+}
+
+// applySchemaDefaultPrivileges replaces the default privileges defined by oldOwner in the schema
+// by the ones defined by newOwner.
+// Revoking and granting them in the same transaction prevents the roles from losing their privileges
+// in between.
+func applySchemaDefaultPrivileges(txn *sql.Tx, schemaName, oldOwner, newOwner string, old, new []interface{}) error {
+	for _, query := range schemaDefaultPrivilegesQueries(schemaName, oldOwner, newOwner, old, new) {
+		if _, err := txn.Exec(query); err != nil {
+			return fmt.Errorf("could not alter default privileges of schema %s: %w", schemaName, err)
+		}
+	}
+	return nil
+}
+
+// schemaDefaultPrivilegesQueries generates the queries revoking the old default privileges
+// and granting the new ones.
+func schemaDefaultPrivilegesQueries(schemaName, oldOwner, newOwner string, old, new []interface{}) []string {
+	var queries []string
+
+	if oldOwner != "" {
+		for _, raw := range old {
+			entry := raw.(map[string]interface{})
+			queries = append(queries, revokeDefaultPrivilegesQuery(
+				oldOwner, schemaName,
+				entry[schemaDefaultPrivilegesObjectTypeAttr].(string),
+				entry[schemaDefaultPrivilegesRoleAttr].(string),
+			))
+		}
+	}
+
+	for _, raw := range new {
+		entry := raw.(map[string]interface{})
+
+		var privileges []string
+		for _, priv := range entry[schemaDefaultPrivilegesPrivilegesAttr].(*schema.Set).List() {
+			privileges = append(privileges, priv.(string))
+		}
+		sort.Strings(privileges)
+
+		queries = append(queries, grantDefaultPrivilegesQuery(
+			newOwner, schemaName,
+			entry[schemaDefaultPrivilegesObjectTypeAttr].(string),
+			entry[schemaDefaultPrivilegesRoleAttr].(string),
+			privileges,
+			entry[schemaDefaultPrivilegesWithGrantOptionAttr].(bool),
+		))
+	}
+
+	return queries
+}
+
+// readSchemaDefaultPrivileges refreshes the default privileges managed by the resource.
+// Other default privileges of the owner in the schema (e.g.: managed by postgresql_default_privileges)
+// are ignored.
+func readSchemaDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData, schemaName, owner string) error {
+	entries := d.Get(schemaDefaultPrivilegesAttr).(*schema.Set).List()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	result := make([]interface{}, 0, len(entries))
+	for _, raw := range entries {
+		entry := raw.(map[string]interface{})
+		role := entry[schemaDefaultPrivilegesRoleAttr].(string)
+
+		privileges, err := readDefaultPrivileges(
+			txn, owner, schemaName, entry[schemaDefaultPrivilegesObjectTypeAttr].(string), role,
+		)
+		if err != nil {
+			return err
+		}
+		if len(privileges) == 0 {
+			log.Printf("[DEBUG] no default privileges for role %s in schema %s", role, schemaName)
+			continue
+		}
+
+		entry[schemaDefaultPrivilegesPrivilegesAttr] = pgArrayToSet(privileges)
+		result = append(result, entry)
+	}
+
+	return d.Set(schemaDefaultPrivilegesAttr, result)
+}
+
 // schemaChangedPolicies walks old and new to create a set of queries that can
 // be executed to enact each type of state change (roles that have been dropped
 // from the policy, added to a policy, have updated privilges, or are
@@ -3,9 +3,11 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -267,6 +269,168 @@ resource "postgresql_schema" "public" {
 	})
 }
 
+func TestAccPostgresqlSchema_UpdateOwner(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testAccPostgresqlSchemaConfig = `
+resource "postgresql_role" "new_owner" {
+  name = "tf_tests_schema_new_owner"
+}
+
+resource "postgresql_schema" "test_owner" {
+  name     = "test_owner"
+  database = "%s"
+  owner    = %s
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaConfig, dbName, fmt.Sprintf("%q", roleName)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema.test_owner", "owner", roleName),
+					testAccCheckSchemaOwner(t, dbName, "test_owner", roleName),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaConfig, dbName, "postgresql_role.new_owner.name"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema.test_owner", "owner", "tf_tests_schema_new_owner"),
+					testAccCheckSchemaOwner(t, dbName, "test_owner", "tf_tests_schema_new_owner"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_NoOwnerMembershipGrant(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			// Superusers are members of all roles.
+			testSuperuserPreCheck(t)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "postgresql_schema" "test_owner" {
+  name                   = "test_owner"
+  database               = "%s"
+  owner                  = "%s"
+  grant_owner_membership = false
+}
+`, dbName, roleName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema.test_owner", "grant_owner_membership", "false"),
+					testAccCheckSchemaOwner(t, dbName, "test_owner", roleName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_DefaultPrivileges(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	config := `
+resource "postgresql_role" "reporting" {
+  name = "tf_tests_schema_reporting"
+}
+
+resource "postgresql_schema" "test_default_privileges" {
+  name     = "test_default_privileges"
+  database = "%s"
+  owner    = "%s"
+
+  default_privileges {
+    role        = postgresql_role.reporting.name
+    object_type = "table"
+    privileges  = [%s]
+  }
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, dbName, roleName, `"SELECT"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_schema.test_default_privileges", "default_privileges.#", "1"),
+					testAccCheckSchemaDefaultPrivileges(t, dbName, "test_default_privileges", roleName, "tf_tests_schema_reporting", "SELECT"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, dbName, roleName, `"SELECT", "UPDATE"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSchemaDefaultPrivileges(t, dbName, "test_default_privileges", roleName, "tf_tests_schema_reporting", "SELECT,UPDATE"),
+				),
+			},
+			{
+				// Default privileges revoked outside of Terraform must be detected.
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dsn, _ := testConfig.connStr(dbName)
+					dbExecute(t, dsn, fmt.Sprintf(
+						"ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA test_default_privileges REVOKE UPDATE ON TABLES FROM tf_tests_schema_reporting",
+						roleName,
+					))
+				},
+				Config:             fmt.Sprintf(config, dbName, roleName, `"SELECT", "UPDATE"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccCheckSchemaDefaultPrivileges(t *testing.T, database, schemaName, owner, role, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := getTestProvider(t).Meta().(*Client).config.NewClient(database)
+		db, err := client.Connect()
+		if err != nil {
+			return err
+		}
+
+		var privileges string
+		query := "SELECT string_agg(acl.privilege_type, ',' ORDER BY acl.privilege_type) " +
+			"FROM pg_catalog.pg_default_acl a JOIN pg_catalog.pg_namespace n ON n.oid = a.defaclnamespace, " +
+			"aclexplode(a.defaclacl) acl " +
+			"WHERE n.nspname = $1 AND pg_get_userbyid(a.defaclrole) = $2 AND pg_get_userbyid(acl.grantee) = $3"
+		if err := db.QueryRow(query, schemaName, owner, role).Scan(&privileges); err != nil {
+			return fmt.Errorf("could not read default privileges of schema %s: %w", schemaName, err)
+		}
+
+		if privileges != expected {
+			return fmt.Errorf("expected default privileges of %s in schema %s to be %s; got %s", role, schemaName, expected, privileges)
+		}
+		return nil
+	}
+}
+
 func testAccCheckPostgresqlSchemaDestroy(t *testing.T) func(s *terraform.State) error {
 	return func(s *terraform.State) error {
 		client := getTestProvider(t).Meta().(*Client)
@@ -584,3 +748,85 @@ resource "postgresql_schema" "test4" {
   }
 }
 `
+
+func TestCreateSchemaQuery(t *testing.T) {
+	var tests = []struct {
+		owner       string
+		ifNotExists bool
+		expected    string
+	}{
+		{"", false, `CREATE SCHEMA "myschema"`},
+		{"app", false, `CREATE SCHEMA "myschema" AUTHORIZATION "app"`},
+		{"app", true, `CREATE SCHEMA IF NOT EXISTS "myschema" AUTHORIZATION "app"`},
+		// The current user owns the schema it creates
+		{"admin", false, `CREATE SCHEMA "myschema"`},
+	}
+
+	for _, test := range tests {
+		if got := createSchemaQuery("myschema", test.owner, "admin", test.ifNotExists); got != test.expected {
+			t.Errorf("createSchemaQuery(%q, %t) = %q, want %q", test.owner, test.ifNotExists, got, test.expected)
+		}
+	}
+}
+
+func TestSchemaDefaultPrivilegesQueries(t *testing.T) {
+	entry := func(role, objectType string, withGrantOption bool, privileges ...interface{}) interface{} {
+		return map[string]interface{}{
+			"role":              role,
+			"object_type":       objectType,
+			"privileges":        schema.NewSet(schema.HashString, privileges),
+			"with_grant_option": withGrantOption,
+		}
+	}
+
+	var tests = []struct {
+		description string
+		oldOwner    string
+		newOwner    string
+		old         []interface{}
+		new         []interface{}
+		expected    []string
+	}{
+		{
+			"creation",
+			"",
+			"app",
+			nil,
+			[]interface{}{entry("reporting", "table", false, "SELECT", "INSERT")},
+			[]string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "app" IN SCHEMA "myschema" GRANT INSERT,SELECT ON TABLES TO "reporting"`,
+			},
+		},
+		{
+			"privileges update",
+			"app",
+			"app",
+			[]interface{}{entry("reporting", "table", false, "SELECT")},
+			[]interface{}{entry("reporting", "sequence", true, "USAGE")},
+			[]string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "app" IN SCHEMA "myschema" REVOKE ALL ON TABLES FROM "reporting"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "app" IN SCHEMA "myschema" GRANT USAGE ON SEQUENCES TO "reporting" WITH GRANT OPTION`,
+			},
+		},
+		{
+			"owner update",
+			"app",
+			"new_app",
+			[]interface{}{entry("reporting", "table", false, "SELECT")},
+			[]interface{}{entry("reporting", "table", false, "SELECT")},
+			[]string{
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "app" IN SCHEMA "myschema" REVOKE ALL ON TABLES FROM "reporting"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "new_app" IN SCHEMA "myschema" GRANT SELECT ON TABLES TO "reporting"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			queries := schemaDefaultPrivilegesQueries("myschema", test.oldOwner, test.newOwner, test.old, test.new)
+			if !reflect.DeepEqual(queries, test.expected) {
+				t.Errorf("expected queries %v, got %v", test.expected, queries)
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package postgresql
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	seqNameAttr      = "name"
+	seqSchemaAttr    = "schema"
+	seqDatabaseAttr  = "database"
+	seqIncrementAttr = "increment"
+	seqMinValueAttr  = "min_value"
+	seqMaxValueAttr  = "max_value"
+	seqStartAttr     = "start"
+	seqCacheAttr     = "cache"
+	seqCycleAttr     = "cycle"
+	seqOwnedByAttr   = "owned_by"
+)
+
+func resourcePostgreSQLSequence() *schema.Resource {
+	return &schema.Resource{
+		Create: PGResourceFunc(resourcePostgreSQLSequenceCreate),
+		Read:   removeIfNotFound(PGResourceFunc(resourcePostgreSQLSequenceRead)),
+		Update: PGResourceFunc(resourcePostgreSQLSequenceUpdate),
+		Delete: removeIfNotFound(PGResourceFunc(resourcePostgreSQLSequenceDelete)),
+		Exists: PGResourceExistsFunc(resourcePostgreSQLSequenceExists),
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			seqNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the sequence",
+			},
+			seqSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema in which the sequence is created",
+			},
+			seqDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database in which the sequence is created",
+			},
+			seqIncrementAttr: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1,
+				Description: "The value added to the current sequence value to create a new value",
+			},
+			seqMinValueAttr: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "The minimum value the sequence can generate",
+			},
+			seqMaxValueAttr: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "The maximum value the sequence can generate",
+			},
+			seqStartAttr: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "The starting value of the sequence",
+			},
+			seqCacheAttr: {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1,
+				Description: "How many sequence numbers are preallocated and stored in memory",
+			},
+			seqCycleAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the sequence wraps around when the max or min value is reached",
+			},
+			seqOwnedByAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The table column (as table.column) owning the sequence. The sequence is dropped with the column",
+			},
+			lockTimeoutAttr: lockTimeoutSchema(),
+		},
+	}
+}
+
+func resourcePostgreSQLSequenceCreate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureSequence) {
+		return unsupportedVersionError(db, "postgresql_sequence resource")
+	}
+
+	database := getDatabase(d, db.client.databaseName)
+	seqName := d.Get(seqNameAttr).(string)
+	seqSchema := d.Get(seqSchemaAttr).(string)
+
+	b := bytes.NewBufferString("CREATE SEQUENCE ")
+	fmt.Fprint(b, pq.QuoteIdentifier(seqSchema), ".", pq.QuoteIdentifier(seqName))
+	fmt.Fprint(b, " INCREMENT BY ", d.Get(seqIncrementAttr).(int))
+	if v, ok := d.GetOk(seqMinValueAttr); ok {
+		fmt.Fprint(b, " MINVALUE ", v.(int))
+	}
+	if v, ok := d.GetOk(seqMaxValueAttr); ok {
+		fmt.Fprint(b, " MAXVALUE ", v.(int))
+	}
+	if v, ok := d.GetOk(seqStartAttr); ok {
+		fmt.Fprint(b, " START WITH ", v.(int))
+	}
+	fmt.Fprint(b, " CACHE ", d.Get(seqCacheAttr).(int))
+	if d.Get(seqCycleAttr).(bool) {
+		fmt.Fprint(b, " CYCLE")
+	} else {
+		fmt.Fprint(b, " NO CYCLE")
+	}
+	if v, ok := d.GetOk(seqOwnedByAttr); ok {
+		ownedBy, err := sequenceOwnedByClause(seqSchema, v.(string))
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(b, " OWNED BY ", ownedBy)
+	}
+
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
+
+		if _, err := txn.Exec(b.String()); err != nil {
+			return fmt.Errorf("Error creating sequence %s: %w", seqName, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	invalidateCachedObjectsPrivileges(db.client, database, seqSchema, "sequence")
+
+	d.SetId(generateSequenceID(database, seqSchema, seqName))
+
+	return resourcePostgreSQLSequenceReadImpl(db, d)
+}
+
+func resourcePostgreSQLSequenceExists(db *DBConnection, d *schema.ResourceData) (bool, error) {
+	if !db.featureSupported(featureSequence) {
+		return false, unsupportedVersionError(db, "postgresql_sequence resource")
+	}
+
+	database, seqSchema, seqName, err := getDBSequenceName(d, db.client.databaseName)
+	if err != nil {
+		return false, err
+	}
+
+	// Check if the database exists
+	exists, err := dbExists(db, database)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return false, err
+	}
+	defer deferredRollback(txn)
+
+	var found bool
+	query := "SELECT true FROM pg_catalog.pg_sequences WHERE schemaname = $1 AND sequencename = $2"
+	err = txn.QueryRow(query, seqSchema, seqName).Scan(&found)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("could not check if sequence exists: %w", err)
+	}
+
+	return true, nil
+}
+
+func resourcePostgreSQLSequenceRead(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureSequence) {
+		return unsupportedVersionError(db, "postgresql_sequence resource")
+	}
+
+	return resourcePostgreSQLSequenceReadImpl(db, d)
+}
+
+func resourcePostgreSQLSequenceReadImpl(db *DBConnection, d *schema.ResourceData) error {
+	database, seqSchema, seqName, err := getDBSequenceName(d, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+
+	txn, err := startTransaction(db.client, database)
+	if err != nil {
+		return err
+	}
+	defer deferredRollback(txn)
+
+	var increment, minValue, maxValue, start, cache int
+	var cycle bool
+	query := "SELECT increment_by, min_value, max_value, start_value, cache_size, cycle " +
+		"FROM pg_catalog.pg_sequences WHERE schemaname = $1 AND sequencename = $2"
+	err = txn.QueryRow(query, seqSchema, seqName).Scan(
+		&increment, &minValue, &maxValue, &start, &cache, &cycle,
+	)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL sequence %s.%s not found in database %s", seqSchema, seqName, database)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return fmt.Errorf("Error reading sequence: %w", err)
+	}
+
+	ownedBy, err := getSequenceOwnedBy(txn, seqSchema, seqName)
+	if err != nil {
+		return err
+	}
+
+	_ = d.Set(seqNameAttr, seqName)
+	_ = d.Set(seqSchemaAttr, seqSchema)
+	_ = d.Set(seqDatabaseAttr, database)
+	_ = d.Set(seqIncrementAttr, increment)
+	_ = d.Set(seqMinValueAttr, minValue)
+	_ = d.Set(seqMaxValueAttr, maxValue)
+	_ = d.Set(seqStartAttr, start)
+	_ = d.Set(seqCacheAttr, cache)
+	_ = d.Set(seqCycleAttr, cycle)
+	_ = d.Set(seqOwnedByAttr, ownedBy)
+	d.SetId(generateSequenceID(database, seqSchema, seqName))
+
+	return nil
+}
+
+func resourcePostgreSQLSequenceUpdate(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureSequence) {
+		return unsupportedVersionError(db, "postgresql_sequence resource")
+	}
+
+	database, seqSchema, seqName, err := getDBSequenceName(d, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
+
+		if err := setSequenceOptions(txn, d, seqSchema, seqName); err != nil {
+			return err
+		}
+
+		return setSequenceOwnedBy(txn, d, seqSchema, seqName)
+	}); err != nil {
+		return err
+	}
+	invalidateCachedObjectsPrivileges(db.client, database, seqSchema, "sequence")
+
+	return resourcePostgreSQLSequenceReadImpl(db, d)
+}
+
+func resourcePostgreSQLSequenceDelete(db *DBConnection, d *schema.ResourceData) error {
+	if !db.featureSupported(featureSequence) {
+		return unsupportedVersionError(db, "postgresql_sequence resource")
+	}
+
+	database, seqSchema, seqName, err := getDBSequenceName(d, db.client.databaseName)
+	if err != nil {
+		return err
+	}
+
+	if err := withTransaction(db.client, database, func(txn *sql.Tx) error {
+		if err := setLockTimeout(txn, d); err != nil {
+			return err
+		}
+
+		sql := fmt.Sprintf("DROP SEQUENCE IF EXISTS %s.%s",
+			pq.QuoteIdentifier(seqSchema), pq.QuoteIdentifier(seqName))
+		if _, err := txn.Exec(sql); err != nil {
+			return fmt.Errorf("Error deleting sequence %s: %w", seqName, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	invalidateCachedObjectsPrivileges(db.client, database, seqSchema, "sequence")
+
+	d.SetId("")
+
+	return nil
+}
+
+func setSequenceOptions(txn *sql.Tx, d *schema.ResourceData, seqSchema, seqName string) error {
+	var options []string
+
+	if d.HasChange(seqIncrementAttr) {
+		options = append(options, fmt.Sprintf("INCREMENT BY %d", d.Get(seqIncrementAttr).(int)))
+	}
+	if d.HasChange(seqMinValueAttr) {
+		options = append(options, fmt.Sprintf("MINVALUE %d", d.Get(seqMinValueAttr).(int)))
+	}
+	if d.HasChange(seqMaxValueAttr) {
+		options = append(options, fmt.Sprintf("MAXVALUE %d", d.Get(seqMaxValueAttr).(int)))
+	}
+	if d.HasChange(seqStartAttr) {
+		options = append(options, fmt.Sprintf("START WITH %d", d.Get(seqStartAttr).(int)))
+	}
+	if d.HasChange(seqCacheAttr) {
+		options = append(options, fmt.Sprintf("CACHE %d", d.Get(seqCacheAttr).(int)))
+	}
+	if d.HasChange(seqCycleAttr) {
+		if d.Get(seqCycleAttr).(bool) {
+			options = append(options, "CYCLE")
+		} else {
+			options = append(options, "NO CYCLE")
+		}
+	}
+
+	if len(options) == 0 {
+		return nil
+	}
+
+	sql := fmt.Sprintf("ALTER SEQUENCE %s.%s %s",
+		pq.QuoteIdentifier(seqSchema), pq.QuoteIdentifier(seqName), strings.Join(options, " "))
+	if _, err := txn.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating sequence %s: %w", seqName, err)
+	}
+
+	return nil
+}
+
+func setSequenceOwnedBy(txn *sql.Tx, d *schema.ResourceData, seqSchema, seqName string) error {
+	if !d.HasChange(seqOwnedByAttr) {
+		return nil
+	}
+
+	ownedBy := "NONE"
+	if v, ok := d.GetOk(seqOwnedByAttr); ok {
+		var err error
+		if ownedBy, err = sequenceOwnedByClause(seqSchema, v.(string)); err != nil {
+			return err
+		}
+	}
+
+	sql := fmt.Sprintf("ALTER SEQUENCE %s.%s OWNED BY %s",
+		pq.QuoteIdentifier(seqSchema), pq.QuoteIdentifier(seqName), ownedBy)
+	if _, err := txn.Exec(sql); err != nil {
+		return fmt.Errorf("Error updating sequence OWNED BY: %w", err)
+	}
+
+	return nil
+}
+
+// getSequenceOwnedBy returns the column owning the sequence (as table.column)
+// or an empty string if the sequence is not owned by any column.
+// The owning column is the one the sequence has an automatic dependency on,
+// the owning table is always in the same schema as the sequence.
+func getSequenceOwnedBy(txn *sql.Tx, seqSchema, seqName string) (string, error) {
+	var table, column string
+	query := `SELECT t.relname, a.attname ` +
+		`FROM pg_catalog.pg_depend dep ` +
+		`JOIN pg_catalog.pg_class s ON s.oid = dep.objid ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = s.relnamespace ` +
+		`JOIN pg_catalog.pg_class t ON t.oid = dep.refobjid ` +
+		`JOIN pg_catalog.pg_attribute a ON a.attrelid = dep.refobjid AND a.attnum = dep.refobjsubid ` +
+		`WHERE dep.classid = 'pg_catalog.pg_class'::regclass ` +
+		`AND dep.refclassid = 'pg_catalog.pg_class'::regclass ` +
+		`AND dep.deptype = 'a' ` +
+		`AND s.relkind = 'S' AND n.nspname = $1 AND s.relname = $2`
+	err := txn.QueryRow(query, seqSchema, seqName).Scan(&table, &column)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("could not read the column owning sequence %s: %w", seqName, err)
+	}
+
+	return table + "." + column, nil
+}
+
+// sequenceOwnedByClause returns the quoted schema.table.column to use in the OWNED BY clause.
+// The table is qualified with the schema of the sequence, as it must be in the same schema
+// and would otherwise be looked up through the search_path.
+func sequenceOwnedByClause(seqSchema, ownedBy string) (string, error) {
+	parts := strings.Split(ownedBy, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("owned_by %q has not the expected format 'table.column'", ownedBy)
+	}
+	return pq.QuoteIdentifier(seqSchema) + "." + pq.QuoteIdentifier(parts[0]) + "." + pq.QuoteIdentifier(parts[1]), nil
+}
+
+func generateSequenceID(database, seqSchema, seqName string) string {
+	return strings.Join([]string{database, seqSchema, seqName}, ".")
+}
+
+// getDBSequenceName returns the database, schema and name of the sequence.
+// When importing, they are parsed from the resource ID.
+func getDBSequenceName(d *schema.ResourceData, databaseName string) (string, string, string, error) {
+	database := getDatabase(d, databaseName)
+	seqSchema := d.Get(seqSchemaAttr).(string)
+	seqName := d.Get(seqNameAttr).(string)
+
+	if seqName == "" {
+		parsed := strings.Split(d.Id(), ".")
+		if len(parsed) != 3 {
+			return "", "", "", fmt.Errorf("sequence ID %s has not the expected format 'database.schema.sequence': %v", d.Id(), parsed)
+		}
+		database = parsed[0]
+		seqSchema = parsed[1]
+		seqName = parsed[2]
+	}
+	return database, seqSchema, seqName, nil
+}
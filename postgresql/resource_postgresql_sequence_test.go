@@ -0,0 +1,296 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccPostgresqlSequence_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureSequence)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlSequenceDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				resource "postgresql_sequence" "myseq" {
+					name = "myseq"
+				}`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSequenceExists(t, "postgresql_sequence.myseq"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "name", "myseq"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "schema", "public"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "increment", "1"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "start", "1"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "cycle", "false"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "owned_by", ""),
+				),
+			},
+			{
+				Config: `
+				resource "postgresql_sequence" "myseq" {
+					name      = "myseq"
+					increment = 10
+					max_value = 1000
+					cycle     = true
+				}`,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSequenceExists(t, "postgresql_sequence.myseq"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "increment", "10"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "max_value", "1000"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "cycle", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSequence_OwnedBy(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	tables := []string{"test_schema.test_table", "test_schema.test_table2"}
+	dropFunc := createTestTables(t, dbSuffix, tables, "")
+	defer dropFunc()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	config := `
+	resource "postgresql_sequence" "myseq" {
+		database = "%s"
+		schema   = "test_schema"
+		name     = "myseq"
+		owned_by = "%s"
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureSequence)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlSequenceDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, dbName, "test_table.val"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSequenceExists(t, "postgresql_sequence.myseq"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "owned_by", "test_table.val"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, dbName, "test_table2.val"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSequenceExists(t, "postgresql_sequence.myseq"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "owned_by", "test_table2.val"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, dbName, ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSequenceExists(t, "postgresql_sequence.myseq"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "owned_by", ""),
+				),
+			},
+			{
+				// Ownership added outside of Terraform must be detected.
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dsn, _ := testConfig.connStr(dbName)
+					dbExecute(t, dsn, "ALTER SEQUENCE test_schema.myseq OWNED BY test_schema.test_table.val")
+				},
+				Config:             fmt.Sprintf(config, dbName, ""),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSequence_LockTimeout(t *testing.T) {
+	skipIfNotAcc(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	config := `
+	resource "postgresql_sequence" "myseq" {
+		database     = "%s"
+		name         = "myseq"
+		increment    = %d
+		lock_timeout = 100
+	}
+	`
+
+	// Transaction holding a lock on the sequence during the update
+	var lockTxn *sql.Tx
+	defer func() {
+		if lockTxn != nil {
+			_ = lockTxn.Rollback()
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testCheckCompatibleVersion(t, featureSequence)
+		},
+		Providers:    getTestProvidersForTest(t),
+		CheckDestroy: testAccCheckPostgresqlSequenceDestroy(t),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(config, dbName, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSequenceExists(t, "postgresql_sequence.myseq"),
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "lock_timeout", "100"),
+				),
+			},
+			{
+				PreConfig: func() {
+					testConfig := getTestConfig(t)
+					dsn, _ := testConfig.connStr(dbName)
+					conn, err := sql.Open("postgres", dsn)
+					if err != nil {
+						t.Fatalf("could not open connection: %v", err)
+					}
+					if lockTxn, err = conn.Begin(); err != nil {
+						t.Fatalf("could not start transaction: %v", err)
+					}
+					if _, err = lockTxn.Exec("LOCK TABLE public.myseq IN ACCESS EXCLUSIVE MODE"); err != nil {
+						t.Fatalf("could not lock sequence: %v", err)
+					}
+				},
+				Config:      fmt.Sprintf(config, dbName, 10),
+				ExpectError: regexp.MustCompile("lock timeout"),
+			},
+			{
+				PreConfig: func() {
+					_ = lockTxn.Rollback()
+					lockTxn = nil
+				},
+				Config: fmt.Sprintf(config, dbName, 10),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_sequence.myseq", "increment", "10"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlSequenceDestroy(t *testing.T) func(s *terraform.State) error {
+	return func(s *terraform.State) error {
+		client := getTestProvider(t).Meta().(*Client)
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "postgresql_sequence" {
+				continue
+			}
+
+			txn, err := startTransaction(client, rs.Primary.Attributes[seqDatabaseAttr])
+			if err != nil {
+				return err
+			}
+			defer deferredRollback(txn)
+
+			exists, err := checkSequenceExists(
+				txn, rs.Primary.Attributes[seqSchemaAttr], rs.Primary.Attributes[seqNameAttr],
+			)
+			if err != nil {
+				return fmt.Errorf("Error checking sequence %s", err)
+			}
+
+			if exists {
+				return fmt.Errorf("Sequence still exists after destroy")
+			}
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckPostgresqlSequenceExists(t *testing.T, n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No ID is set")
+		}
+
+		client := getTestProvider(t).Meta().(*Client)
+		txn, err := startTransaction(client, rs.Primary.Attributes[seqDatabaseAttr])
+		if err != nil {
+			return err
+		}
+		defer deferredRollback(txn)
+
+		exists, err := checkSequenceExists(
+			txn, rs.Primary.Attributes[seqSchemaAttr], rs.Primary.Attributes[seqNameAttr],
+		)
+		if err != nil {
+			return fmt.Errorf("Error checking sequence %s", err)
+		}
+
+		if !exists {
+			return fmt.Errorf("Sequence not found")
+		}
+
+		return nil
+	}
+}
+
+func checkSequenceExists(txn *sql.Tx, seqSchema, seqName string) (bool, error) {
+	var _rez bool
+	err := txn.QueryRow(
+		"SELECT TRUE FROM pg_catalog.pg_sequences WHERE schemaname = $1 AND sequencename = $2",
+		seqSchema, seqName,
+	).Scan(&_rez)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("Error reading info about sequence: %s", err)
+	}
+
+	return true, nil
+}
+
+func TestSequenceOwnedByClause(t *testing.T) {
+	var tests = []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"my_table.my_column", `"test_schema"."my_table"."my_column"`, false},
+		{"MyTable.id", `"test_schema"."MyTable"."id"`, false},
+		{"my_table", "", true},
+		{"my_schema.my_table.my_column", "", true},
+		{".my_column", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := sequenceOwnedByClause("test_schema", test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("sequenceOwnedByClause(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("sequenceOwnedByClause(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
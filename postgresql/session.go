@@ -0,0 +1,182 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// setRoleConnector wraps a connector to assume the roles of the chain
+// (cf. the set_role_chain provider option) on each new connection.
+type setRoleConnector struct {
+	connector driver.Connector
+	roles     []string
+}
+
+func (c *setRoleConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each role must be assumable by the previous one of the chain,
+	// which is checked before switching to it to report which hop is broken.
+	previous := "the connected user"
+	for _, role := range c.roles {
+		member, err := querySessionBool(ctx, conn, setRoleMemberQuery, role)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not check if %s can assume role %s: %w", previous, role, err)
+		}
+		if !member {
+			conn.Close()
+			return nil, fmt.Errorf("could not set role %s: %s is not a member of it", role, previous)
+		}
+		if err := execSessionStatement(ctx, conn, fmt.Sprintf("SET ROLE %s", pq.QuoteIdentifier(role))); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not set role %s: %w", role, err)
+		}
+		previous = fmt.Sprintf("role %s", role)
+	}
+
+	// Nothing has to be reset as the settings of the session are lost when the connection is closed
+	return conn, nil
+}
+
+// setRoleMemberQuery checks if the current role (i.e.: the previous one of the chain) is a member of the role.
+const setRoleMemberQuery = "SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1 AND pg_catalog.pg_has_role(current_user, oid, 'MEMBER'))"
+
+func (c *setRoleConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// execSessionStatement executes a statement without result directly on the driver connection.
+func execSessionStatement(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		return err
+	}
+
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(nil)
+	return err
+}
+
+// querySessionBool returns the boolean returned by a query directly on the driver connection.
+func querySessionBool(ctx context.Context, conn driver.Conn, query string, args ...interface{}) (bool, error) {
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return false, fmt.Errorf("the driver connection cannot execute queries")
+	}
+
+	namedArgs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		namedArgs[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	rows, err := queryer.QueryContext(ctx, query, namedArgs)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	if err := rows.Next(dest); err != nil {
+		return false, err
+	}
+	if len(dest) != 1 {
+		return false, fmt.Errorf("expected a single column, got %d", len(dest))
+	}
+	value, ok := dest[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean, got %T", dest[0])
+	}
+	return value, nil
+}
+
+// resetSession, isValidConn and checkNamedValue forward the optional interfaces of the driver connection
+// through the connections wrapping it, so database/sql behaves as with the driver connection itself.
+func resetSession(ctx context.Context, conn driver.Conn) error {
+	if resetter, ok := conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func isValidConn(conn driver.Conn) bool {
+	if validator, ok := conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func checkNamedValue(conn driver.Conn, value *driver.NamedValue) error {
+	if checker, ok := conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	// database/sql will convert the value with its default converter instead
+	return driver.ErrSkip
+}
+
+// sessionVariablesConnector wraps a connector to set the session variables
+// (cf. the session_variables provider option) on each new connection.
+type sessionVariablesConnector struct {
+	connector driver.Connector
+	variables map[string]string
+}
+
+func (c *sessionVariablesConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(c.variables))
+	for name := range c.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		query := fmt.Sprintf(
+			"SELECT pg_catalog.set_config('%s', '%s', false)", pqQuoteLiteral(name), pqQuoteLiteral(c.variables[name]),
+		)
+		if err := execSessionStatement(ctx, conn, query); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not set session variable %s: %w", name, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (c *sessionVariablesConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// sessionNameRegexp matches the names of the settings and of the custom variables (prefix.name).
+var sessionNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)*$`)
+
+// sessionReservedVariables cannot be set with session_variables as the provider manages them.
+var sessionReservedVariables = []string{"role", "session_authorization", "transaction_isolation", "transaction_read_only"}
+
+// validateSessionVariableName returns an error if the name cannot be set with session_variables.
+// Whether the setting can be changed in a session is checked by the server when connecting.
+func validateSessionVariableName(name string) error {
+	if !sessionNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid session variable name %q, expected a setting name or a custom variable (prefix.name)", name)
+	}
+	if sliceContainsStr(sessionReservedVariables, strings.ToLower(name)) {
+		return fmt.Errorf("session variable %s cannot be set with session_variables (use set_role_chain to assume roles)", name)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordingConn records the statements executed on the connection.
+// The current role is a member of every role except the ones in notMemberOf.
+type recordingConn struct {
+	fakeConn
+	statements  *[]string
+	notMemberOf []string
+}
+
+func (c recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	*c.statements = append(*c.statements, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recordingConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	return &boolRows{value: !sliceContainsStr(c.notMemberOf, args[0].Value.(string))}, nil
+}
+
+// boolRows returns a single row with a boolean.
+type boolRows struct {
+	value bool
+	done  bool
+}
+
+func (r *boolRows) Columns() []string { return []string{"result"} }
+func (r *boolRows) Close() error      { return nil }
+
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.value
+	r.done = true
+	return nil
+}
+
+type recordingConnector struct {
+	statements  []string
+	notMemberOf []string
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return recordingConn{statements: &c.statements, notMemberOf: c.notMemberOf}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestSetRoleConnector(t *testing.T) {
+	recorder := &recordingConnector{}
+	connector := &setRoleConnector{connector: recorder, roles: []string{"bootstrap", "tenant_admin"}}
+
+	conn, err := connector.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("could not close connection: %v", err)
+	}
+
+	expected := []string{`SET ROLE "bootstrap"`, `SET ROLE "tenant_admin"`}
+	if !reflect.DeepEqual(recorder.statements, expected) {
+		t.Errorf("executed statements %v, want %v", recorder.statements, expected)
+	}
+}
+
+func TestSetRoleConnectorNotMember(t *testing.T) {
+	recorder := &recordingConnector{notMemberOf: []string{"tenant_admin"}}
+	connector := &setRoleConnector{connector: recorder, roles: []string{"bootstrap", "tenant_admin"}}
+
+	_, err := connector.Connect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "role bootstrap is not a member of it") {
+		t.Fatalf("expected the broken hop to be reported, got: %v", err)
+	}
+
+	expected := []string{`SET ROLE "bootstrap"`}
+	if !reflect.DeepEqual(recorder.statements, expected) {
+		t.Errorf("executed statements %v, want %v", recorder.statements, expected)
+	}
+}
+
+func TestSessionVariablesConnector(t *testing.T) {
+	recorder := &recordingConnector{}
+	connector := &sessionVariablesConnector{connector: recorder, variables: map[string]string{
+		"myapp.run":         "terraform:prod:42",
+		"statement_timeout": "5min",
+	}}
+
+	conn, err := connector.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("could not close connection: %v", err)
+	}
+
+	expected := []string{
+		"SELECT pg_catalog.set_config('myapp.run', 'terraform:prod:42', false)",
+		"SELECT pg_catalog.set_config('statement_timeout', '5min', false)",
+	}
+	if !reflect.DeepEqual(recorder.statements, expected) {
+		t.Errorf("executed statements %v, want %v", recorder.statements, expected)
+	}
+}
+
+func TestValidateSessionVariableName(t *testing.T) {
+	var tests = []struct {
+		name    string
+		wantErr bool
+	}{
+		{"statement_timeout", false},
+		{"myapp.run", false},
+		{"myapp.run'; DROP TABLE foo", true},
+		{"", true},
+		{"ROLE", true},
+		{"session_authorization", true},
+	}
+
+	for _, test := range tests {
+		if err := validateSessionVariableName(test.name); (err != nil) != test.wantErr {
+			t.Errorf("validateSessionVariableName(%q) error = %v, want error %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestGetDatabaseUsername(t *testing.T) {
+	var tests = []struct {
+		config   Config
+		expected string
+	}{
+		{Config{Username: "admin"}, "admin"},
+		{Config{Username: "admin@server", DatabaseUsername: "admin"}, "admin"},
+		{Config{Username: "admin", SetRoleChain: []string{"bootstrap", "tenant_admin"}}, "tenant_admin"},
+	}
+
+	for _, test := range tests {
+		if got := test.config.getDatabaseUsername(); got != test.expected {
+			t.Errorf("getDatabaseUsername() = %q, want %q", got, test.expected)
+		}
+	}
+}
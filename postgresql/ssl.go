@@ -0,0 +1,532 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql/driver"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/crypto/pkcs12"
+)
+
+// sslHostnameConnector opens connections to the PostgreSQL server address
+// while verifying the server certificate against another hostname
+// (e.g.: when connecting through a load balancer using a shared certificate).
+//
+// lib/pq verifies the certificate against the host of the DSN (with sslmode=verify-full),
+// so the DSN host is replaced by the expected hostname and the dialer connects
+// to the real address instead.
+type sslHostnameConnector struct {
+	dsn    string
+	dialer pq.DialerContext
+}
+
+func (c *sslHostnameConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return dialOpen(ctx, c.dialer, c.dsn)
+}
+
+func (c *sslHostnameConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// addressDialer dials a fixed address whatever the requested one.
+type addressDialer struct {
+	address string
+	dialer  net.Dialer
+}
+
+func (d addressDialer) Dial(network, _ string) (net.Conn, error) {
+	return d.dialer.Dial(network, d.address)
+}
+
+func (d addressDialer) DialTimeout(network, _ string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.dialer.DialContext(ctx, network, d.address)
+}
+
+func (d addressDialer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	return d.dialer.DialContext(ctx, network, d.address)
+}
+
+// dialOpen opens a lib/pq connection with the dialer within the context of the connection:
+// pq.DialOpen dials without context, so the dialer is bound to it.
+func dialOpen(ctx context.Context, dialer pq.DialerContext, dsn string) (driver.Conn, error) {
+	return pq.DialOpen(contextDialer{ctx: ctx, dialer: dialer}, dsn)
+}
+
+// contextDialer dials with a context dialer within a given context.
+type contextDialer struct {
+	ctx    context.Context
+	dialer pq.DialerContext
+}
+
+func (d contextDialer) Dial(network, address string) (net.Conn, error) {
+	return d.dialer.DialContext(d.ctx, network, address)
+}
+
+func (d contextDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, timeout)
+	defer cancel()
+	return d.dialer.DialContext(ctx, network, address)
+}
+
+// newSSLHostnameConnector returns the connector to use for the DSN
+// in order to verify the server certificate against sslHostname.
+func newSSLHostnameConnector(dsn, sslHostname string) (*sslHostnameConnector, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse connection string: %w", err)
+	}
+
+	address := u.Host
+	u.Host = net.JoinHostPort(sslHostname, u.Port())
+
+	return &sslHostnameConnector{
+		dsn:    u.String(),
+		dialer: addressDialer{address: address},
+	}, nil
+}
+
+// directSSLConnector opens connections with a direct SSL negotiation (sslnegotiation=direct, PostgreSQL 17+):
+// the TLS handshake starts right after the TCP connection instead of after an SSLRequest message,
+// saving a round trip per connection.
+//
+// lib/pq does not implement it, so the dialer establishes the TLS connection
+// and lib/pq uses it as a plain connection (sslmode=disable).
+//
+// It is also used with the standard negotiation when the client certificate is loaded by the provider
+// (lib/pq can only load unencrypted PEM files).
+type directSSLConnector struct {
+	dsn    string
+	dialer pq.DialerContext
+}
+
+func (c *directSSLConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return dialOpen(ctx, c.dialer, c.dsn)
+}
+
+func (c *directSSLConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// directSSLDialer dials the address and performs the TLS handshake on the connection.
+type directSSLDialer struct {
+	address   string
+	tlsConfig *tls.Config
+	dialer    net.Dialer
+	// Standard negotiation: an SSLRequest message is sent before the TLS handshake
+	sslRequest bool
+}
+
+// sslRequestMessage is the SSLRequest message of the standard SSL negotiation
+// (length 8 and request code 80877103).
+var sslRequestMessage = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+func (d directSSLDialer) Dial(network, _ string) (net.Conn, error) {
+	return d.dial(context.Background(), network)
+}
+
+func (d directSSLDialer) DialTimeout(network, _ string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.dial(ctx, network)
+}
+
+func (d directSSLDialer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	return d.dial(ctx, network)
+}
+
+func (d directSSLDialer) dial(ctx context.Context, network string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, d.address)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	negotiation := "direct SSL negotiation"
+	if d.sslRequest {
+		negotiation = "SSL negotiation"
+		if err := requestSSL(conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s with %s failed: %w", negotiation, d.address, err)
+		}
+	}
+	tlsConn := tls.Client(conn, d.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s with %s failed: %w", negotiation, d.address, err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return tlsConn, nil
+}
+
+// requestSSL sends the SSLRequest message on the connection and checks the server accepts it.
+func requestSSL(conn net.Conn) error {
+	if _, err := conn.Write(sslRequestMessage); err != nil {
+		return err
+	}
+	response := make([]byte, 1)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return err
+	}
+	if response[0] != 'S' {
+		return errors.New("SSL is not enabled on the server")
+	}
+	return nil
+}
+
+// newDirectSSLConnector returns the connector to use for the DSN with a direct SSL negotiation
+// (or with the standard one if sslnegotiation is not direct).
+// The server certificate is verified according to the sslmode of the configuration,
+// against sslhostname if set.
+func newDirectSSLConnector(dsn string, config *Config) (*directSSLConnector, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse connection string: %w", err)
+	}
+
+	serverName := u.Hostname()
+	if config.SSLHostname != "" {
+		serverName = config.SSLHostname
+	}
+	tlsConfig, err := directSSLTLSConfig(config, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	address := u.Host
+	query := u.Query()
+	query.Set("sslmode", "disable")
+	u.RawQuery = query.Encode()
+
+	return &directSSLConnector{
+		dsn:    u.String(),
+		dialer: directSSLDialer{address: address, tlsConfig: tlsConfig, sslRequest: !config.useDirectSSL()},
+	}, nil
+}
+
+// directSSLTLSConfig builds the TLS configuration of a direct SSL connection
+// like lib/pq does for the standard negotiation, with the ALPN protocol required by the server.
+func directSSLTLSConfig(config *Config, serverName string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		NextProtos: []string{"postgresql"},
+	}
+
+	if config.SSLRootCertPath != "" {
+		pem, err := ioutil.ReadFile(config.SSLRootCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read sslrootcert %s: %w", config.SSLRootCertPath, err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("could not parse sslrootcert %s", config.SSLRootCertPath)
+		}
+	}
+
+	if config.SSLClientCert != nil {
+		cert, err := loadClientCertificate(config.SSLClientCert)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	crls, err := loadCRLs(config)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.SSLMode {
+	case "verify-full":
+		if len(crls) > 0 {
+			tlsConfig.VerifyPeerCertificate = func(_ [][]byte, chains [][]*x509.Certificate) error {
+				return checkRevocation(chains, crls)
+			}
+		}
+	case "verify-ca":
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyCertificateChain(tlsConfig.RootCAs, crls)
+	default:
+		// As with lib/pq, require verifies the certificate chain only if a root certificate is set
+		tlsConfig.InsecureSkipVerify = true
+		if config.SSLRootCertPath != "" {
+			tlsConfig.VerifyPeerCertificate = verifyCertificateChain(tlsConfig.RootCAs, crls)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyCertificateChain returns the function verifying the server certificate chain
+// without verifying its hostname (for sslmode verify-ca), nor its revocation if there is no CRL.
+func verifyCertificateChain(roots *x509.CertPool, crls []*pkix.CertificateList) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("the server did not present any certificate")
+		}
+
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		var leaf *x509.Certificate
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("could not parse the server certificate: %w", err)
+			}
+			if i == 0 {
+				leaf = cert
+			} else {
+				opts.Intermediates.AddCert(cert)
+			}
+		}
+
+		chains, err := leaf.Verify(opts)
+		if err != nil {
+			return err
+		}
+		if len(crls) > 0 {
+			return checkRevocation(chains, crls)
+		}
+		return nil
+	}
+}
+
+// loadCRLs loads the certificate revocation lists of the sslcrl file and of the files of the sslcrldir directory,
+// PEM (possibly several in a file) or DER encoded.
+func loadCRLs(config *Config) ([]*pkix.CertificateList, error) {
+	var paths []string
+	if config.SSLCRLPath != "" {
+		paths = append(paths, config.SSLCRLPath)
+	}
+	if config.SSLCRLDir != "" {
+		files, err := ioutil.ReadDir(config.SSLCRLDir)
+		if err != nil {
+			return nil, fmt.Errorf("could not read sslcrldir %s: %w", config.SSLCRLDir, err)
+		}
+		for _, file := range files {
+			if !file.IsDir() {
+				paths = append(paths, filepath.Join(config.SSLCRLDir, file.Name()))
+			}
+		}
+	}
+
+	var crls []*pkix.CertificateList
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CRL %s: %w", path, err)
+		}
+		fileCRLs, err := parseCRLs(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse CRL %s: %w", path, err)
+		}
+		crls = append(crls, fileCRLs...)
+	}
+	return crls, nil
+}
+
+func parseCRLs(data []byte) ([]*pkix.CertificateList, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		crl, err := x509.ParseDERCRL(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*pkix.CertificateList{crl}, nil
+	}
+
+	var crls []*pkix.CertificateList
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		crl, err := x509.ParseDERCRL(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		crls = append(crls, crl)
+	}
+	if len(crls) == 0 {
+		return nil, errors.New("no X509 CRL PEM block found")
+	}
+	return crls, nil
+}
+
+// checkRevocation checks that none of the certificates of a verified chain (except its root) is revoked,
+// which is enough to accept the server certificate. As with libpq, the CRL of each issuer of the chain
+// must be provided and not expired, so a missing CRL cannot be mistaken for a certificate not revoked.
+func checkRevocation(chains [][]*x509.Certificate, crls []*pkix.CertificateList) error {
+	err := errors.New("the server certificate chain could not be verified")
+	for _, chain := range chains {
+		if err = checkChainRevocation(chain, crls); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func checkChainRevocation(chain []*x509.Certificate, crls []*pkix.CertificateList) error {
+	now := time.Now()
+	for i := 0; i < len(chain)-1; i++ {
+		cert, issuer := chain[i], chain[i+1]
+
+		found := false
+		for _, crl := range crls {
+			if issuer.CheckCRLSignature(crl) != nil {
+				// Issued by another certificate authority
+				continue
+			}
+			if crl.HasExpired(now) {
+				return fmt.Errorf("the CRL of %s has expired", issuer.Subject)
+			}
+			found = true
+			for _, revoked := range crl.TBSCertList.RevokedCertificates {
+				if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+					return fmt.Errorf("the certificate %s has been revoked", cert.Subject)
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("no CRL of %s found in sslcrl or sslcrldir", issuer.Subject)
+		}
+	}
+	return nil
+}
+
+// loadClientCertificate loads the SSL client certificate from the PKCS#12 bundle
+// or from the PEM files, decrypting the private key with the passphrase if needed.
+func loadClientCertificate(config *ClientCertificateConfig) (tls.Certificate, error) {
+	if config.PKCS12Path != "" {
+		return loadPKCS12Certificate(config.PKCS12Path, config.KeyPassphrase)
+	}
+
+	certPEM, err := ioutil.ReadFile(config.CertificatePath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not read the SSL client certificate %s: %w", config.CertificatePath, err)
+	}
+	keyPEM, err := ioutil.ReadFile(config.KeyPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not read the SSL client certificate key %s: %w", config.KeyPath, err)
+	}
+	if keyPEM, err = decryptPEMKey(keyPEM, config.KeyPassphrase); err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not decrypt the SSL client certificate key %s: %w", config.KeyPath, err)
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not load the SSL client certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// decryptPEMKey returns the PEM private key decrypted with the passphrase
+// (as is if it is not encrypted).
+// Only the legacy PEM encryption (Proc-Type: 4,ENCRYPTED header) is supported.
+func decryptPEMKey(keyPEM []byte, passphrase string) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		return nil, errors.New(
+			"encrypted PKCS#8 keys are not supported, convert the key to the legacy PEM encryption " +
+				"(e.g.: openssl rsa -aes256 -traditional) or use a PKCS#12 bundle",
+		)
+	}
+	if !x509.IsEncryptedPEMBlock(block) {
+		return keyPEM, nil
+	}
+	if passphrase == "" {
+		return nil, errors.New("the key is encrypted, key_passphrase is required")
+	}
+
+	der, err := x509.DecryptPEMBlock(block, []byte(passphrase))
+	if err != nil {
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, errors.New("wrong key_passphrase")
+		}
+		return nil, err
+	}
+	// The padding check of DecryptPEMBlock does not always detect a wrong passphrase,
+	// the decrypted key is garbage then
+	if !isPrivateKey(der) {
+		return nil, errors.New("wrong key_passphrase")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+}
+
+// isPrivateKey returns true if der is a PKCS#1, PKCS#8 or SEC 1 (EC) private key.
+func isPrivateKey(der []byte) bool {
+	if _, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return true
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return true
+	}
+	_, err := x509.ParseECPrivateKey(der)
+	return err == nil
+}
+
+// loadPKCS12Certificate loads the client certificate and its private key from the PKCS#12 bundle.
+// The other certificates of the bundle (e.g.: intermediate CAs) are sent with it.
+func loadPKCS12Certificate(path, passphrase string) (tls.Certificate, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not read the SSL client certificate bundle %s: %w", path, err)
+	}
+
+	blocks, err := pkcs12.ToPEM(data, passphrase)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return tls.Certificate{}, fmt.Errorf("could not decrypt the SSL client certificate bundle %s: wrong key_passphrase", path)
+		}
+		return tls.Certificate{}, fmt.Errorf(
+			"could not decode the SSL client certificate bundle %s (only the legacy 3DES and RC2 encryptions are supported, "+
+				"e.g.: openssl pkcs12 -export -legacy): %w", path, err,
+		)
+	}
+
+	var keyPEM []byte
+	var certsPEM [][]byte
+	for _, block := range blocks {
+		switch block.Type {
+		case "PRIVATE KEY":
+			keyPEM = pem.EncodeToMemory(block)
+		case "CERTIFICATE":
+			certsPEM = append(certsPEM, pem.EncodeToMemory(block))
+		}
+	}
+	if keyPEM == nil || len(certsPEM) == 0 {
+		return tls.Certificate{}, fmt.Errorf("the SSL client certificate bundle %s must contain a certificate and its private key", path)
+	}
+
+	// The certificate of the key has to come first, whatever its position in the bundle
+	for i := range certsPEM {
+		chain := append([][]byte{certsPEM[i]}, certsPEM[:i]...)
+		chain = append(chain, certsPEM[i+1:]...)
+		if cert, err := tls.X509KeyPair(bytes.Join(chain, nil), keyPEM); err == nil {
+			return cert, nil
+		}
+	}
+	return tls.Certificate{}, fmt.Errorf("no certificate matches the private key in the SSL client certificate bundle %s", path)
+}
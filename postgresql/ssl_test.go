@@ -0,0 +1,490 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSSLHostnameConnector(t *testing.T) {
+	config := &Config{
+		Scheme:   "postgres",
+		Host:     "10.0.0.1",
+		Port:     5433,
+		Username: "user",
+		Password: "pass",
+		SSLMode:  "verify-full",
+	}
+	dsn, err := config.connStr("mydb")
+	if err != nil {
+		t.Fatalf("could not build connection string: %v", err)
+	}
+
+	connector, err := newSSLHostnameConnector(dsn, "db.example.com")
+	if err != nil {
+		t.Fatalf("could not create connector: %v", err)
+	}
+
+	// The certificate is verified against the DSN host
+	u, err := url.Parse(connector.dsn)
+	if err != nil {
+		t.Fatalf("could not parse connector DSN: %v", err)
+	}
+	if u.Host != "db.example.com:5433" {
+		t.Errorf("connector DSN host = %q, want %q", u.Host, "db.example.com:5433")
+	}
+	if u.Query().Get("sslmode") != "verify-full" {
+		t.Errorf("connector DSN sslmode = %q, want %q", u.Query().Get("sslmode"), "verify-full")
+	}
+
+	dialer, ok := connector.dialer.(addressDialer)
+	if !ok {
+		t.Fatalf("connector dialer is a %T, want addressDialer", connector.dialer)
+	}
+	if dialer.address != "10.0.0.1:5433" {
+		t.Errorf("dialer address = %q, want %q", dialer.address, "10.0.0.1:5433")
+	}
+}
+
+func TestSSLHostnameConnectorContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	defer listener.Close()
+
+	connector, err := newSSLHostnameConnector(
+		fmt.Sprintf("postgres://user@%s/postgres?sslmode=verify-full", listener.Addr()), "db.example.com",
+	)
+	if err != nil {
+		t.Fatalf("could not create connector: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := connector.Connect(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the connection to be cancelled, got: %v", err)
+	}
+}
+
+func TestNewDirectSSLConnector(t *testing.T) {
+	var tests = []struct {
+		sslMode            string
+		sslHostname        string
+		expectedServerName string
+		expectedSkipVerify bool
+		expectedVerifyFunc bool
+	}{
+		{"require", "", "10.0.0.1", true, false},
+		{"verify-ca", "", "10.0.0.1", true, true},
+		{"verify-full", "", "10.0.0.1", false, false},
+		{"verify-full", "db.example.com", "db.example.com", false, false},
+	}
+
+	for _, test := range tests {
+		config := &Config{
+			Scheme:         "postgres",
+			Host:           "10.0.0.1",
+			Port:           5433,
+			Username:       "user",
+			Password:       "pass",
+			SSLMode:        test.sslMode,
+			SSLHostname:    test.sslHostname,
+			SSLNegotiation: sslNegotiationDirect,
+		}
+		dsn, err := config.connStr("mydb")
+		if err != nil {
+			t.Fatalf("could not build connection string: %v", err)
+		}
+
+		connector, err := newDirectSSLConnector(dsn, config)
+		if err != nil {
+			t.Fatalf("could not create connector: %v", err)
+		}
+
+		// lib/pq must not negotiate SSL on the connection already encrypted by the dialer
+		u, err := url.Parse(connector.dsn)
+		if err != nil {
+			t.Fatalf("could not parse connector DSN: %v", err)
+		}
+		if u.Query().Get("sslmode") != "disable" {
+			t.Errorf("%s: connector DSN sslmode = %q, want %q", test.sslMode, u.Query().Get("sslmode"), "disable")
+		}
+
+		dialer, ok := connector.dialer.(directSSLDialer)
+		if !ok {
+			t.Fatalf("connector dialer is a %T, want directSSLDialer", connector.dialer)
+		}
+		if dialer.address != "10.0.0.1:5433" {
+			t.Errorf("%s: dialer address = %q, want %q", test.sslMode, dialer.address, "10.0.0.1:5433")
+		}
+		tlsConfig := dialer.tlsConfig
+		if tlsConfig.ServerName != test.expectedServerName {
+			t.Errorf("%s: server name = %q, want %q", test.sslMode, tlsConfig.ServerName, test.expectedServerName)
+		}
+		if len(tlsConfig.NextProtos) != 1 || tlsConfig.NextProtos[0] != "postgresql" {
+			t.Errorf("%s: ALPN protocols = %v, want [postgresql]", test.sslMode, tlsConfig.NextProtos)
+		}
+		if tlsConfig.InsecureSkipVerify != test.expectedSkipVerify {
+			t.Errorf("%s: InsecureSkipVerify = %t, want %t", test.sslMode, tlsConfig.InsecureSkipVerify, test.expectedSkipVerify)
+		}
+		if (tlsConfig.VerifyPeerCertificate != nil) != test.expectedVerifyFunc {
+			t.Errorf("%s: chain verification set = %t, want %t", test.sslMode, tlsConfig.VerifyPeerCertificate != nil, test.expectedVerifyFunc)
+		}
+	}
+}
+
+// writeTestClientCertificate writes a self-signed client certificate and its private key,
+// encrypted with the passphrase if not empty, and returns their paths.
+func writeTestClientCertificate(t *testing.T, passphrase string) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test_user"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create certificate: %v", err)
+	}
+
+	keyBlock := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if passphrase != "" {
+		keyBlock, err = x509.EncryptPEMBlock(rand.Reader, keyBlock.Type, keyBlock.Bytes, []byte(passphrase), x509.PEMCipherAES256)
+		if err != nil {
+			t.Fatalf("could not encrypt key: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("could not write certificate: %v", err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(keyBlock), 0600); err != nil {
+		t.Fatalf("could not write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestLoadClientCertificate(t *testing.T) {
+	plainCert, plainKey := writeTestClientCertificate(t, "")
+	encryptedCert, encryptedKey := writeTestClientCertificate(t, "secret")
+
+	var tests = []struct {
+		name          string
+		config        ClientCertificateConfig
+		expectedError string
+	}{
+		{"plain key", ClientCertificateConfig{CertificatePath: plainCert, KeyPath: plainKey}, ""},
+		{"encrypted key", ClientCertificateConfig{CertificatePath: encryptedCert, KeyPath: encryptedKey, KeyPassphrase: "secret"}, ""},
+		{"wrong passphrase", ClientCertificateConfig{CertificatePath: encryptedCert, KeyPath: encryptedKey, KeyPassphrase: "wrong"}, "wrong key_passphrase"},
+		{"missing passphrase", ClientCertificateConfig{CertificatePath: encryptedCert, KeyPath: encryptedKey}, "key_passphrase is required"},
+		{"pkcs12", ClientCertificateConfig{PKCS12Path: "testdata/client.p12", KeyPassphrase: "secret"}, ""},
+		{"pkcs12 wrong passphrase", ClientCertificateConfig{PKCS12Path: "testdata/client.p12", KeyPassphrase: "wrong"}, "wrong key_passphrase"},
+	}
+
+	for _, test := range tests {
+		cert, err := loadClientCertificate(&test.config)
+		if test.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("%s: error = %v, want %q", test.name, err, test.expectedError)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: could not load certificate: %v", test.name, err)
+			continue
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatalf("%s: could not parse certificate: %v", test.name, err)
+		}
+		if leaf.Subject.CommonName != "test_user" {
+			t.Errorf("%s: certificate CN = %q, want %q", test.name, leaf.Subject.CommonName, "test_user")
+		}
+	}
+}
+
+func TestConfigClientCertificateLoadedByProvider(t *testing.T) {
+	config := &Config{
+		Scheme:   "postgres",
+		Host:     "10.0.0.1",
+		Port:     5433,
+		Username: "user",
+		SSLMode:  "verify-full",
+		SSLClientCert: &ClientCertificateConfig{
+			PKCS12Path:    "testdata/client.p12",
+			KeyPassphrase: "secret",
+		},
+	}
+	if !config.loadsClientCert() {
+		t.Fatalf("the PKCS#12 bundle must be loaded by the provider")
+	}
+
+	dsn, err := config.connStr("mydb")
+	if err != nil {
+		t.Fatalf("could not build connection string: %v", err)
+	}
+	if strings.Contains(dsn, "sslcert") || strings.Contains(dsn, "sslkey") {
+		t.Errorf("lib/pq must not load the client certificate: %s", dsn)
+	}
+
+	connector, err := config.newConnector(dsn)
+	if err != nil {
+		t.Fatalf("could not create connector: %v", err)
+	}
+	dialer := connector.(*directSSLConnector).dialer.(directSSLDialer)
+	if !dialer.sslRequest {
+		t.Errorf("the standard SSL negotiation must be used")
+	}
+	if len(dialer.tlsConfig.Certificates) != 1 {
+		t.Errorf("TLS configuration has %d client certificates, want 1", len(dialer.tlsConfig.Certificates))
+	}
+}
+
+func TestDirectSSLDialerSSLRequest(t *testing.T) {
+	certPath, keyPath := writeTestClientCertificate(t, "")
+	serverCert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("could not load server certificate: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		request := make([]byte, len(sslRequestMessage))
+		_, _ = io.ReadFull(conn, request)
+		received <- request
+		_, _ = conn.Write([]byte("S"))
+		_ = tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{serverCert}}).Handshake()
+	}()
+
+	dialer := directSSLDialer{
+		address:    listener.Addr().String(),
+		tlsConfig:  &tls.Config{InsecureSkipVerify: true},
+		sslRequest: true,
+	}
+	conn, err := dialer.DialTimeout("tcp", "", 5*time.Second)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	conn.Close()
+
+	if request := <-received; !bytes.Equal(request, sslRequestMessage) {
+		t.Errorf("server received %v, want the SSLRequest message %v", request, sslRequestMessage)
+	}
+}
+
+func TestDirectSSLDialerHandshakeError(t *testing.T) {
+	var tests = []struct {
+		sslRequest    bool
+		expectedError string
+	}{
+		{false, "direct SSL negotiation with"},
+		{true, "SSL negotiation with"},
+	}
+
+	for _, test := range tests {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("could not listen: %v", err)
+		}
+
+		go func(sslRequest bool) {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			if sslRequest {
+				request := make([]byte, len(sslRequestMessage))
+				_, _ = io.ReadFull(conn, request)
+				_, _ = conn.Write([]byte("S"))
+			}
+			// Closed without TLS handshake
+		}(test.sslRequest)
+
+		dialer := directSSLDialer{
+			address:    listener.Addr().String(),
+			tlsConfig:  &tls.Config{InsecureSkipVerify: true},
+			sslRequest: test.sslRequest,
+		}
+		_, err = dialer.DialTimeout("tcp", "", 5*time.Second)
+		listener.Close()
+		if err == nil || !strings.HasPrefix(err.Error(), test.expectedError) {
+			t.Errorf("sslRequest %t: error = %v, want prefix %q", test.sslRequest, err, test.expectedError)
+		}
+	}
+}
+
+// testCertificateAuthority is a certificate authority issuing the certificates and the CRLs of the tests.
+type testCertificateAuthority struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+func newTestCertificateAuthority(t *testing.T, name string) *testCertificateAuthority {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("could not create CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("could not parse CA certificate: %v", err)
+	}
+	return &testCertificateAuthority{cert: cert, key: key}
+}
+
+// issue returns the DER encoded server certificate with the serial number.
+func (ca *testCertificateAuthority) issue(t *testing.T, serial int64) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "db.example.com"},
+		DNSNames:     []string{"db.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &ca.key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("could not create server certificate: %v", err)
+	}
+	return der
+}
+
+// crl returns the PEM encoded CRL revoking the serial numbers, valid until nextUpdate.
+func (ca *testCertificateAuthority) crl(t *testing.T, nextUpdate time.Time, serials ...int64) []byte {
+	var revoked []pkix.RevokedCertificate
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+	der, err := ca.cert.CreateCRL(rand.Reader, ca.key, revoked, time.Now().Add(-time.Hour), nextUpdate)
+	if err != nil {
+		t.Fatalf("could not create CRL: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func TestVerifyCertificateChainRevocation(t *testing.T) {
+	ca := newTestCertificateAuthority(t, "test_ca")
+	otherCA := newTestCertificateAuthority(t, "other_ca")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	valid := time.Now().Add(time.Hour)
+	var tests = []struct {
+		name          string
+		crls          [][]byte
+		serial        int64
+		expectedError string
+	}{
+		{"not revoked", [][]byte{ca.crl(t, valid, 3)}, 2, ""},
+		{"revoked", [][]byte{ca.crl(t, valid, 2, 3)}, 2, "has been revoked"},
+		{"revoked in another CRL of the issuer", [][]byte{ca.crl(t, valid), ca.crl(t, valid, 2)}, 2, "has been revoked"},
+		{"CRL of another issuer", [][]byte{otherCA.crl(t, valid, 2)}, 2, "no CRL of CN=test_ca"},
+		{"expired CRL", [][]byte{ca.crl(t, time.Now().Add(-time.Minute))}, 2, "has expired"},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		for i, crl := range test.crls {
+			if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.r0", i)), crl, 0600); err != nil {
+				t.Fatalf("could not write CRL: %v", err)
+			}
+		}
+		crls, err := loadCRLs(&Config{SSLCRLDir: dir})
+		if err != nil {
+			t.Fatalf("%s: could not load CRLs: %v", test.name, err)
+		}
+
+		err = verifyCertificateChain(roots, crls)([][]byte{ca.issue(t, test.serial)}, nil)
+		if test.expectedError == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("%s: error = %v, want %q", test.name, err, test.expectedError)
+		}
+	}
+}
+
+func TestLoadCRLs(t *testing.T) {
+	ca := newTestCertificateAuthority(t, "test_ca")
+	dir := t.TempDir()
+
+	// Several PEM CRLs in the file, a DER one in the directory
+	pemPath := filepath.Join(dir, "crls.pem")
+	pemCRLs := append(ca.crl(t, time.Now().Add(time.Hour), 2), ca.crl(t, time.Now().Add(time.Hour), 3)...)
+	if err := ioutil.WriteFile(pemPath, pemCRLs, 0600); err != nil {
+		t.Fatalf("could not write CRL: %v", err)
+	}
+	crlDir := filepath.Join(dir, "crls")
+	if err := os.Mkdir(crlDir, 0700); err != nil {
+		t.Fatalf("could not create CRL directory: %v", err)
+	}
+	block, _ := pem.Decode(ca.crl(t, time.Now().Add(time.Hour), 4))
+	if err := ioutil.WriteFile(filepath.Join(crlDir, "ca.r0"), block.Bytes, 0600); err != nil {
+		t.Fatalf("could not write CRL: %v", err)
+	}
+
+	crls, err := loadCRLs(&Config{SSLCRLPath: pemPath, SSLCRLDir: crlDir})
+	if err != nil {
+		t.Fatalf("could not load CRLs: %v", err)
+	}
+	if len(crls) != 3 {
+		t.Errorf("loaded %d CRLs, want 3", len(crls))
+	}
+
+	invalidPath := filepath.Join(dir, "invalid.crl")
+	if err := ioutil.WriteFile(invalidPath, []byte("-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n"), 0600); err != nil {
+		t.Fatalf("could not write CRL: %v", err)
+	}
+	if _, err := loadCRLs(&Config{SSLCRLPath: invalidPath}); err == nil || !strings.Contains(err.Error(), "no X509 CRL") {
+		t.Errorf("loading a file without CRL: error = %v, want no X509 CRL", err)
+	}
+}
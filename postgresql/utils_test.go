@@ -37,6 +37,14 @@ func testSuperuserPreCheck(t *testing.T) {
 	}
 }
 
+// Some tests check the behavior with a user which is not a superuser (RDS like)
+func testNoSuperuserPreCheck(t *testing.T) {
+	client := getTestProvider(t).Meta().(*Client)
+	if client.config.Superuser {
+		t.Skip("Skip test: This test can be run only with a user which is not a superuser")
+	}
+}
+
 func getTestConfig(t *testing.T) Config {
 	getEnv := func(key, fallback string) string {
 		value := os.Getenv(key)
@@ -251,6 +259,31 @@ func connectAsTestRole(t *testing.T, role, dbName string) *sql.DB {
 	return db
 }
 
+// testCheckSequencesPrivileges checks the role has exactly the expected privileges (among USAGE, SELECT and UPDATE)
+// on the sequences.
+func testCheckSequencesPrivileges(t *testing.T, dbName, roleName string, sequences []string, expectedPrivileges []string) error {
+	config := getTestConfig(t)
+	dsn, _ := config.connStr(dbName)
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("could not open connection pool for db %s: %v", dbName, err)
+	}
+	defer db.Close()
+
+	for _, sequence := range sequences {
+		for _, privilege := range []string{"USAGE", "SELECT", "UPDATE"} {
+			var granted bool
+			if err := db.QueryRow("SELECT has_sequence_privilege($1, $2, $3)", roleName, sequence, privilege).Scan(&granted); err != nil {
+				return fmt.Errorf("could not check privilege %s on sequence %s: %w", privilege, sequence, err)
+			}
+			if expected := sliceContainsStr(expectedPrivileges, privilege); granted != expected {
+				return fmt.Errorf("role %s has privilege %s on sequence %s: %t, want %t", roleName, privilege, sequence, granted, expected)
+			}
+		}
+	}
+	return nil
+}
+
 func testCheckTablesPrivileges(t *testing.T, dbName, roleName string, tables []string, allowedPrivileges []string) error {
 	db := connectAsTestRole(t, roleName, dbName)
 	defer db.Close()